@@ -43,6 +43,11 @@ type NodeFeature struct {
 
 	// Specification of the NodeFeature, containing features discovered for a node.
 	Spec NodeFeatureSpec `json:"spec"`
+
+	// Status of the feature discovery on the node. Updated by nfd-worker
+	// together with the spec.
+	// +optional
+	Status NodeFeatureStatus `json:"status,omitempty"`
 }
 
 // NodeFeatureSpec describes a NodeFeature object.
@@ -55,6 +60,51 @@ type NodeFeatureSpec struct {
 	Labels map[string]string `json:"labels"`
 }
 
+// NodeFeatureStatus describes the status of feature discovery on a node.
+type NodeFeatureStatus struct {
+	// Sources contains the discovery status of each enabled feature source.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Sources []FeatureSourceStatus `json:"sources,omitempty"`
+}
+
+// FeatureSourceStatus describes the status of the discovery of one feature
+// source.
+type FeatureSourceStatus struct {
+	// Name of the feature source.
+	Name string `json:"name"`
+
+	// LastSuccessfulTime is the time when the discovery of the feature
+	// source last completed successfully.
+	// +optional
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+
+	// Duration of the most recent discovery of the feature source.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// Error is the error message of the most recent discovery of the feature
+	// source. Empty if the discovery was successful.
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// Stale is true if the most recent discovery did not complete in time
+	// and the features from the last successful discovery are reported.
+	// +optional
+	Stale bool `json:"stale,omitempty"`
+
+	// Version of nfd-worker that ran the discovery.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Labels contains the names of the labels in the spec that were created
+	// from the features of the source.
+	// +listType=set
+	// +optional
+	Labels []string `json:"labels,omitempty"`
+}
+
 // Features is the collection of all discovered features.
 type Features struct {
 	// Flags contains all the flag-type features of the node.
@@ -111,6 +161,13 @@ type NodeFeatureRuleList struct {
 // customization of node objects, such as node labeling.
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster,shortName=nfr
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Valid",type="string",JSONPath=".status.conditions[?(@.type==\"Valid\")].status"
+// +kubebuilder:printcolumn:name="Applied",type="string",JSONPath=".status.conditions[?(@.type==\"Applied\")].status"
+// +kubebuilder:printcolumn:name="Matched",type="integer",JSONPath=".status.matchedNodes"
+// +kubebuilder:printcolumn:name="Unmatched",type="integer",JSONPath=".status.unmatchedNodes"
+// +kubebuilder:printcolumn:name="Shadow",type="boolean",JSONPath=".spec.shadow",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +genclient
 // +genclient:nonNamespaced
@@ -120,18 +177,161 @@ type NodeFeatureRule struct {
 
 	// Spec defines the rules to be evaluated.
 	Spec NodeFeatureRuleSpec `json:"spec"`
+
+	// Status of the NodeFeatureRule after the most recent evaluation of the
+	// rules against the nodes of the cluster.
+	// +optional
+	Status NodeFeatureRuleStatus `json:"status,omitempty"`
 }
 
 // NodeFeatureRuleSpec describes a NodeFeatureRule.
 type NodeFeatureRuleSpec struct {
 	// Rules is a list of node customization rules.
 	Rules []Rule `json:"rules"`
+
+	// Priority of the rules in this NodeFeatureRule. Used for resolving
+	// conflicts when rules of multiple NodeFeatureRule objects produce the
+	// same label, annotation, extended resource or taint with different
+	// values. Higher value means higher priority. Defaults to zero.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// NodeSelector limits the nodes the rules are evaluated on. The selector
+	// is matched against the labels of the Node object. If not specified, the
+	// rules are evaluated on all nodes.
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// Shadow enables the shadow (dry-run) mode. In shadow mode the rules are
+	// evaluated on all nodes as usual but the nodes are not modified.
+	// Instead, the labels, annotations, extended resources and taints that
+	// the rules would produce are reported in the status of the
+	// NodeFeatureRule.
+	// +optional
+	Shadow bool `json:"shadow,omitempty"`
+}
+
+// NodeFeatureRuleStatus describes the status of a NodeFeatureRule, i.e. the
+// aggregated results of evaluating the rules against all nodes.
+type NodeFeatureRuleStatus struct {
+	// Conditions describe the current state of the NodeFeatureRule. Known
+	// condition types are "Valid" and "Applied".
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// MatchedNodes is the number of nodes where at least one of the rules
+	// matched.
+	// +optional
+	MatchedNodes int32 `json:"matchedNodes"`
+
+	// UnmatchedNodes is the number of nodes where none of the rules matched.
+	// +optional
+	UnmatchedNodes int32 `json:"unmatchedNodes"`
+
+	// Rules contains the evaluation status of each individual rule.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Rules []RuleStatus `json:"rules,omitempty"`
+}
+
+// RuleStatus describes the evaluation status of one rule of a
+// NodeFeatureRule.
+type RuleStatus struct {
+	// Name of the rule.
+	Name string `json:"name"`
+
+	// MatchedNodes is the number of nodes where the rule matched.
+	// +optional
+	MatchedNodes int32 `json:"matchedNodes"`
+
+	// UnmatchedNodes is the number of nodes where the rule did not match.
+	// +optional
+	UnmatchedNodes int32 `json:"unmatchedNodes"`
+
+	// LastError is the last error encountered when evaluating the rule.
+	// +optional
+	LastError *RuleError `json:"lastError,omitempty"`
+
+	// ShadowOutput summarizes the output the rule would produce on the nodes
+	// if the NodeFeatureRule was not in shadow mode. Only populated in
+	// shadow mode.
+	// +optional
+	ShadowOutput *RuleShadowOutput `json:"shadowOutput,omitempty"`
 }
 
+// RuleShadowOutput summarizes the output a rule would produce on the nodes.
+// Each distinct name-value pair is listed with the number of nodes it would
+// be applied to.
+type RuleShadowOutput struct {
+	// Labels the rule would create.
+	// +optional
+	Labels []ShadowOutputItem `json:"labels,omitempty"`
+
+	// Annotations the rule would create.
+	// +optional
+	Annotations []ShadowOutputItem `json:"annotations,omitempty"`
+
+	// ExtendedResources the rule would create.
+	// +optional
+	ExtendedResources []ShadowOutputItem `json:"extendedResources,omitempty"`
+
+	// Taints the rule would create. The name is in the form <key>:<effect>.
+	// +optional
+	Taints []ShadowOutputItem `json:"taints,omitempty"`
+
+	// Truncated is true if some of the items were omitted because of the
+	// size limit of the status.
+	// +optional
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// ShadowOutputItem is one distinct output of a rule in shadow mode.
+type ShadowOutputItem struct {
+	// Name of the label, annotation, extended resource or taint.
+	Name string `json:"name"`
+
+	// Value of the label, annotation, extended resource or taint.
+	// +optional
+	Value string `json:"value"`
+
+	// Nodes is the number of nodes the item would be applied to.
+	Nodes int32 `json:"nodes"`
+}
+
+// RuleError describes an error encountered when evaluating a rule.
+type RuleError struct {
+	// Message is the error message.
+	Message string `json:"message"`
+
+	// NodeName is the name of the node the rule was evaluated against.
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+
+	// Time is the time when the error occurred.
+	Time metav1.Time `json:"time"`
+}
+
+const (
+	// NodeFeatureRuleConditionValid is the condition type indicating
+	// whether the rules of a NodeFeatureRule could be evaluated without
+	// errors.
+	NodeFeatureRuleConditionValid = "Valid"
+	// NodeFeatureRuleConditionApplied is the condition type indicating
+	// whether the NodeFeatureRule matched any nodes in the cluster. Always
+	// false in shadow mode as the nodes are not modified.
+	NodeFeatureRuleConditionApplied = "Applied"
+)
+
 // NodeFeatureGroup resource holds Node pools by featureGroup
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Namespaced,shortName=nfg
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Valid",type="string",JSONPath=".status.conditions[?(@.type==\"Valid\")].status"
+// +kubebuilder:printcolumn:name="Nodes",type="integer",JSONPath=".status.nodeCount"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +genclient
 type NodeFeatureGroup struct {
@@ -150,9 +350,32 @@ type NodeFeatureGroup struct {
 type NodeFeatureGroupSpec struct {
 	// List of rules to evaluate to determine nodes that belong in this group.
 	Rules []GroupRule `json:"featureGroupRules"`
+
+	// Labels to create on the member nodes of the group. The labels are
+	// removed when a node leaves the group. Only effective in the namespace
+	// of nfd-master.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Taints to create on the member nodes of the group. The taints are
+	// removed when a node leaves the group. Only effective in the namespace
+	// of nfd-master.
+	// +optional
+	Taints []corev1.Taint `json:"taints,omitempty"`
 }
 
 type NodeFeatureGroupStatus struct {
+	// Conditions describe the current state of the NodeFeatureGroup. Known
+	// condition types are "Valid", "Populated" and "Truncated".
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// NodeCount is the number of nodes in the group.
+	// +optional
+	NodeCount int32 `json:"nodeCount"`
+
 	// Nodes is a list of FeatureGroupNode in the cluster that match the featureGroupRules
 	// +optional
 	// +patchMergeKey=name
@@ -165,8 +388,37 @@ type NodeFeatureGroupStatus struct {
 type FeatureGroupNode struct {
 	// Name of the node.
 	Name string `json:"name"`
+
+	// MatchedRules is the list of the names of the featureGroupRules that
+	// matched the node. Only reported for the first 100 nodes of the group
+	// in alphabetical order.
+	// +optional
+	MatchedRules []string `json:"matchedRules,omitempty"`
+
+	// Vars contains the vars created by the matched rules. Only reported for
+	// the first 100 nodes of the group in alphabetical order.
+	// +optional
+	Vars map[string]string `json:"vars,omitempty"`
+
+	// JoinTime is the time when the node joined the group.
+	// +optional
+	JoinTime metav1.Time `json:"joinTime,omitempty"`
 }
 
+const (
+	// NodeFeatureGroupConditionValid is the condition type indicating
+	// whether the rules of a NodeFeatureGroup could be evaluated without
+	// errors.
+	NodeFeatureGroupConditionValid = "Valid"
+	// NodeFeatureGroupConditionPopulated is the condition type indicating
+	// whether the NodeFeatureGroup has any member nodes.
+	NodeFeatureGroupConditionPopulated = "Populated"
+	// NodeFeatureGroupConditionTruncated is the condition type indicating
+	// whether the matched rules and vars of some member nodes were left out
+	// of the status of the NodeFeatureGroup.
+	NodeFeatureGroupConditionTruncated = "Truncated"
+)
+
 // NodeFeatureGroupList contains a list of NodeFeatureGroup objects.
 // +kubebuilder:object:root=true
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -201,6 +453,17 @@ type GroupRule struct {
 	// MatchAny specifies a list of matchers one of which must match.
 	// +optional
 	MatchAny []MatchAnyElem `json:"matchAny"`
+
+	// MatchExpression specifies a boolean expression of feature matchers
+	// that must match.
+	// +optional
+	MatchExpression *FeatureMatchExpression `json:"matchExpression,omitempty"`
+
+	// CELExpression specifies a CEL expression that must evaluate to true.
+	// The expression has access to the features as variables flags,
+	// attributes and instances.
+	// +optional
+	CELExpression string `json:"celExpression,omitempty"`
 }
 
 // Rule defines a rule for node customization such as labeling.
@@ -250,6 +513,17 @@ type Rule struct {
 	// MatchAny specifies a list of matchers one of which must match.
 	// +optional
 	MatchAny []MatchAnyElem `json:"matchAny"`
+
+	// MatchExpression specifies a boolean expression of feature matchers
+	// that must match.
+	// +optional
+	MatchExpression *FeatureMatchExpression `json:"matchExpression,omitempty"`
+
+	// CELExpression specifies a CEL expression that must evaluate to true.
+	// The expression has access to the features as variables flags,
+	// attributes and instances.
+	// +optional
+	CELExpression string `json:"celExpression,omitempty"`
 }
 
 // MatchAnyElem specifies one sub-matcher of MatchAny.
@@ -258,6 +532,33 @@ type MatchAnyElem struct {
 	MatchFeatures FeatureMatcher `json:"matchFeatures"`
 }
 
+// FeatureMatchExpression is a node in a boolean expression tree of feature
+// matchers. The node matches if all of the specified fields match, i.e. the
+// fields are combined with a logical AND. An empty node matches always.
+type FeatureMatchExpression struct {
+	// MatchFeatures specifies a set of matcher terms all of which must match.
+	// +optional
+	MatchFeatures FeatureMatcher `json:"matchFeatures,omitempty"`
+
+	// AllOf specifies a list of expressions all of which must match.
+	// +optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	AllOf []FeatureMatchExpression `json:"allOf,omitempty"`
+
+	// AnyOf specifies a list of expressions at least one of which must match.
+	// +optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	AnyOf []FeatureMatchExpression `json:"anyOf,omitempty"`
+
+	// NoneOf specifies a list of expressions none of which may match.
+	// +optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	NoneOf []FeatureMatchExpression `json:"noneOf,omitempty"`
+}
+
 // FeatureMatcher specifies a set of feature matcher terms (i.e. per-feature
 // matchers), all of which must match.
 type FeatureMatcher []FeatureMatcherTerm
@@ -276,8 +577,46 @@ type FeatureMatcherTerm struct {
 	// element in the feature set.
 	// +optional
 	MatchName *MatchExpression `json:"matchName"`
+	// MatchAggregates is a list of aggregate expressions evaluated over the
+	// instances of an instance feature set. If specified, MatchExpressions
+	// only selects the instances to aggregate over and the term matches if
+	// all aggregate expressions match.
+	// +optional
+	MatchAggregates []AggregateExpression `json:"matchAggregates,omitempty"`
 }
 
+// AggregateExpression specifies an aggregate function to compute over a set
+// of feature instances and an expression to evaluate against the result.
+type AggregateExpression struct {
+	// Func is the aggregate function to compute.
+	Func AggregateFunc `json:"func"`
+
+	// Attribute is the name of the instance attribute to aggregate. Required
+	// for Sum, Min and Max, must be empty for Count. Instances that do not
+	// have the attribute are ignored.
+	// +optional
+	Attribute string `json:"attribute,omitempty"`
+
+	// MatchExpression is evaluated against the result of the aggregate
+	// function.
+	MatchExpression `json:",inline"`
+}
+
+// AggregateFunc is an aggregate function computed over feature instances.
+// +kubebuilder:validation:Enum="Count";"Sum";"Min";"Max"
+type AggregateFunc string
+
+const (
+	// AggregateCount is the number of instances.
+	AggregateCount AggregateFunc = "Count"
+	// AggregateSum is the sum of the values of an attribute.
+	AggregateSum AggregateFunc = "Sum"
+	// AggregateMin is the minimum of the values of an attribute.
+	AggregateMin AggregateFunc = "Min"
+	// AggregateMax is the maximum of the values of an attribute.
+	AggregateMax AggregateFunc = "Max"
+)
+
 // MatchExpressionSet contains a set of MatchExpressions, each of which is
 // evaluated against a set of input values.
 type MatchExpressionSet map[string]*MatchExpression
@@ -298,7 +637,9 @@ type MatchExpression struct {
 	Value MatchValue `json:"value,omitempty"`
 
 	// Type defines the value type for specific operators.
-	// The currently supported type is 'version' for Gt,Ge,Lt,Le,GtLt,GeLe operators.
+	// The currently supported types are 'version', 'float', 'quantity' and
+	// 'bytes' for Gt,Ge,Lt,Le,GtLt,GeLe operators. Integers are used if the
+	// type is not specified.
 	// +optional
 	Type ValueType `json:"type,omitempty"`
 }
@@ -322,6 +663,16 @@ const (
 	// %d.%d (e.g., 1.2),
 	// %d (e.g., 1)
 	TypeVersion ValueType = "version"
+	// TypeFloat represents a floating point number (e.g., 2.5).
+	TypeFloat ValueType = "float"
+	// TypeQuantity represents a Kubernetes resource quantity (e.g., 1Gi,
+	// 500m, 2k).
+	TypeQuantity ValueType = "quantity"
+	// TypeBytes represents a size in bytes with an optional case insensitive
+	// unit suffix (e.g., 512, 2048KiB, 1.5GB). Decimal prefixes are powers
+	// of 1000 and binary prefixes powers of 1024, i.e. 1kB is 1000 bytes and
+	// 1KiB is 1024 bytes.
+	TypeBytes ValueType = "bytes"
 )
 
 const (
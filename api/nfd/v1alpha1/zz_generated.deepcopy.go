@@ -23,9 +23,27 @@ package v1alpha1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AggregateExpression) DeepCopyInto(out *AggregateExpression) {
+	*out = *in
+	in.MatchExpression.DeepCopyInto(&out.MatchExpression)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AggregateExpression.
+func (in *AggregateExpression) DeepCopy() *AggregateExpression {
+	if in == nil {
+		return nil
+	}
+	out := new(AggregateExpression)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AttributeFeatureSet) DeepCopyInto(out *AttributeFeatureSet) {
 	*out = *in
@@ -52,6 +70,19 @@ func (in *AttributeFeatureSet) DeepCopy() *AttributeFeatureSet {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FeatureGroupNode) DeepCopyInto(out *FeatureGroupNode) {
 	*out = *in
+	if in.MatchedRules != nil {
+		in, out := &in.MatchedRules, &out.MatchedRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Vars != nil {
+		in, out := &in.Vars, &out.Vars
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.JoinTime.DeepCopyInto(&out.JoinTime)
 	return
 }
 
@@ -65,6 +96,50 @@ func (in *FeatureGroupNode) DeepCopy() *FeatureGroupNode {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeatureMatchExpression) DeepCopyInto(out *FeatureMatchExpression) {
+	*out = *in
+	if in.MatchFeatures != nil {
+		in, out := &in.MatchFeatures, &out.MatchFeatures
+		*out = make(FeatureMatcher, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AllOf != nil {
+		in, out := &in.AllOf, &out.AllOf
+		*out = make([]FeatureMatchExpression, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AnyOf != nil {
+		in, out := &in.AnyOf, &out.AnyOf
+		*out = make([]FeatureMatchExpression, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NoneOf != nil {
+		in, out := &in.NoneOf, &out.NoneOf
+		*out = make([]FeatureMatchExpression, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeatureMatchExpression.
+func (in *FeatureMatchExpression) DeepCopy() *FeatureMatchExpression {
+	if in == nil {
+		return nil
+	}
+	out := new(FeatureMatchExpression)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in FeatureMatcher) DeepCopyInto(out *FeatureMatcher) {
 	{
@@ -114,6 +189,13 @@ func (in *FeatureMatcherTerm) DeepCopyInto(out *FeatureMatcherTerm) {
 		*out = new(MatchExpression)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MatchAggregates != nil {
+		in, out := &in.MatchAggregates, &out.MatchAggregates
+		*out = make([]AggregateExpression, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -127,6 +209,36 @@ func (in *FeatureMatcherTerm) DeepCopy() *FeatureMatcherTerm {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeatureSourceStatus) DeepCopyInto(out *FeatureSourceStatus) {
+	*out = *in
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeatureSourceStatus.
+func (in *FeatureSourceStatus) DeepCopy() *FeatureSourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FeatureSourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Features) DeepCopyInto(out *Features) {
 	*out = *in
@@ -211,6 +323,11 @@ func (in *GroupRule) DeepCopyInto(out *GroupRule) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MatchExpression != nil {
+		in, out := &in.MatchExpression, &out.MatchExpression
+		*out = new(FeatureMatchExpression)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -386,6 +503,7 @@ func (in *NodeFeature) DeepCopyInto(out *NodeFeature) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -478,6 +596,20 @@ func (in *NodeFeatureGroupSpec) DeepCopyInto(out *NodeFeatureGroupSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Taints != nil {
+		in, out := &in.Taints, &out.Taints
+		*out = make([]v1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -494,10 +626,19 @@ func (in *NodeFeatureGroupSpec) DeepCopy() *NodeFeatureGroupSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeFeatureGroupStatus) DeepCopyInto(out *NodeFeatureGroupStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Nodes != nil {
 		in, out := &in.Nodes, &out.Nodes
 		*out = make([]FeatureGroupNode, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
@@ -551,6 +692,7 @@ func (in *NodeFeatureRule) DeepCopyInto(out *NodeFeatureRule) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -615,6 +757,11 @@ func (in *NodeFeatureRuleSpec) DeepCopyInto(out *NodeFeatureRuleSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -628,6 +775,36 @@ func (in *NodeFeatureRuleSpec) DeepCopy() *NodeFeatureRuleSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFeatureRuleStatus) DeepCopyInto(out *NodeFeatureRuleStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]RuleStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFeatureRuleStatus.
+func (in *NodeFeatureRuleStatus) DeepCopy() *NodeFeatureRuleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFeatureRuleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeFeatureSpec) DeepCopyInto(out *NodeFeatureSpec) {
 	*out = *in
@@ -652,6 +829,29 @@ func (in *NodeFeatureSpec) DeepCopy() *NodeFeatureSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFeatureStatus) DeepCopyInto(out *NodeFeatureStatus) {
+	*out = *in
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]FeatureSourceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFeatureStatus.
+func (in *NodeFeatureStatus) DeepCopy() *NodeFeatureStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFeatureStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Rule) DeepCopyInto(out *Rule) {
 	*out = *in
@@ -704,6 +904,11 @@ func (in *Rule) DeepCopyInto(out *Rule) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MatchExpression != nil {
+		in, out := &in.MatchExpression, &out.MatchExpression
+		*out = new(FeatureMatchExpression)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -716,3 +921,98 @@ func (in *Rule) DeepCopy() *Rule {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleError) DeepCopyInto(out *RuleError) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleError.
+func (in *RuleError) DeepCopy() *RuleError {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleError)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleShadowOutput) DeepCopyInto(out *RuleShadowOutput) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]ShadowOutputItem, len(*in))
+		copy(*out, *in)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make([]ShadowOutputItem, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExtendedResources != nil {
+		in, out := &in.ExtendedResources, &out.ExtendedResources
+		*out = make([]ShadowOutputItem, len(*in))
+		copy(*out, *in)
+	}
+	if in.Taints != nil {
+		in, out := &in.Taints, &out.Taints
+		*out = make([]ShadowOutputItem, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleShadowOutput.
+func (in *RuleShadowOutput) DeepCopy() *RuleShadowOutput {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleShadowOutput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleStatus) DeepCopyInto(out *RuleStatus) {
+	*out = *in
+	if in.LastError != nil {
+		in, out := &in.LastError, &out.LastError
+		*out = new(RuleError)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ShadowOutput != nil {
+		in, out := &in.ShadowOutput, &out.ShadowOutput
+		*out = new(RuleShadowOutput)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleStatus.
+func (in *RuleStatus) DeepCopy() *RuleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShadowOutputItem) DeepCopyInto(out *ShadowOutputItem) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShadowOutputItem.
+func (in *ShadowOutputItem) DeepCopy() *ShadowOutputItem {
+	if in == nil {
+		return nil
+	}
+	out := new(ShadowOutputItem)
+	in.DeepCopyInto(out)
+	return out
+}
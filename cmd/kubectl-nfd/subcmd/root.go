@@ -32,6 +32,8 @@ var (
 	node string
 	// kubeconfig file to use
 	kubeconfig string
+	// Print a full evaluation trace of the rules
+	explain bool
 )
 
 // RootCmd represents the base command when called without any subcommands
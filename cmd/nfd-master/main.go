@@ -115,8 +115,16 @@ func initFlags(flagset *flag.FlagSet) (*master.Args, *master.ConfigOverrideArgs)
 	flagset.StringVar(&args.Options, "options", "",
 		"Specify config options from command line. Config options are specified "+
 			"in the same format as in the config file (i.e. json or yaml). These options")
+	flagset.BoolVar(&args.ReloadOnSighup, "reload-on-sighup", false,
+		"Reload the configuration when receiving SIGHUP, in addition to reloading on changes of the config file.")
 	flagset.BoolVar(&args.EnableLeaderElection, "enable-leader-election", false,
 		"Enables a leader election. Enable this when running more than one replica on nfd master.")
+	flagset.IntVar(&args.WebhookPort, "webhook-port", 0,
+		"Port which the validating admission webhook is served on. The webhook is disabled if set to 0.")
+	flagset.StringVar(&args.WebhookCertFile, "webhook-cert-file", "/etc/kubernetes/node-feature-discovery/certs/tls.crt",
+		"TLS certificate file of the validating admission webhook.")
+	flagset.StringVar(&args.WebhookKeyFile, "webhook-key-file", "/etc/kubernetes/node-feature-discovery/certs/tls.key",
+		"TLS private key file of the validating admission webhook.")
 
 	args.Klog = klogutils.InitKlogFlags(flagset)
 
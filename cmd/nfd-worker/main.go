@@ -113,6 +113,10 @@ func initFlags(flagset *flag.FlagSet) (*worker.Args, *worker.ConfigOverrideArgs)
 	flagset.StringVar(&args.Options, "options", "",
 		"Specify config options from command line. Config options are specified "+
 			"in the same format as in the config file (i.e. json or yaml). These options")
+	flagset.BoolVar(&args.ReloadOnSighup, "reload-on-sighup", false,
+		"Reload the configuration when receiving SIGHUP, in addition to reloading on changes of the config file.")
+	flagset.StringVar(&args.FeaturesAPISocket, "features-api-socket", "",
+		"Unix socket on which to serve the discovered features and labels. Disabled if empty.")
 
 	args.Klog = klogutils.InitKlogFlags(flagset)
 
@@ -0,0 +1,151 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"k8s.io/utils/lru"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+)
+
+const (
+	// Names of the variables available in CEL expressions
+	varFlags      = "flags"
+	varAttributes = "attributes"
+	varInstances  = "instances"
+
+	// costLimit is the maximum runtime cost of evaluating one expression. It
+	// protects against runaway expressions, e.g. deeply nested
+	// comprehensions over large instance lists.
+	costLimit = 1000000
+
+	// programCacheSize is the maximum number of compiled programs to cache.
+	programCacheSize = 1024
+)
+
+var (
+	newEnv = sync.OnceValues(func() (*cel.Env, error) {
+		return cel.NewEnv(
+			// flags maps feature name to the list of flag names
+			cel.Variable(varFlags, cel.MapType(cel.StringType, cel.ListType(cel.StringType))),
+			// attributes maps feature name to a map of attribute names and values
+			cel.Variable(varAttributes, cel.MapType(cel.StringType, cel.MapType(cel.StringType, cel.StringType))),
+			// instances maps feature name to a list of instances, each being
+			// a map of attribute names and values
+			cel.Variable(varInstances, cel.MapType(cel.StringType, cel.ListType(cel.MapType(cel.StringType, cel.StringType)))),
+			ext.Strings(),
+			ext.Math(),
+			ext.Lists(),
+		)
+	})
+
+	programCache = lru.New(programCacheSize)
+)
+
+// Program is a compiled CEL expression that evaluates to a boolean.
+type Program struct {
+	program cel.Program
+}
+
+// NewProgram compiles a CEL expression. Compiled programs are cached so
+// compiling the same expression repeatedly is cheap.
+func NewProgram(expr string) (*Program, error) {
+	if p, ok := programCache.Get(expr); ok {
+		return p.(*Program), nil
+	}
+
+	env, err := newEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	ast, issues := env.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid CEL expression: %w", issues.Err())
+	}
+	if !ast.OutputType().IsExactType(cel.BoolType) {
+		return nil, fmt.Errorf("invalid CEL expression: must evaluate to bool, got %s", ast.OutputType())
+	}
+
+	prg, err := env.Program(ast, cel.CostLimit(costLimit))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CEL expression: %w", err)
+	}
+
+	p := &Program{program: prg}
+	programCache.Add(expr, p)
+	return p, nil
+}
+
+// Evaluate evaluates the program against a set of features.
+func (p *Program) Evaluate(features *nfdv1alpha1.Features) (bool, error) {
+	// The variables are converted lazily, only when referenced
+	vars := map[string]any{
+		varFlags:      func() any { return flagsToCEL(features.Flags) },
+		varAttributes: func() any { return attributesToCEL(features.Attributes) },
+		varInstances:  func() any { return instancesToCEL(features.Instances) },
+	}
+
+	out, _, err := p.program.Eval(vars)
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate CEL expression: %w", err)
+	}
+	isMatch, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("CEL expression evaluated to %s, expected bool", out.Type())
+	}
+	return isMatch, nil
+}
+
+func flagsToCEL(in map[string]nfdv1alpha1.FlagFeatureSet) map[string][]string {
+	out := make(map[string][]string, len(in))
+	for name, f := range in {
+		elems := make([]string, 0, len(f.Elements))
+		for e := range f.Elements {
+			elems = append(elems, e)
+		}
+		slices.Sort(elems)
+		out[name] = elems
+	}
+	return out
+}
+
+func attributesToCEL(in map[string]nfdv1alpha1.AttributeFeatureSet) map[string]map[string]string {
+	out := make(map[string]map[string]string, len(in))
+	for name, f := range in {
+		out[name] = f.Elements
+	}
+	return out
+}
+
+func instancesToCEL(in map[string]nfdv1alpha1.InstanceFeatureSet) map[string][]map[string]string {
+	out := make(map[string][]map[string]string, len(in))
+	for name, f := range in {
+		instances := make([]map[string]string, 0, len(f.Elements))
+		for _, i := range f.Elements {
+			instances = append(instances, i.Attributes)
+		}
+		out[name] = instances
+	}
+	return out
+}
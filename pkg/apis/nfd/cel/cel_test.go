@@ -0,0 +1,77 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+)
+
+func TestProgram(t *testing.T) {
+	f := nfdv1alpha1.NewFeatures()
+	f.Flags["cpu.cpuid"] = nfdv1alpha1.NewFlagFeatures("AVX", "AVX2")
+	f.Attributes["memory.hugepages"] = nfdv1alpha1.NewAttributeFeatures(map[string]string{"2Mi": "512", "1Gi": "4"})
+	f.Instances["pci.device"] = nfdv1alpha1.NewInstanceFeatures(
+		*nfdv1alpha1.NewInstanceFeature(map[string]string{"class": "0200", "vendor": "8086"}),
+		*nfdv1alpha1.NewInstanceFeature(map[string]string{"class": "0200", "vendor": "15b3"}),
+		*nfdv1alpha1.NewInstanceFeature(map[string]string{"class": "0300", "vendor": "10de"}),
+	)
+
+	tcs := []struct {
+		name        string
+		expr        string
+		expected    bool
+		compileErr  bool
+		evaluateErr bool
+	}{
+		{name: "flag present", expr: `"AVX2" in flags["cpu.cpuid"]`, expected: true},
+		{name: "flag not present", expr: `"AVX512F" in flags["cpu.cpuid"]`, expected: false},
+		{
+			name:     "arithmetic over attributes",
+			expr:     `int(attributes["memory.hugepages"]["2Mi"]) * 2 + int(attributes["memory.hugepages"]["1Gi"]) * 1024 >= 4096`,
+			expected: true,
+		},
+		{name: "count instances", expr: `instances["pci.device"].filter(d, d["class"] == "0200").size() == 2`, expected: true},
+		{name: "missing feature guarded", expr: `"kernel.config" in attributes && attributes["kernel.config"]["NO_HZ"] == "y"`, expected: false},
+		{name: "missing feature", expr: `attributes["kernel.config"]["NO_HZ"] == "y"`, evaluateErr: true},
+		{name: "syntax error", expr: `flags[`, compileErr: true},
+		{name: "undeclared variable", expr: `foo == 1`, compileErr: true},
+		{name: "non-bool result", expr: `instances["pci.device"].size()`, compileErr: true},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := NewProgram(tc.expr)
+			if tc.compileErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			match, err := p.Evaluate(f)
+			if tc.evaluateErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, match)
+		})
+	}
+}
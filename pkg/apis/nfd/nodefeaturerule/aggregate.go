@@ -0,0 +1,176 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodefeaturerule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+)
+
+// AggregatesSuffix is appended to the feature name to form the name under
+// which the results of aggregate functions are available in templates.
+const AggregatesSuffix = "_aggregates"
+
+// AggregateName returns the name of the result of an aggregate expression,
+// e.g. "count" or "sum_sriov_totalvfs".
+func AggregateName(a *nfdv1alpha1.AggregateExpression) string {
+	name := strings.ToLower(string(a.Func))
+	if a.Attribute != "" {
+		name += "_" + a.Attribute
+	}
+	return name
+}
+
+// MatchAggregates evaluates a list of aggregate expressions against a set of
+// instance features. If the MatchExpressionSet is non-nil, only the instances
+// matching it are aggregated over. Returns a boolean that reports whether all
+// of the aggregate expressions matched, the instances that were aggregated
+// over and the results of the aggregate functions as name-value elements.
+func MatchAggregates(aggregates []nfdv1alpha1.AggregateExpression, m *nfdv1alpha1.MatchExpressionSet, instances []nfdv1alpha1.InstanceFeature, failFast bool) (bool, []MatchedElement, []MatchedElement, error) {
+	selected := make([]MatchedElement, 0, len(instances))
+	if m != nil {
+		var err error
+		if _, selected, _, err = MatchGetInstances(m, instances, false); err != nil {
+			return false, nil, nil, err
+		}
+	} else {
+		for _, i := range instances {
+			selected = append(selected, i.Attributes)
+		}
+	}
+
+	isMatch := true
+	results := make([]MatchedElement, 0, len(aggregates))
+	for i := range aggregates {
+		a := &aggregates[i]
+		value, valid, err := aggregate(a, selected)
+		if err != nil {
+			return false, nil, nil, err
+		}
+		if valid {
+			results = append(results, MatchedElement{MatchedKeyName: AggregateName(a), MatchedKeyValue: value})
+		}
+
+		match, err := evaluateMatchExpression(&a.MatchExpression, valid, value)
+		if err != nil {
+			return false, nil, nil, err
+		}
+		if !match {
+			isMatch = false
+			if failFast {
+				break
+			}
+		}
+	}
+	return isMatch, selected, results, nil
+}
+
+// aggregate computes an aggregate function over a set of instances. The
+// attribute values are parsed according to the value type of the expression.
+// The result is returned as a string that can be evaluated against the
+// expression. The returned boolean is false if the result is undefined, i.e.
+// none of the instances has the attribute to aggregate over.
+func aggregate(a *nfdv1alpha1.AggregateExpression, instances []MatchedElement) (string, bool, error) {
+	if a.Func == nfdv1alpha1.AggregateCount {
+		if a.Attribute != "" {
+			return "", false, fmt.Errorf("invalid aggregate expression, 'attribute' field must be empty for Func %q (have %q)", a.Func, a.Attribute)
+		}
+		return strconv.Itoa(len(instances)), true, nil
+	}
+
+	if a.Attribute == "" {
+		return "", false, fmt.Errorf("invalid aggregate expression, 'attribute' field must be non-empty for Func %q", a.Func)
+	}
+	if a.Func == nfdv1alpha1.AggregateSum && a.Type == nfdv1alpha1.TypeVersion {
+		return "", false, fmt.Errorf("invalid aggregate expression, Func %q is not supported for 'type' %q", a.Func, a.Type)
+	}
+
+	var (
+		result    any
+		resultStr string
+		valid     bool
+	)
+	for _, i := range instances {
+		s, ok := i[a.Attribute]
+		if !ok {
+			continue
+		}
+		v, err := ParseValue(a.Type, s)
+		if err != nil {
+			return "", false, fmt.Errorf("%v in attribute %q", err, a.Attribute)
+		}
+
+		switch a.Func {
+		case nfdv1alpha1.AggregateSum:
+			if !valid {
+				result = v
+			} else {
+				result = addValues(result, v)
+			}
+		case nfdv1alpha1.AggregateMin:
+			if !valid || compareValues(v, result) < 0 {
+				result, resultStr = v, s
+			}
+		case nfdv1alpha1.AggregateMax:
+			if !valid || compareValues(v, result) > 0 {
+				result, resultStr = v, s
+			}
+		default:
+			return "", false, fmt.Errorf("invalid aggregate Func %q", a.Func)
+		}
+		valid = true
+	}
+	if valid && a.Func == nfdv1alpha1.AggregateSum {
+		resultStr = formatValue(result)
+	}
+	return resultStr, valid, nil
+}
+
+// addValues adds two numeric values returned by ParseValue for the same
+// value type.
+func addValues(a, b any) any {
+	switch a := a.(type) {
+	case int:
+		return a + b.(int)
+	case float64:
+		return a + b.(float64)
+	case resource.Quantity:
+		sum := a.DeepCopy()
+		sum.Add(b.(resource.Quantity))
+		return sum
+	}
+	panic(fmt.Sprintf("BUG: unsupported value type %T", a))
+}
+
+// formatValue formats a numeric value returned by ParseValue so that it can
+// be parsed again with the same value type.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case resource.Quantity:
+		return v.String()
+	}
+	panic(fmt.Sprintf("BUG: unsupported value type %T", v))
+}
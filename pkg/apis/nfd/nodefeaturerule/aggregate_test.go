@@ -0,0 +1,192 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodefeaturerule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+)
+
+func newAggregate(f nfdv1alpha1.AggregateFunc, attr string, op nfdv1alpha1.MatchOp, values ...string) nfdv1alpha1.AggregateExpression {
+	return nfdv1alpha1.AggregateExpression{Func: f, Attribute: attr, MatchExpression: *newMatchExpression(op, values...)}
+}
+
+func newTypedAggregate(f nfdv1alpha1.AggregateFunc, attr string, valueType nfdv1alpha1.ValueType, op nfdv1alpha1.MatchOp, values ...string) nfdv1alpha1.AggregateExpression {
+	a := newAggregate(f, attr, op, values...)
+	a.Type = valueType
+	return a
+}
+
+func TestMatchAggregates(t *testing.T) {
+	instances := []nfdv1alpha1.InstanceFeature{
+		*nfdv1alpha1.NewInstanceFeature(map[string]string{"name": "eth0", "sriov_totalvfs": "8", "mem": "512MiB", "load": "0.5", "cpu": "500m", "fw": "1.10.0"}),
+		*nfdv1alpha1.NewInstanceFeature(map[string]string{"name": "eth1", "sriov_totalvfs": "0", "mem": "256MiB", "load": "1", "cpu": "1", "fw": "1.9.1"}),
+		*nfdv1alpha1.NewInstanceFeature(map[string]string{"name": "eth2", "sriov_totalvfs": "64", "mem": "256MiB", "load": "0.25", "cpu": "500m"}),
+		*nfdv1alpha1.NewInstanceFeature(map[string]string{"name": "eth3"}),
+	}
+	sriov := &nfdv1alpha1.MatchExpressionSet{"sriov_totalvfs": newMatchExpression(nfdv1alpha1.MatchGt, "0")}
+
+	tcs := []struct {
+		name            string
+		aggregates      []nfdv1alpha1.AggregateExpression
+		mes             *nfdv1alpha1.MatchExpressionSet
+		expectMatch     bool
+		expectSelected  int
+		expectedResults []MatchedElement
+		expectErr       bool
+	}{
+		{
+			name:            "count all",
+			aggregates:      []nfdv1alpha1.AggregateExpression{newAggregate(nfdv1alpha1.AggregateCount, "", nfdv1alpha1.MatchGe, "4")},
+			expectMatch:     true,
+			expectSelected:  4,
+			expectedResults: []MatchedElement{{"Name": "count", "Value": "4"}},
+		},
+		{
+			name:            "count selected",
+			aggregates:      []nfdv1alpha1.AggregateExpression{newAggregate(nfdv1alpha1.AggregateCount, "", nfdv1alpha1.MatchGe, "4")},
+			mes:             sriov,
+			expectMatch:     false,
+			expectSelected:  2,
+			expectedResults: []MatchedElement{{"Name": "count", "Value": "2"}},
+		},
+		{
+			name:            "count zero",
+			aggregates:      []nfdv1alpha1.AggregateExpression{newAggregate(nfdv1alpha1.AggregateCount, "", nfdv1alpha1.MatchIn, "0")},
+			mes:             &nfdv1alpha1.MatchExpressionSet{"name": newMatchExpression(nfdv1alpha1.MatchIn, "eth9")},
+			expectMatch:     true,
+			expectedResults: []MatchedElement{{"Name": "count", "Value": "0"}},
+		},
+		{
+			name: "sum, min and max",
+			aggregates: []nfdv1alpha1.AggregateExpression{
+				newAggregate(nfdv1alpha1.AggregateSum, "sriov_totalvfs", nfdv1alpha1.MatchIn, "72"),
+				newAggregate(nfdv1alpha1.AggregateMin, "sriov_totalvfs", nfdv1alpha1.MatchIn, "0"),
+				newAggregate(nfdv1alpha1.AggregateMax, "sriov_totalvfs", nfdv1alpha1.MatchGtLt, "60", "70"),
+			},
+			expectMatch:    true,
+			expectSelected: 4,
+			expectedResults: []MatchedElement{
+				{"Name": "sum_sriov_totalvfs", "Value": "72"},
+				{"Name": "min_sriov_totalvfs", "Value": "0"},
+				{"Name": "max_sriov_totalvfs", "Value": "64"},
+			},
+		},
+		{
+			name: "typed values",
+			aggregates: []nfdv1alpha1.AggregateExpression{
+				newTypedAggregate(nfdv1alpha1.AggregateSum, "mem", nfdv1alpha1.TypeBytes, nfdv1alpha1.MatchGe, "1GiB"),
+				newTypedAggregate(nfdv1alpha1.AggregateMax, "mem", nfdv1alpha1.TypeBytes, nfdv1alpha1.MatchIn, "512MiB"),
+				newTypedAggregate(nfdv1alpha1.AggregateSum, "load", nfdv1alpha1.TypeFloat, nfdv1alpha1.MatchIn, "1.75"),
+				newTypedAggregate(nfdv1alpha1.AggregateSum, "cpu", nfdv1alpha1.TypeQuantity, nfdv1alpha1.MatchGe, "2"),
+				newTypedAggregate(nfdv1alpha1.AggregateMin, "fw", nfdv1alpha1.TypeVersion, nfdv1alpha1.MatchLt, "1.10"),
+			},
+			mes:            &nfdv1alpha1.MatchExpressionSet{"mem": newMatchExpression(nfdv1alpha1.MatchExists)},
+			expectMatch:    true,
+			expectSelected: 3,
+			expectedResults: []MatchedElement{
+				{"Name": "sum_mem", "Value": "1073741824"},
+				{"Name": "max_mem", "Value": "512MiB"},
+				{"Name": "sum_load", "Value": "1.75"},
+				{"Name": "sum_cpu", "Value": "2"},
+				{"Name": "min_fw", "Value": "1.9.1"},
+			},
+		},
+		{
+			name:       "sum of versions",
+			aggregates: []nfdv1alpha1.AggregateExpression{newTypedAggregate(nfdv1alpha1.AggregateSum, "fw", nfdv1alpha1.TypeVersion, nfdv1alpha1.MatchExists)},
+			expectErr:  true,
+		},
+		{
+			name:            "undefined result",
+			aggregates:      []nfdv1alpha1.AggregateExpression{newAggregate(nfdv1alpha1.AggregateMax, "speed", nfdv1alpha1.MatchGe, "0")},
+			expectMatch:     false,
+			expectSelected:  4,
+			expectedResults: []MatchedElement{},
+		},
+		{
+			name:       "non-numeric attribute",
+			aggregates: []nfdv1alpha1.AggregateExpression{newAggregate(nfdv1alpha1.AggregateSum, "name", nfdv1alpha1.MatchGe, "0")},
+			expectErr:  true,
+		},
+		{
+			name:       "missing attribute",
+			aggregates: []nfdv1alpha1.AggregateExpression{newAggregate(nfdv1alpha1.AggregateSum, "", nfdv1alpha1.MatchGe, "0")},
+			expectErr:  true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			match, selected, results, err := MatchAggregates(tc.aggregates, tc.mes, instances, false)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectMatch, match)
+			assert.Len(t, selected, tc.expectSelected)
+			assert.Equal(t, tc.expectedResults, results)
+		})
+	}
+}
+
+func TestRuleMatchAggregates(t *testing.T) {
+	f := nfdv1alpha1.NewFeatures()
+	f.Instances["network.device"] = nfdv1alpha1.NewInstanceFeatures(
+		*nfdv1alpha1.NewInstanceFeature(map[string]string{"name": "eth0", "sriov_totalvfs": "8"}),
+		*nfdv1alpha1.NewInstanceFeature(map[string]string{"name": "eth1", "sriov_totalvfs": "16"}),
+		*nfdv1alpha1.NewInstanceFeature(map[string]string{"name": "eth2", "sriov_totalvfs": "0"}),
+	)
+
+	r := &nfdv1alpha1.Rule{
+		Name: "rule-1",
+		LabelsTemplate: `{{range .network.device_aggregates}}sriov-nic-{{.Name}}={{.Value}}
+{{end}}`,
+		MatchFeatures: nfdv1alpha1.FeatureMatcher{
+			nfdv1alpha1.FeatureMatcherTerm{
+				Feature:          "network.device",
+				MatchExpressions: &nfdv1alpha1.MatchExpressionSet{"sriov_totalvfs": newMatchExpression(nfdv1alpha1.MatchGt, "0")},
+				MatchAggregates: []nfdv1alpha1.AggregateExpression{
+					newAggregate(nfdv1alpha1.AggregateCount, "", nfdv1alpha1.MatchGe, "2"),
+					newAggregate(nfdv1alpha1.AggregateSum, "sriov_totalvfs", nfdv1alpha1.MatchGe, "16"),
+				},
+			},
+		},
+	}
+
+	m, err := Execute(r, f, true)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"sriov-nic-count": "2", "sriov-nic-sum_sriov_totalvfs": "24"}, m.Labels)
+
+	trace := Explain(r, f)
+	assert.True(t, trace.IsMatch)
+	assert.Equal(t, []*MatchExpressionTrace{
+		{Name: "count", Op: nfdv1alpha1.MatchGe, Value: nfdv1alpha1.MatchValue{"2"}, Inputs: []string{"2"}, IsMatch: true},
+		{Name: "sum_sriov_totalvfs", Op: nfdv1alpha1.MatchGe, Value: nfdv1alpha1.MatchValue{"16"}, Inputs: []string{"24"}, IsMatch: true},
+	}, trace.MatchFeatures.Terms[0].MatchAggregates)
+
+	// At least 3 SR-IOV capable NICs
+	r.MatchFeatures[0].MatchAggregates[0] = newAggregate(nfdv1alpha1.AggregateCount, "", nfdv1alpha1.MatchGe, "3")
+	m, err = Execute(r, f, true)
+	assert.NoError(t, err)
+	assert.Nil(t, m.Labels)
+	assert.False(t, Explain(r, f).IsMatch)
+}
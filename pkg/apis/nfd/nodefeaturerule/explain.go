@@ -0,0 +1,370 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodefeaturerule
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+)
+
+// RuleTrace is a full trace of the evaluation of a rule. In contrast to
+// MatchStatus, it contains the result of every term and every expression of
+// the rule, whether they matched or not.
+// +k8s:deepcopy-gen=false
+type RuleTrace struct {
+	// Name of the rule.
+	Name string
+	// IsMatch informs whether the rule matched.
+	IsMatch bool
+	// MatchFeatures is the trace of the matchFeatures field of the rule. Nil
+	// if the rule does not have matchFeatures.
+	MatchFeatures *FeatureMatcherTrace
+	// MatchAny contains the traces of the elements of the matchAny field of
+	// the rule.
+	MatchAny []*FeatureMatcherTrace
+	// MatchExpression is the trace of the matchExpression field of the rule.
+	// Nil if the rule does not have matchExpression.
+	MatchExpression *FeatureMatchExpressionTrace
+	// CELExpression is the trace of the celExpression field of the rule. Nil
+	// if the rule does not have celExpression.
+	CELExpression *CELExpressionTrace
+}
+
+// CELExpressionTrace is the evaluation trace of a CEL expression.
+// +k8s:deepcopy-gen=false
+type CELExpressionTrace struct {
+	// Expression is the CEL expression.
+	Expression string
+	// IsMatch is the verdict of the expression.
+	IsMatch bool
+	// Error is the error encountered when evaluating the expression, if any.
+	Error string
+}
+
+// FeatureMatchExpressionTrace is the evaluation trace of a
+// FeatureMatchExpression tree.
+// +k8s:deepcopy-gen=false
+type FeatureMatchExpressionTrace struct {
+	// IsMatch informs whether the expression matched.
+	IsMatch bool
+	// MatchFeatures is the trace of the matchFeatures field. Nil if the
+	// expression does not have matchFeatures.
+	MatchFeatures *FeatureMatcherTrace
+	// AllOf contains the traces of the allOf sub-expressions.
+	AllOf []*FeatureMatchExpressionTrace
+	// AnyOf contains the traces of the anyOf sub-expressions.
+	AnyOf []*FeatureMatchExpressionTrace
+	// NoneOf contains the traces of the noneOf sub-expressions.
+	NoneOf []*FeatureMatchExpressionTrace
+}
+
+// FeatureMatcherTrace is the evaluation trace of a FeatureMatcher.
+// +k8s:deepcopy-gen=false
+type FeatureMatcherTrace struct {
+	// IsMatch informs whether all terms of the feature matcher matched.
+	IsMatch bool
+	// Terms contains the traces of the individual terms.
+	Terms []*FeatureMatcherTermTrace
+}
+
+// FeatureMatcherTermTrace is the evaluation trace of one FeatureMatcherTerm.
+// +k8s:deepcopy-gen=false
+type FeatureMatcherTermTrace struct {
+	// Feature is the name of the feature the term matches against.
+	Feature string
+	// Available informs whether the feature was available at all.
+	Available bool
+	// IsMatch informs whether the term matched.
+	IsMatch bool
+	// Error is the error encountered when evaluating the term, if any.
+	Error string
+	// MatchExpressions contains the traces of the individual expressions of
+	// matchExpressions, sorted by the element name.
+	MatchExpressions []*MatchExpressionTrace
+	// MatchName is the trace of the matchName expression. Nil if the term
+	// does not have matchName.
+	MatchName *MatchExpressionTrace
+	// MatchAggregates contains the traces of the individual aggregate
+	// expressions. The name of each trace is the name of the aggregate
+	// result, e.g. "count".
+	MatchAggregates []*MatchExpressionTrace
+}
+
+// MatchExpressionTrace is the evaluation trace of one MatchExpression.
+// +k8s:deepcopy-gen=false
+type MatchExpressionTrace struct {
+	// Name of the feature element the expression was evaluated against. Empty
+	// for matchName.
+	Name string
+	// Op is the operator of the expression.
+	Op nfdv1alpha1.MatchOp
+	// Value is the value of the expression.
+	Value nfdv1alpha1.MatchValue
+	// Inputs contains the input element values the expression was evaluated
+	// against, in human-readable form. For matchName, it contains the names
+	// of the matched elements.
+	Inputs []string
+	// IsMatch is the verdict of the expression.
+	IsMatch bool
+	// Error is the error encountered when evaluating the expression, if any.
+	Error string
+}
+
+// Explain evaluates the rule against a set of input features and returns a
+// full trace of the evaluation. The outcome of the rule is the same as with
+// Execute but, unlike Execute, Explain evaluates all terms and expressions and
+// does not stop on the first failure.
+func Explain(r *nfdv1alpha1.Rule, features *nfdv1alpha1.Features) *RuleTrace {
+	trace := &RuleTrace{Name: r.Name}
+
+	isMatch := true
+	if len(r.MatchAny) > 0 {
+		anyMatch := false
+		for _, matcher := range r.MatchAny {
+			t := explainFeatureMatcher(&matcher.MatchFeatures, features)
+			trace.MatchAny = append(trace.MatchAny, t)
+			anyMatch = anyMatch || t.IsMatch
+		}
+		isMatch = anyMatch
+	}
+
+	if len(r.MatchFeatures) > 0 {
+		trace.MatchFeatures = explainFeatureMatcher(&r.MatchFeatures, features)
+		isMatch = isMatch && trace.MatchFeatures.IsMatch
+	}
+
+	if r.MatchExpression != nil {
+		trace.MatchExpression = explainFeatureMatchExpression(r.MatchExpression, features)
+		isMatch = trace.MatchExpression.IsMatch && (isMatch || (len(r.MatchFeatures) == 0 && len(r.MatchAny) == 0))
+	}
+
+	if r.CELExpression != "" {
+		trace.CELExpression = &CELExpressionTrace{Expression: r.CELExpression}
+		match, err := evaluateCELExpression(r.CELExpression, features)
+		if err != nil {
+			trace.CELExpression.Error = err.Error()
+		}
+		trace.CELExpression.IsMatch = match
+		isMatch = match && (isMatch || (len(r.MatchFeatures) == 0 && len(r.MatchAny) == 0 && r.MatchExpression == nil))
+	}
+	trace.IsMatch = isMatch
+
+	return trace
+}
+
+func explainFeatureMatchExpression(e *nfdv1alpha1.FeatureMatchExpression, features *nfdv1alpha1.Features) *FeatureMatchExpressionTrace {
+	trace := &FeatureMatchExpressionTrace{IsMatch: true}
+
+	if len(e.MatchFeatures) > 0 {
+		trace.MatchFeatures = explainFeatureMatcher(&e.MatchFeatures, features)
+		trace.IsMatch = trace.MatchFeatures.IsMatch
+	}
+	for i := range e.AllOf {
+		t := explainFeatureMatchExpression(&e.AllOf[i], features)
+		trace.AllOf = append(trace.AllOf, t)
+		trace.IsMatch = trace.IsMatch && t.IsMatch
+	}
+	if len(e.AnyOf) > 0 {
+		anyMatch := false
+		for i := range e.AnyOf {
+			t := explainFeatureMatchExpression(&e.AnyOf[i], features)
+			trace.AnyOf = append(trace.AnyOf, t)
+			anyMatch = anyMatch || t.IsMatch
+		}
+		trace.IsMatch = trace.IsMatch && anyMatch
+	}
+	for i := range e.NoneOf {
+		t := explainFeatureMatchExpression(&e.NoneOf[i], features)
+		trace.NoneOf = append(trace.NoneOf, t)
+		trace.IsMatch = trace.IsMatch && !t.IsMatch
+	}
+	return trace
+}
+
+func explainFeatureMatcher(m *nfdv1alpha1.FeatureMatcher, features *nfdv1alpha1.Features) *FeatureMatcherTrace {
+	trace := &FeatureMatcherTrace{IsMatch: true}
+
+	// Logical AND over the terms
+	for _, term := range *m {
+		t := explainFeatureMatcherTerm(&term, features)
+		trace.Terms = append(trace.Terms, t)
+		trace.IsMatch = trace.IsMatch && t.IsMatch
+	}
+	return trace
+}
+
+func explainFeatureMatcherTerm(term *nfdv1alpha1.FeatureMatcherTerm, features *nfdv1alpha1.Features) *FeatureMatcherTermTrace {
+	featureName := strings.ToLower(term.Feature)
+	trace := &FeatureMatcherTermTrace{Feature: featureName}
+
+	fF, okF := features.Flags[featureName]
+	fA, okA := features.Attributes[featureName]
+	fI, okI := features.Instances[featureName]
+	trace.Available = okF || okA || okI
+	if !trace.Available {
+		return trace
+	}
+
+	// Trace the individual expressions
+	if term.MatchExpressions != nil {
+		names := make([]string, 0, len(*term.MatchExpressions))
+		for n := range *term.MatchExpressions {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			trace.MatchExpressions = append(trace.MatchExpressions,
+				explainMatchExpression(n, (*term.MatchExpressions)[n], fF.Elements, fA.Elements, fI.Elements))
+		}
+	}
+	if term.MatchName != nil {
+		trace.MatchName = explainMatchName(term.MatchName, fF.Elements, fA.Elements, fI.Elements)
+	}
+	for i := range term.MatchAggregates {
+		trace.MatchAggregates = append(trace.MatchAggregates,
+			explainMatchAggregate(&term.MatchAggregates[i], term.MatchExpressions, fI.Elements))
+	}
+
+	// The verdict of the term is determined with the same functions that
+	// Execute uses. Instance features can only be matched by evaluating all
+	// expressions against each instance as a whole.
+	var err error
+	trace.IsMatch = true
+	if len(term.MatchAggregates) > 0 {
+		trace.IsMatch, _, _, err = MatchAggregates(term.MatchAggregates, term.MatchExpressions, fI.Elements, false)
+	} else if term.MatchExpressions != nil {
+		trace.IsMatch, _, _, err = MatchMulti(term.MatchExpressions, fF.Elements, fA.Elements, fI.Elements, false)
+	}
+	if err == nil && trace.IsMatch && term.MatchName != nil {
+		trace.IsMatch, _, err = MatchNamesMulti(term.MatchName, fF.Elements, fA.Elements, fI.Elements)
+	}
+	if err != nil {
+		trace.IsMatch = false
+		trace.Error = err.Error()
+	}
+
+	return trace
+}
+
+func explainMatchExpression(name string, m *nfdv1alpha1.MatchExpression, keys map[string]nfdv1alpha1.Nil, values map[string]string, instances []nfdv1alpha1.InstanceFeature) *MatchExpressionTrace {
+	trace := &MatchExpressionTrace{Name: name, Op: m.Op, Value: m.Value}
+
+	var (
+		kvMatch   bool
+		instMatch bool
+		errs      []string
+	)
+	evaluate := func(valid bool, value interface{}, input string) bool {
+		trace.Inputs = append(trace.Inputs, input)
+		match, err := evaluateMatchExpression(m, valid, value)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		return match
+	}
+
+	if keys != nil || values != nil {
+		// DoesNotExist is special in that the name must not be found in
+		// either keys or values
+		kvMatch = m.Op == nfdv1alpha1.MatchDoesNotExist
+		if keys != nil {
+			_, ok := keys[name]
+			match := evaluate(ok, nil, "flag: "+presence(ok))
+			kvMatch = combineKeyValueMatch(m.Op, kvMatch, match)
+		}
+		if values != nil {
+			v, ok := values[name]
+			input := "attribute: " + presence(ok)
+			if ok {
+				input = fmt.Sprintf("attribute: %q", v)
+			}
+			match := evaluate(ok, v, input)
+			kvMatch = combineKeyValueMatch(m.Op, kvMatch, match)
+		}
+	}
+
+	for i, inst := range instances {
+		v, ok := inst.Attributes[name]
+		input := fmt.Sprintf("instance[%d]: %s", i, presence(ok))
+		if ok {
+			input = fmt.Sprintf("instance[%d]: %q", i, v)
+		}
+		if evaluate(ok, v, input) {
+			instMatch = true
+		}
+	}
+
+	slices.Sort(errs)
+	errs = slices.Compact(errs)
+	if len(errs) > 0 {
+		trace.Error = strings.Join(errs, "; ")
+		return trace
+	}
+	trace.IsMatch = kvMatch || instMatch
+
+	return trace
+}
+
+func explainMatchName(m *nfdv1alpha1.MatchExpression, keys map[string]nfdv1alpha1.Nil, values map[string]string, instances []nfdv1alpha1.InstanceFeature) *MatchExpressionTrace {
+	trace := &MatchExpressionTrace{Op: m.Op, Value: m.Value}
+
+	match, matched, err := MatchNamesMulti(m, keys, values, instances)
+	if err != nil {
+		trace.Error = err.Error()
+		return trace
+	}
+	trace.IsMatch = match
+	for _, e := range matched {
+		trace.Inputs = append(trace.Inputs, e[MatchedKeyName])
+	}
+
+	return trace
+}
+
+func explainMatchAggregate(a *nfdv1alpha1.AggregateExpression, m *nfdv1alpha1.MatchExpressionSet, instances []nfdv1alpha1.InstanceFeature) *MatchExpressionTrace {
+	trace := &MatchExpressionTrace{Name: AggregateName(a), Op: a.Op, Value: a.Value}
+
+	match, _, results, err := MatchAggregates([]nfdv1alpha1.AggregateExpression{*a}, m, instances, false)
+	if err != nil {
+		trace.Error = err.Error()
+		return trace
+	}
+	trace.IsMatch = match
+	if len(results) > 0 {
+		trace.Inputs = []string{results[0][MatchedKeyValue]}
+	}
+
+	return trace
+}
+
+func combineKeyValueMatch(op nfdv1alpha1.MatchOp, prev, match bool) bool {
+	if op == nfdv1alpha1.MatchDoesNotExist {
+		return prev && match
+	}
+	return prev || match
+}
+
+func presence(ok bool) string {
+	if ok {
+		return "present"
+	}
+	return "not present"
+}
@@ -17,7 +17,9 @@ limitations under the License.
 package nodefeaturerule
 
 import (
+	"cmp"
 	"fmt"
+	"math"
 	"regexp"
 	"slices"
 	"sort"
@@ -27,6 +29,7 @@ import (
 	"maps"
 
 	semver "github.com/Masterminds/semver/v3"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
 
 	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
@@ -124,88 +127,42 @@ func evaluateMatchExpression(m *nfdv1alpha1.MatchExpression, valid bool, value i
 				return false, fmt.Errorf("invalid expression, 'value' field must contain exactly one element for Op %q (have %v)", m.Op, m.Value)
 			}
 
-			switch m.Type {
-			case nfdv1alpha1.TypeVersion:
-				l, err := extractVersion(value)
-				if err != nil {
-					return false, fmt.Errorf("not a version %q", value)
-				}
-
-				r, err := extractVersion(m.Value[0])
-				if err != nil {
-					return false, fmt.Errorf("not a version %q in %v", m.Value[0], m)
-				}
-
-				if (m.Op == nfdv1alpha1.MatchLt && l.LessThan(r)) ||
-					(m.Op == nfdv1alpha1.MatchLe && l.LessThanEqual(r)) ||
-					(m.Op == nfdv1alpha1.MatchGt && l.GreaterThan(r)) ||
-					(m.Op == nfdv1alpha1.MatchGe && l.GreaterThanEqual(r)) {
-					return true, nil
-				}
-				return false, nil
-			case nfdv1alpha1.TypeEmpty:
-				l, err := strconv.Atoi(value)
-				if err != nil {
-					return false, fmt.Errorf("not a number %q", value)
-				}
-				r, err := strconv.Atoi(m.Value[0])
-				if err != nil {
-					return false, fmt.Errorf("not a number %q in %v", m.Value[0], m)
-				}
-
-				if (l < r && m.Op == nfdv1alpha1.MatchLt) || (l <= r && m.Op == nfdv1alpha1.MatchLe) ||
-					(l > r && m.Op == nfdv1alpha1.MatchGt) || (l >= r && m.Op == nfdv1alpha1.MatchGe) {
-					return true, nil
-				}
-			default:
-				return false, fmt.Errorf("invalid expression, 'type' field only accepts %q value for Op %q (have %q)", nfdv1alpha1.TypeVersion, m.Op, m.Type)
+			l, err := ParseValue(m.Type, value)
+			if err != nil {
+				return false, err
+			}
+			r, err := ParseValue(m.Type, m.Value[0])
+			if err != nil {
+				return false, fmt.Errorf("%w in %v", err, m)
 			}
 
+			c := compareValues(l, r)
+			return (c < 0 && m.Op == nfdv1alpha1.MatchLt) || (c <= 0 && m.Op == nfdv1alpha1.MatchLe) ||
+				(c > 0 && m.Op == nfdv1alpha1.MatchGt) || (c >= 0 && m.Op == nfdv1alpha1.MatchGe), nil
+
 		case nfdv1alpha1.MatchGtLt, nfdv1alpha1.MatchGeLe:
 			if len(m.Value) != 2 {
 				return false, fmt.Errorf("invalid expression, 'value' field must contain exactly two elements for Op %q (have %v)", m.Op, m.Value)
 			}
 
-			switch m.Type {
-			case nfdv1alpha1.TypeVersion:
-				v, err := extractVersion(value)
-				if err != nil {
-					return false, fmt.Errorf("not a version %q", value)
-				}
-				lr := make([]*semver.Version, 2)
-				for i := range 2 {
-					lr[i], err = extractVersion(m.Value[i])
-					if err != nil {
-						return false, fmt.Errorf("not a version %q in %v", m.Value[i], m)
-					}
-				}
-
-				if (m.Op == nfdv1alpha1.MatchGtLt && v.GreaterThan(lr[0]) && v.LessThan(lr[1])) ||
-					(m.Op == nfdv1alpha1.MatchGeLe && v.GreaterThanEqual(lr[0]) && v.LessThanEqual(lr[1])) {
-					return true, nil
-				}
-
-				return false, nil
-			case nfdv1alpha1.TypeEmpty:
-				v, err := strconv.Atoi(value)
+			v, err := ParseValue(m.Type, value)
+			if err != nil {
+				return false, err
+			}
+			lr := make([]any, 2)
+			for i := range 2 {
+				lr[i], err = ParseValue(m.Type, m.Value[i])
 				if err != nil {
-					return false, fmt.Errorf("not a number %q", value)
-				}
-				lr := make([]int, 2)
-				for i := range 2 {
-					lr[i], err = strconv.Atoi(m.Value[i])
-					if err != nil {
-						return false, fmt.Errorf("not a number %q in %v", m.Value[i], m)
-					}
+					return false, fmt.Errorf("%w in %v", err, m)
 				}
-				if lr[0] >= lr[1] {
-					return false, fmt.Errorf("invalid expression, value[0] must be less than Value[1] for Op %q (have %v)", m.Op, m.Value)
-				}
-				return (v > lr[0] && v < lr[1] && m.Op == nfdv1alpha1.MatchGtLt) ||
-					(v >= lr[0] && v <= lr[1] && m.Op == nfdv1alpha1.MatchGeLe), nil
-			default:
-				return false, fmt.Errorf("invalid expression, 'type' field only accepts %q value for Op %q (have %q)", nfdv1alpha1.TypeVersion, m.Op, m.Type)
 			}
+			if m.Type != nfdv1alpha1.TypeVersion && compareValues(lr[0], lr[1]) >= 0 {
+				return false, fmt.Errorf("invalid expression, value[0] must be less than Value[1] for Op %q (have %v)", m.Op, m.Value)
+			}
+
+			cl, cr := compareValues(v, lr[0]), compareValues(v, lr[1])
+			return (cl > 0 && cr < 0 && m.Op == nfdv1alpha1.MatchGtLt) ||
+				(cl >= 0 && cr <= 0 && m.Op == nfdv1alpha1.MatchGeLe), nil
 		case nfdv1alpha1.MatchIsTrue:
 			if len(m.Value) != 0 {
 				return false, fmt.Errorf("invalid expression, 'value' field must be empty for Op %q (have %v)", m.Op, m.Value)
@@ -591,6 +548,104 @@ func MatchNamesMulti(m *nfdv1alpha1.MatchExpression, keys map[string]nfdv1alpha1
 	return len(ret) > 0, ret, nil
 }
 
+// ParseValue parses a string value of the given value type into a form that
+// can be compared with other values of the same type.
+func ParseValue(t nfdv1alpha1.ValueType, value string) (any, error) {
+	switch t {
+	case nfdv1alpha1.TypeEmpty:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("not a number %q", value)
+		}
+		return v, nil
+	case nfdv1alpha1.TypeVersion:
+		v, err := extractVersion(value)
+		if err != nil {
+			return nil, fmt.Errorf("not a version %q", value)
+		}
+		return v, nil
+	case nfdv1alpha1.TypeFloat:
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || math.IsNaN(v) {
+			return nil, fmt.Errorf("not a float %q", value)
+		}
+		return v, nil
+	case nfdv1alpha1.TypeQuantity:
+		v, err := resource.ParseQuantity(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("not a quantity %q", value)
+		}
+		return v, nil
+	case nfdv1alpha1.TypeBytes:
+		v, err := parseBytes(value)
+		if err != nil {
+			return nil, fmt.Errorf("not a byte size %q", value)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("invalid expression, unsupported 'type' %q", t)
+}
+
+// compareValues compares two values returned by ParseValue for the same
+// value type. The result is -1 if a < b, 0 if a == b and +1 if a > b.
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case int:
+		return cmp.Compare(a, b.(int))
+	case float64:
+		return cmp.Compare(a, b.(float64))
+	case *semver.Version:
+		return a.Compare(b.(*semver.Version))
+	case resource.Quantity:
+		return a.Cmp(b.(resource.Quantity))
+	}
+	panic(fmt.Sprintf("BUG: unsupported value type %T", a))
+}
+
+// byteUnitRegexp matches the unit suffix of a byte size: an optional
+// decimal (e.g. "k") or binary (e.g. "Ki") prefix, optionally followed by "B".
+var byteUnitRegexp = regexp.MustCompile(`^(?i)(?:([kmgtpe])(i?))?b?$`)
+
+// bytePrefixExponents contains the exponents of the unit prefixes supported
+// by the bytes value type. Decimal prefixes are powers of 1000 and binary
+// prefixes powers of 1024.
+var bytePrefixExponents = map[string]float64{
+	"k": 1,
+	"m": 2,
+	"g": 3,
+	"t": 4,
+	"p": 5,
+	"e": 6,
+}
+
+// parseBytes parses a byte size with an optional, case insensitive unit
+// suffix, e.g. "512", "2048KiB", "1.5 GB" or "4G".
+func parseBytes(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	i := strings.IndexFunc(value, func(r rune) bool { return !(r >= '0' && r <= '9' || r == '.') })
+	if i < 0 {
+		i = len(value)
+	}
+
+	num, err := strconv.ParseFloat(value[:i], 64)
+	if err != nil || num < 0 {
+		return 0, fmt.Errorf("invalid number %q", value[:i])
+	}
+
+	unit := byteUnitRegexp.FindStringSubmatch(strings.TrimSpace(value[i:]))
+	if unit == nil {
+		return 0, fmt.Errorf("invalid unit %q", value[i:])
+	}
+	if unit[1] == "" {
+		return num, nil
+	}
+	base := 1000.0
+	if unit[2] != "" {
+		base = 1024
+	}
+	return num * math.Pow(base, bytePrefixExponents[strings.ToLower(unit[1])]), nil
+}
+
 func extractVersion(v string) (*semver.Version, error) {
 	version, err := semver.NewVersion(v)
 	if err != nil {
@@ -146,6 +146,101 @@ func TestEvaluateMatchExpression(t *testing.T) {
 	}
 }
 
+func TestEvaluateMatchExpressionTyped(t *testing.T) {
+	type V = nfdv1alpha1.MatchValue
+	tcs := []struct {
+		name      string
+		op        nfdv1alpha1.MatchOp
+		valueType nfdv1alpha1.ValueType
+		values    V
+		input     string
+		result    BoolAssertionFunc
+		err       ValueAssertionFunc
+	}{
+		{name: "float-1", op: nfdv1alpha1.MatchGt, valueType: nfdv1alpha1.TypeFloat, values: V{"2.5"}, input: "2.51", result: assert.True, err: assert.Nil},
+		{name: "float-2", op: nfdv1alpha1.MatchLe, valueType: nfdv1alpha1.TypeFloat, values: V{"-1e3"}, input: "-1000", result: assert.True, err: assert.Nil},
+		{name: "float-3", op: nfdv1alpha1.MatchGtLt, valueType: nfdv1alpha1.TypeFloat, values: V{"0.1", "0.2"}, input: "0.2", result: assert.False, err: assert.Nil},
+		{name: "float-err-1", op: nfdv1alpha1.MatchGt, valueType: nfdv1alpha1.TypeFloat, values: V{"1"}, input: "1Gi", result: assert.False, err: assert.NotNil},
+
+		{name: "quantity-1", op: nfdv1alpha1.MatchGe, valueType: nfdv1alpha1.TypeQuantity, values: V{"1Gi"}, input: "1024Mi", result: assert.True, err: assert.Nil},
+		{name: "quantity-2", op: nfdv1alpha1.MatchLt, valueType: nfdv1alpha1.TypeQuantity, values: V{"1G"}, input: "1Gi", result: assert.False, err: assert.Nil},
+		{name: "quantity-3", op: nfdv1alpha1.MatchGeLe, valueType: nfdv1alpha1.TypeQuantity, values: V{"500m", "2"}, input: "1", result: assert.True, err: assert.Nil},
+		{name: "quantity-err-1", op: nfdv1alpha1.MatchGtLt, valueType: nfdv1alpha1.TypeQuantity, values: V{"2", "1"}, input: "1", result: assert.False, err: assert.NotNil},
+		{name: "quantity-err-2", op: nfdv1alpha1.MatchGt, valueType: nfdv1alpha1.TypeQuantity, values: V{"1 Gi"}, input: "1", result: assert.False, err: assert.NotNil},
+
+		{name: "bytes-1", op: nfdv1alpha1.MatchGe, valueType: nfdv1alpha1.TypeBytes, values: V{"2MiB"}, input: "2048KiB", result: assert.True, err: assert.Nil},
+		{name: "bytes-2", op: nfdv1alpha1.MatchGt, valueType: nfdv1alpha1.TypeBytes, values: V{"1G"}, input: "1.5 GiB", result: assert.True, err: assert.Nil},
+		{name: "bytes-3", op: nfdv1alpha1.MatchLt, valueType: nfdv1alpha1.TypeBytes, values: V{"1k"}, input: "1024", result: assert.False, err: assert.Nil},
+		{name: "bytes-err-1", op: nfdv1alpha1.MatchLt, valueType: nfdv1alpha1.TypeBytes, values: V{"1k"}, input: "1 XB", result: assert.False, err: assert.NotNil},
+		{name: "bytes-err-2", op: nfdv1alpha1.MatchLt, valueType: nfdv1alpha1.TypeBytes, values: V{"-1"}, input: "1", result: assert.False, err: assert.NotNil},
+		{name: "bytes-err-3", op: nfdv1alpha1.MatchLt, valueType: nfdv1alpha1.TypeBytes, values: V{"1k"}, input: "1ib", result: assert.False, err: assert.NotNil},
+
+		{name: "version-1", op: nfdv1alpha1.MatchGt, valueType: nfdv1alpha1.TypeVersion, values: V{"5.4"}, input: "5.10.1", result: assert.True, err: assert.Nil},
+
+		{name: "invalid-type", op: nfdv1alpha1.MatchGt, valueType: "foo", values: V{"1"}, input: "2", result: assert.False, err: assert.NotNil},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			me := &nfdv1alpha1.MatchExpression{Op: tc.op, Value: tc.values, Type: tc.valueType}
+			res, err := evaluateMatchExpression(me, true, tc.input)
+			tc.result(t, res)
+			tc.err(t, err)
+		})
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	tcs := []struct {
+		input    string
+		expected float64
+		err      ValueAssertionFunc
+	}{
+		{input: "512", expected: 512, err: assert.Nil},
+		{input: "512B", expected: 512, err: assert.Nil},
+		{input: " 1.5 ", expected: 1.5, err: assert.Nil},
+
+		// Decimal prefixes
+		{input: "1k", expected: 1e3, err: assert.Nil},
+		{input: "1kB", expected: 1e3, err: assert.Nil},
+		{input: "2 MB", expected: 2e6, err: assert.Nil},
+		{input: "1.5GB", expected: 1.5e9, err: assert.Nil},
+		{input: "1tb", expected: 1e12, err: assert.Nil},
+		{input: "1P", expected: 1e15, err: assert.Nil},
+		{input: "1EB", expected: 1e18, err: assert.Nil},
+
+		// Binary prefixes
+		{input: "1Ki", expected: 1 << 10, err: assert.Nil},
+		{input: "1KiB", expected: 1 << 10, err: assert.Nil},
+		{input: "2 MiB", expected: 2 << 20, err: assert.Nil},
+		{input: "1.5GiB", expected: 1.5 * (1 << 30), err: assert.Nil},
+		{input: "1tib", expected: 1 << 40, err: assert.Nil},
+		{input: "1Pi", expected: 1 << 50, err: assert.Nil},
+		{input: "1EiB", expected: 1 << 60, err: assert.Nil},
+
+		// Malformed
+		{input: "", err: assert.NotNil},
+		{input: "kB", err: assert.NotNil},
+		{input: "-1", err: assert.NotNil},
+		{input: "1.2.3", err: assert.NotNil},
+		{input: "1ib", err: assert.NotNil},
+		{input: "1i", err: assert.NotNil},
+		{input: "1bb", err: assert.NotNil},
+		{input: "1kk", err: assert.NotNil},
+		{input: "1kbi", err: assert.NotNil},
+		{input: "1XB", err: assert.NotNil},
+		{input: "1 k B", err: assert.NotNil},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.input, func(t *testing.T) {
+			v, err := parseBytes(tc.input)
+			tc.err(t, err)
+			assert.Equal(t, tc.expected, v)
+		})
+	}
+}
+
 func TestEvaluateMatchExpressionKeys(t *testing.T) {
 	type V = nfdv1alpha1.MatchValue
 	type I = map[string]nfdv1alpha1.Nil
@@ -512,3 +512,50 @@ var-2=
 	assert.Nilf(t, err, "unexpected error: %v", err)
 	assert.Equal(t, map[string]string(nil), m.Vars, "instances should have matched")
 }
+
+func TestGroupRuleMatchExpression(t *testing.T) {
+	f := nfdv1alpha1.NewFeatures()
+	f.Flags["domain-1.kf-1"] = nfdv1alpha1.NewFlagFeatures("a", "b")
+
+	r := &nfdv1alpha1.GroupRule{
+		Vars: map[string]string{"var-1": "true"},
+		MatchExpression: &nfdv1alpha1.FeatureMatchExpression{
+			AnyOf: []nfdv1alpha1.FeatureMatchExpression{
+				{
+					MatchFeatures: nfdv1alpha1.FeatureMatcher{
+						nfdv1alpha1.FeatureMatcherTerm{
+							Feature:   "domain-1.kf-1",
+							MatchName: newMatchExpression(nfdv1alpha1.MatchIn, "a"),
+						},
+					},
+				},
+				{
+					MatchFeatures: nfdv1alpha1.FeatureMatcher{
+						nfdv1alpha1.FeatureMatcherTerm{
+							Feature:   "domain-1.kf-1",
+							MatchName: newMatchExpression(nfdv1alpha1.MatchIn, "c"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	m, err := ExecuteGroupRule(r, f, true)
+	assert.NoError(t, err)
+	assert.Equal(t, r.Vars, m.Vars)
+
+	r.MatchExpression.NoneOf = []nfdv1alpha1.FeatureMatchExpression{
+		{
+			MatchFeatures: nfdv1alpha1.FeatureMatcher{
+				nfdv1alpha1.FeatureMatcherTerm{
+					Feature:   "domain-1.kf-1",
+					MatchName: newMatchExpression(nfdv1alpha1.MatchIn, "b"),
+				},
+			},
+		},
+	}
+	m, err = ExecuteGroupRule(r, f, true)
+	assert.NoError(t, err)
+	assert.Nil(t, m.Vars)
+}
@@ -26,6 +26,7 @@ import (
 	"k8s.io/klog/v2"
 
 	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+	nfdcel "sigs.k8s.io/node-feature-discovery/pkg/apis/nfd/cel"
 	"sigs.k8s.io/node-feature-discovery/pkg/apis/nfd/template"
 	"sigs.k8s.io/node-feature-discovery/pkg/utils"
 )
@@ -42,6 +43,9 @@ type MatchStatus struct {
 	IsMatch bool
 	// MatchAny represents an array of logical OR conditions between MatchFeatureStatus entries.
 	MatchAny []*MatchFeatureStatus
+	// MatchExpression represents the merged status of the matching branches
+	// of the MatchExpression tree of the rule.
+	MatchExpression *MatchFeatureStatus
 }
 
 // MatchFeatureStatus represents a matched expression
@@ -131,6 +135,39 @@ func Execute(r *nfdv1alpha1.Rule, features *nfdv1alpha1.Features, failFast bool)
 		}
 	}
 
+	if r.MatchExpression != nil {
+		var exprMatch bool
+		if exprMatch, matchStatus.MatchExpression, err = evaluateFeatureMatchExpression(r.MatchExpression, features, failFast); err != nil {
+			return RuleOutput{}, err
+		}
+		// MatchExpression is combined with matchFeatures and matchAny with a logical AND
+		isMatch = exprMatch && (isMatch || (len(r.MatchFeatures) == 0 && len(r.MatchAny) == 0))
+		if !isMatch {
+			klog.V(2).InfoS("rule did not match", "ruleName", r.Name)
+			return RuleOutput{MatchStatus: &matchStatus}, nil
+		}
+		klog.V(4).InfoS("matchExpression matched", "ruleName", r.Name, "matchedFeatures", utils.DelayedDumper(matchStatus.MatchExpression.MatchedFeatures))
+		if err := executeTemplate(r.LabelsTemplate, matchStatus.MatchExpression.MatchedFeatures, labels); err != nil {
+			return RuleOutput{}, err
+		}
+		if err := executeTemplate(r.VarsTemplate, matchStatus.MatchExpression.MatchedFeatures, vars); err != nil {
+			return RuleOutput{}, err
+		}
+	}
+
+	if r.CELExpression != "" {
+		var celMatch bool
+		if celMatch, err = evaluateCELExpression(r.CELExpression, features); err != nil {
+			return RuleOutput{}, err
+		}
+		// CELExpression is combined with other matchers with a logical AND
+		isMatch = celMatch && (isMatch || (len(r.MatchFeatures) == 0 && len(r.MatchAny) == 0 && r.MatchExpression == nil))
+		if !isMatch {
+			klog.V(2).InfoS("rule did not match", "ruleName", r.Name)
+			return RuleOutput{MatchStatus: &matchStatus}, nil
+		}
+	}
+
 	maps.Copy(labels, r.Labels)
 	maps.Copy(vars, r.Vars)
 	matchStatus.IsMatch = isMatch
@@ -203,6 +240,38 @@ func ExecuteGroupRule(r *nfdv1alpha1.GroupRule, features *nfdv1alpha1.Features,
 		}
 	}
 
+	if r.MatchExpression != nil {
+		var (
+			exprMatch bool
+			err       error
+		)
+		if exprMatch, matchStatus.MatchExpression, err = evaluateFeatureMatchExpression(r.MatchExpression, features, failFast); err != nil {
+			return GroupRuleOutput{}, err
+		}
+		// MatchExpression is combined with matchFeatures and matchAny with a logical AND
+		isMatch = exprMatch && (isMatch || (len(r.MatchFeatures) == 0 && len(r.MatchAny) == 0))
+		if !isMatch {
+			klog.V(2).InfoS("rule did not match", "ruleName", r.Name)
+			return GroupRuleOutput{MatchStatus: &matchStatus}, nil
+		}
+		if err := executeTemplate(r.VarsTemplate, matchStatus.MatchExpression.MatchedFeatures, vars); err != nil {
+			return GroupRuleOutput{}, err
+		}
+	}
+
+	if r.CELExpression != "" {
+		celMatch, err := evaluateCELExpression(r.CELExpression, features)
+		if err != nil {
+			return GroupRuleOutput{}, err
+		}
+		// CELExpression is combined with other matchers with a logical AND
+		isMatch = celMatch && (isMatch || (len(r.MatchFeatures) == 0 && len(r.MatchAny) == 0 && r.MatchExpression == nil))
+		if !isMatch {
+			klog.V(2).InfoS("rule did not match", "ruleName", r.Name)
+			return GroupRuleOutput{MatchStatus: &matchStatus}, nil
+		}
+	}
+
 	maps.Copy(vars, r.Vars)
 	matchStatus.IsMatch = isMatch
 
@@ -236,6 +305,104 @@ type matchedFeatures map[string]domainMatchedFeatures
 
 type domainMatchedFeatures map[string][]MatchedElement
 
+// evaluateFeatureMatchExpression evaluates a boolean expression tree of
+// feature matchers. The matched features of all matching branches (excluding
+// the negated noneOf branches) are merged into the returned status.
+func evaluateFeatureMatchExpression(e *nfdv1alpha1.FeatureMatchExpression, features *nfdv1alpha1.Features, failFast bool) (bool, *MatchFeatureStatus, error) {
+	isMatch := true
+	status := &MatchFeatureStatus{MatchedFeatures: make(matchedFeatures)}
+
+	if len(e.MatchFeatures) > 0 {
+		matched, s, err := evaluateFeatureMatcher(&e.MatchFeatures, features, failFast)
+		if err != nil {
+			return false, nil, err
+		} else if !matched {
+			if failFast {
+				return false, status, nil
+			}
+			isMatch = false
+		} else {
+			status.merge(s)
+		}
+	}
+
+	// Logical AND over the allOf sub-expressions
+	for i := range e.AllOf {
+		matched, s, err := evaluateFeatureMatchExpression(&e.AllOf[i], features, failFast)
+		if err != nil {
+			return false, nil, err
+		} else if !matched {
+			if failFast {
+				return false, status, nil
+			}
+			isMatch = false
+		} else {
+			status.merge(s)
+		}
+	}
+
+	// Logical OR over the anyOf sub-expressions. All sub-expressions are
+	// evaluated in order to get the matched features of all of them.
+	if len(e.AnyOf) > 0 {
+		anyMatch := false
+		for i := range e.AnyOf {
+			matched, s, err := evaluateFeatureMatchExpression(&e.AnyOf[i], features, failFast)
+			if err != nil {
+				return false, nil, err
+			} else if matched {
+				anyMatch = true
+				status.merge(s)
+			}
+		}
+		if !anyMatch {
+			if failFast {
+				return false, status, nil
+			}
+			isMatch = false
+		}
+	}
+
+	// Logical NOR over the noneOf sub-expressions
+	for i := range e.NoneOf {
+		matched, _, err := evaluateFeatureMatchExpression(&e.NoneOf[i], features, failFast)
+		if err != nil {
+			return false, nil, err
+		} else if matched {
+			if failFast {
+				return false, status, nil
+			}
+			isMatch = false
+		}
+	}
+
+	return isMatch, status, nil
+}
+
+// evaluateCELExpression evaluates a CEL expression against the features.
+func evaluateCELExpression(expr string, features *nfdv1alpha1.Features) (bool, error) {
+	p, err := nfdcel.NewProgram(expr)
+	if err != nil {
+		return false, err
+	}
+	return p.Evaluate(features)
+}
+
+// merge merges the matched features and terms of another status into s.
+func (s *MatchFeatureStatus) merge(other *MatchFeatureStatus) {
+	if other == nil {
+		return
+	}
+	for dom, domFeatures := range other.MatchedFeatures {
+		if _, ok := s.MatchedFeatures[dom]; !ok {
+			s.MatchedFeatures[dom] = make(domainMatchedFeatures)
+		}
+		for name, elems := range domFeatures {
+			s.MatchedFeatures[dom][name] = append(s.MatchedFeatures[dom][name], elems...)
+		}
+	}
+	s.MatchedFeaturesTerms = append(s.MatchedFeaturesTerms, other.MatchedFeaturesTerms...)
+}
+
 func evaluateMatchAnyElem(e *nfdv1alpha1.MatchAnyElem, features *nfdv1alpha1.Features, failFast bool) (bool, *MatchFeatureStatus, error) {
 	return evaluateFeatureMatcher(&e.MatchFeatures, features, failFast)
 }
@@ -285,7 +452,15 @@ func evaluateFeatureMatcher(m *nfdv1alpha1.FeatureMatcher, features *nfdv1alpha1
 			continue
 		}
 
-		if term.MatchExpressions != nil {
+		if len(term.MatchAggregates) > 0 {
+			var aggregates []MatchedElement
+			isTermMatch, matchedElems, aggregates, err = MatchAggregates(term.MatchAggregates, term.MatchExpressions, fI.Elements, failFast)
+			if isTermMatch {
+				matchedFeatureTerm.MatchExpressions = term.MatchExpressions
+				matchedFeatureTerm.MatchAggregates = term.MatchAggregates
+			}
+			status.MatchedFeatures[dom][nam+AggregatesSuffix] = append(status.MatchedFeatures[dom][nam+AggregatesSuffix], aggregates...)
+		} else if term.MatchExpressions != nil {
 			isTermMatch, matchedElems, matchedExpressions, err = MatchMulti(term.MatchExpressions, fF.Elements, fA.Elements, fI.Elements, failFast)
 			matchedFeatureTerm.MatchExpressions = matchedExpressions
 		}
@@ -302,7 +477,7 @@ func evaluateFeatureMatcher(m *nfdv1alpha1.FeatureMatcher, features *nfdv1alpha1
 		}
 
 		status.MatchedFeatures[dom][nam] = append(status.MatchedFeatures[dom][nam], matchedElems...)
-		if matchedFeatureTerm.MatchName != nil || (matchedFeatureTerm.MatchExpressions != nil && len(*matchedFeatureTerm.MatchExpressions) > 0) {
+		if matchedFeatureTerm.MatchName != nil || (matchedFeatureTerm.MatchExpressions != nil && len(*matchedFeatureTerm.MatchExpressions) > 0) || len(matchedFeatureTerm.MatchAggregates) > 0 {
 			status.MatchedFeaturesTerms = append(status.MatchedFeaturesTerms, matchedFeatureTerm)
 		}
 
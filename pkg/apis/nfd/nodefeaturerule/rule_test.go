@@ -557,3 +557,204 @@ var-2=
 	assert.Nilf(t, err, "unexpected error: %v", err)
 	assert.Equal(t, map[string]string(nil), m.Labels, "instances should have matched")
 }
+
+func TestExplain(t *testing.T) {
+	f := nfdv1alpha1.NewFeatures()
+	f.Flags["domain-1.kf-1"] = nfdv1alpha1.NewFlagFeatures("key-1")
+	f.Attributes["domain-1.vf-1"] = nfdv1alpha1.NewAttributeFeatures(map[string]string{"key-1": "val-1"})
+	f.Instances["domain-1.if-1"] = nfdv1alpha1.NewInstanceFeatures(
+		*nfdv1alpha1.NewInstanceFeature(map[string]string{"attr-1": "val-1"}),
+		*nfdv1alpha1.NewInstanceFeature(map[string]string{"attr-1": "val-2"}),
+	)
+
+	r := &nfdv1alpha1.Rule{
+		Name:   "rule-1",
+		Labels: map[string]string{"label-1": "true"},
+		MatchFeatures: nfdv1alpha1.FeatureMatcher{
+			nfdv1alpha1.FeatureMatcherTerm{
+				Feature: "domain-1.kf-1",
+				MatchExpressions: &nfdv1alpha1.MatchExpressionSet{
+					"key-1": newMatchExpression(nfdv1alpha1.MatchExists),
+					"key-2": newMatchExpression(nfdv1alpha1.MatchExists),
+				},
+			},
+			nfdv1alpha1.FeatureMatcherTerm{
+				Feature: "domain-1.vf-1",
+				MatchExpressions: &nfdv1alpha1.MatchExpressionSet{
+					"key-1": newMatchExpression(nfdv1alpha1.MatchIn, "val-1"),
+				},
+			},
+			nfdv1alpha1.FeatureMatcherTerm{
+				Feature: "domain-1.missing",
+			},
+		},
+		MatchAny: []nfdv1alpha1.MatchAnyElem{
+			{
+				MatchFeatures: nfdv1alpha1.FeatureMatcher{
+					nfdv1alpha1.FeatureMatcherTerm{
+						Feature: "domain-1.if-1",
+						MatchExpressions: &nfdv1alpha1.MatchExpressionSet{
+							"attr-1": newMatchExpression(nfdv1alpha1.MatchIn, "val-2"),
+						},
+					},
+				},
+			},
+			{
+				MatchFeatures: nfdv1alpha1.FeatureMatcher{
+					nfdv1alpha1.FeatureMatcherTerm{
+						Feature:   "domain-1.kf-1",
+						MatchName: newMatchExpression("invalid-op"),
+					},
+				},
+			},
+		},
+	}
+
+	trace := Explain(r, f)
+	assert.Equal(t, "rule-1", trace.Name)
+	assert.False(t, trace.IsMatch)
+
+	// All terms are evaluated, even after a failed one
+	assert.False(t, trace.MatchFeatures.IsMatch)
+	assert.Len(t, trace.MatchFeatures.Terms, 3)
+
+	term := trace.MatchFeatures.Terms[0]
+	assert.True(t, term.Available)
+	assert.False(t, term.IsMatch)
+	assert.Equal(t, []*MatchExpressionTrace{
+		{Name: "key-1", Op: nfdv1alpha1.MatchExists, Inputs: []string{"flag: present"}, IsMatch: true},
+		{Name: "key-2", Op: nfdv1alpha1.MatchExists, Inputs: []string{"flag: not present"}, IsMatch: false},
+	}, term.MatchExpressions)
+
+	term = trace.MatchFeatures.Terms[1]
+	assert.True(t, term.IsMatch)
+	assert.Equal(t, []string{`attribute: "val-1"`}, term.MatchExpressions[0].Inputs)
+
+	term = trace.MatchFeatures.Terms[2]
+	assert.False(t, term.Available)
+	assert.False(t, term.IsMatch)
+
+	// MatchAny
+	assert.Len(t, trace.MatchAny, 2)
+	assert.True(t, trace.MatchAny[0].IsMatch)
+	assert.Equal(t, []string{`instance[0]: "val-1"`, `instance[1]: "val-2"`}, trace.MatchAny[0].Terms[0].MatchExpressions[0].Inputs)
+	assert.False(t, trace.MatchAny[1].IsMatch)
+	assert.NotEmpty(t, trace.MatchAny[1].Terms[0].Error)
+	assert.NotEmpty(t, trace.MatchAny[1].Terms[0].MatchName.Error)
+
+	// Make the rule match
+	f.Flags["domain-1.kf-1"].Elements["key-2"] = nfdv1alpha1.Nil{}
+	f.Flags["domain-1.missing"] = nfdv1alpha1.NewFlagFeatures()
+	trace = Explain(r, f)
+	assert.True(t, trace.IsMatch)
+	m, err := Execute(r, f, true)
+	assert.NoError(t, err)
+	assert.Equal(t, r.Labels, m.Labels, "Explain and Execute should agree")
+}
+
+func TestRuleMatchExpression(t *testing.T) {
+	f := nfdv1alpha1.NewFeatures()
+	f.Flags["domain_1.kf_1"] = nfdv1alpha1.NewFlagFeatures("a")
+	f.Attributes["domain_1.vf_1"] = nfdv1alpha1.NewAttributeFeatures(map[string]string{"b": "false", "c": "false"})
+
+	featureMatcher := func(feature, name string, expr *nfdv1alpha1.MatchExpression) nfdv1alpha1.FeatureMatcher {
+		return nfdv1alpha1.FeatureMatcher{
+			nfdv1alpha1.FeatureMatcherTerm{
+				Feature:          feature,
+				MatchExpressions: &nfdv1alpha1.MatchExpressionSet{name: expr},
+			},
+		}
+	}
+
+	// A and not (B or C)
+	r := &nfdv1alpha1.Rule{
+		Name:           "rule-1",
+		Labels:         map[string]string{"label-1": "true"},
+		LabelsTemplate: "{{range .domain_1.kf_1}}flag-{{.Name}}=true\n{{end}}",
+		MatchExpression: &nfdv1alpha1.FeatureMatchExpression{
+			MatchFeatures: featureMatcher("domain_1.kf_1", "a", newMatchExpression(nfdv1alpha1.MatchExists)),
+			NoneOf: []nfdv1alpha1.FeatureMatchExpression{
+				{
+					AnyOf: []nfdv1alpha1.FeatureMatchExpression{
+						{MatchFeatures: featureMatcher("domain_1.vf_1", "b", newMatchExpression(nfdv1alpha1.MatchIsTrue))},
+						{MatchFeatures: featureMatcher("domain_1.vf_1", "c", newMatchExpression(nfdv1alpha1.MatchIsTrue))},
+					},
+				},
+			},
+		},
+	}
+
+	m, err := Execute(r, f, true)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"label-1": "true", "flag-a": "true"}, m.Labels)
+	assert.True(t, Explain(r, f).IsMatch)
+
+	// B matches, so the rule must not match
+	f.Attributes["domain_1.vf_1"].Elements["b"] = "true"
+	m, err = Execute(r, f, true)
+	assert.NoError(t, err)
+	assert.Nil(t, m.Labels)
+	trace := Explain(r, f)
+	assert.False(t, trace.IsMatch)
+	assert.True(t, trace.MatchExpression.MatchFeatures.IsMatch)
+	assert.True(t, trace.MatchExpression.NoneOf[0].IsMatch)
+	assert.True(t, trace.MatchExpression.NoneOf[0].AnyOf[0].IsMatch)
+	assert.False(t, trace.MatchExpression.NoneOf[0].AnyOf[1].IsMatch)
+
+	// MatchExpression is ANDed with matchFeatures
+	f.Attributes["domain_1.vf_1"].Elements["b"] = "false"
+	r.MatchFeatures = featureMatcher("domain_1.kf_1", "x", newMatchExpression(nfdv1alpha1.MatchExists))
+	m, err = Execute(r, f, false)
+	assert.NoError(t, err)
+	assert.Nil(t, m.Labels)
+	assert.False(t, Explain(r, f).IsMatch)
+
+	// Errors in nested expressions are returned
+	r.MatchFeatures = nil
+	r.MatchExpression.AllOf = []nfdv1alpha1.FeatureMatchExpression{
+		{MatchFeatures: featureMatcher("domain_1.vf_1", "b", newMatchExpression(nfdv1alpha1.MatchIn))},
+	}
+	_, err = Execute(r, f, true)
+	assert.Error(t, err)
+}
+
+func TestRuleCELExpression(t *testing.T) {
+	f := nfdv1alpha1.NewFeatures()
+	f.Flags["domain-1.kf-1"] = nfdv1alpha1.NewFlagFeatures("key-1")
+	f.Instances["domain-1.if-1"] = nfdv1alpha1.NewInstanceFeatures(
+		*nfdv1alpha1.NewInstanceFeature(map[string]string{"attr-1": "1"}),
+		*nfdv1alpha1.NewInstanceFeature(map[string]string{"attr-1": "2"}),
+	)
+
+	r := &nfdv1alpha1.Rule{
+		Name:          "rule-1",
+		Labels:        map[string]string{"label-1": "true"},
+		CELExpression: `math.greatest(instances["domain-1.if-1"].map(i, int(i["attr-1"]))) == 2`,
+	}
+	m, err := Execute(r, f, true)
+	assert.NoError(t, err)
+	assert.Equal(t, r.Labels, m.Labels)
+	assert.True(t, Explain(r, f).IsMatch)
+
+	// CELExpression is ANDed with matchFeatures
+	r.MatchFeatures = nfdv1alpha1.FeatureMatcher{
+		nfdv1alpha1.FeatureMatcherTerm{
+			Feature:          "domain-1.kf-1",
+			MatchExpressions: &nfdv1alpha1.MatchExpressionSet{"key-1": newMatchExpression(nfdv1alpha1.MatchExists)},
+		},
+	}
+	r.CELExpression = `instances["domain-1.if-1"].size() > 2`
+	m, err = Execute(r, f, true)
+	assert.NoError(t, err)
+	assert.Nil(t, m.Labels)
+	trace := Explain(r, f)
+	assert.False(t, trace.IsMatch)
+	assert.True(t, trace.MatchFeatures.IsMatch)
+	assert.False(t, trace.CELExpression.IsMatch)
+
+	// Invalid expression
+	r.CELExpression = `instances[`
+	_, err = Execute(r, f, true)
+	assert.Error(t, err)
+	assert.NotEmpty(t, Explain(r, f).CELExpression.Error)
+}
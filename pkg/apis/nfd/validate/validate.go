@@ -22,9 +22,12 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	k8sQuantity "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
 
 	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+	nfdcel "sigs.k8s.io/node-feature-discovery/pkg/apis/nfd/cel"
+	"sigs.k8s.io/node-feature-discovery/pkg/apis/nfd/nodefeaturerule"
 	"sigs.k8s.io/node-feature-discovery/pkg/apis/nfd/template"
 )
 
@@ -39,6 +42,115 @@ var (
 	ErrEmptyTaintEffect = fmt.Errorf("empty taint effect")
 )
 
+// NodeFeatureRuleSpec validates the spec of a NodeFeatureRule and returns a
+// slice of errors if it is invalid. See Rule for the validation of individual
+// rules.
+func NodeFeatureRuleSpec(spec *nfdv1alpha1.NodeFeatureRuleSpec) []error {
+	validationErr := NodeSelector(spec.NodeSelector)
+	names := make([]string, 0, len(spec.Rules))
+	for i := range spec.Rules {
+		validationErr = append(validationErr, Rule(&spec.Rules[i])...)
+		names = append(names, spec.Rules[i].Name)
+	}
+	return append(validationErr, RuleNames(names)...)
+}
+
+// RuleNames checks that the rule names of a NodeFeatureRule are unique. The
+// per-rule status of a NodeFeatureRule is keyed by the rule name.
+func RuleNames(names []string) []error {
+	var validationErr []error
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		if _, ok := seen[name]; ok && name != "" {
+			validationErr = append(validationErr, fmt.Errorf("duplicate rule name %q", name))
+		}
+		seen[name] = struct{}{}
+	}
+	return validationErr
+}
+
+// Rule validates a rule of a NodeFeatureRule and returns a slice of errors if
+// the rule is invalid. Label names are not validated as their validity
+// depends on the configuration of nfd-master. Use Labels for validating them
+// against the default configuration.
+func Rule(rule *nfdv1alpha1.Rule) []error {
+	var validationErr []error
+
+	// Validate Rule Name
+	if rule.Name == "" {
+		validationErr = append(validationErr, fmt.Errorf("rule name cannot be empty"))
+	}
+
+	// Validate Annotations
+	validationErr = append(validationErr, Annotations(rule.Annotations)...)
+
+	// Validate Taints
+	validationErr = append(validationErr, Taints(rule.Taints)...)
+
+	// Validate extended Resources
+	// Dummy dynamic values before validating extended resources
+	validationErr = append(validationErr, ExtendedResources(DummyDynamicValues(rule.ExtendedResources))...)
+
+	// Validate LabelsTemplate
+	validationErr = append(validationErr, Template(rule.LabelsTemplate)...)
+
+	// Validate VarsTemplate
+	validationErr = append(validationErr, Template(rule.VarsTemplate)...)
+
+	// Validate matchers
+	validationErr = append(validationErr, MatchFeatures(rule.MatchFeatures)...)
+	validationErr = append(validationErr, MatchAny(rule.MatchAny)...)
+	validationErr = append(validationErr, MatchExpression(rule.MatchExpression)...)
+	validationErr = append(validationErr, CELExpression(rule.CELExpression)...)
+
+	return validationErr
+}
+
+// NodeFeatureGroupSpec validates the spec of a NodeFeatureGroup and returns a
+// slice of errors if it is invalid. Label names are not validated, similar to
+// Rule.
+func NodeFeatureGroupSpec(spec *nfdv1alpha1.NodeFeatureGroupSpec) []error {
+	validationErr := Taints(spec.Taints)
+	for i := range spec.Rules {
+		validationErr = append(validationErr, GroupRule(&spec.Rules[i])...)
+	}
+	return validationErr
+}
+
+// GroupRule validates a rule of a NodeFeatureGroup and returns a slice of
+// errors if the rule is invalid.
+func GroupRule(rule *nfdv1alpha1.GroupRule) []error {
+	var validationErr []error
+
+	if rule.Name == "" {
+		validationErr = append(validationErr, fmt.Errorf("rule name cannot be empty"))
+	}
+	validationErr = append(validationErr, Template(rule.VarsTemplate)...)
+	validationErr = append(validationErr, MatchFeatures(rule.MatchFeatures)...)
+	validationErr = append(validationErr, MatchAny(rule.MatchAny)...)
+	validationErr = append(validationErr, MatchExpression(rule.MatchExpression)...)
+	validationErr = append(validationErr, CELExpression(rule.CELExpression)...)
+
+	return validationErr
+}
+
+// DummyDynamicValues returns a copy of a map where dynamic values (i.e.
+// values referencing a feature with the "@" prefix) are replaced with a dummy
+// value. Dynamic values can only be resolved against the features of a node.
+func DummyDynamicValues(in map[string]string) map[string]string {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		if strings.HasPrefix(v, "@") {
+			v = k8sQuantity.NewQuantity(0, k8sQuantity.DecimalSI).String()
+		}
+		out[k] = v
+	}
+	return out
+}
+
 // MatchAny validates a slice of MatchAnyElem and returns a slice of errors if
 // any of the MatchAnyElem are invalid.
 func MatchAny(matchAny []nfdv1alpha1.MatchAnyElem) []error {
@@ -61,8 +173,102 @@ func MatchFeatures(matchFeature nfdv1alpha1.FeatureMatcher) []error {
 		if len(nameSplit) != 2 {
 			validationErr = append(validationErr, fmt.Errorf("invalid feature name %v (not <domain>.<feature>), cannot be used for templating", match.Feature))
 		}
+		if match.MatchExpressions != nil {
+			for name, e := range *match.MatchExpressions {
+				if err := matchExpressionValues(e); err != nil {
+					validationErr = append(validationErr, fmt.Errorf("invalid expression %q for feature %v: %v", name, match.Feature, err))
+				}
+			}
+		}
+		if match.MatchName != nil {
+			if err := matchExpressionValues(match.MatchName); err != nil {
+				validationErr = append(validationErr, fmt.Errorf("invalid matchName for feature %v: %v", match.Feature, err))
+			}
+		}
+		for _, a := range match.MatchAggregates {
+			if err := matchExpressionValues(&a.MatchExpression); err != nil {
+				validationErr = append(validationErr, fmt.Errorf("invalid aggregate expression %q for feature %v: %v", nodefeaturerule.AggregateName(&a), match.Feature, err))
+			}
+			switch {
+			case a.Func == nfdv1alpha1.AggregateCount && a.Attribute != "":
+				validationErr = append(validationErr, fmt.Errorf("invalid aggregate expression for feature %v, attribute must be empty for Func %q", match.Feature, a.Func))
+			case a.Func != nfdv1alpha1.AggregateCount && a.Attribute == "":
+				validationErr = append(validationErr, fmt.Errorf("invalid aggregate expression for feature %v, attribute must be specified for Func %q", match.Feature, a.Func))
+			case a.Func == nfdv1alpha1.AggregateSum && a.Type == nfdv1alpha1.TypeVersion:
+				validationErr = append(validationErr, fmt.Errorf("invalid aggregate expression for feature %v, Func %q is not supported for type %q", match.Feature, a.Func, a.Type))
+			}
+		}
+	}
+
+	return validationErr
+}
+
+// matchExpressionValues checks that the values of a comparison expression
+// are valid for the value type of the expression.
+func matchExpressionValues(e *nfdv1alpha1.MatchExpression) error {
+	switch e.Op {
+	case nfdv1alpha1.MatchGt, nfdv1alpha1.MatchGe, nfdv1alpha1.MatchLt, nfdv1alpha1.MatchLe,
+		nfdv1alpha1.MatchGtLt, nfdv1alpha1.MatchGeLe:
+	default:
+		return nil
+	}
+
+	for _, v := range e.Value {
+		if _, err := nodefeaturerule.ParseValue(e.Type, v); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+// MatchExpression validates a FeatureMatchExpression tree and returns a slice
+// of errors if any of the nodes of the tree are invalid.
+func MatchExpression(expr *nfdv1alpha1.FeatureMatchExpression) []error {
+	return matchExpression(expr, "matchExpression")
+}
 
+func matchExpression(expr *nfdv1alpha1.FeatureMatchExpression, path string) []error {
+	if expr == nil {
+		return nil
+	}
+
+	var validationErr []error
+	if len(expr.MatchFeatures) == 0 && len(expr.AllOf) == 0 && len(expr.AnyOf) == 0 && len(expr.NoneOf) == 0 {
+		validationErr = append(validationErr, fmt.Errorf("invalid %s: empty expression", path))
+	}
+	validationErr = append(validationErr, MatchFeatures(expr.MatchFeatures)...)
+	for i := range expr.AllOf {
+		validationErr = append(validationErr, matchExpression(&expr.AllOf[i], fmt.Sprintf("%s.allOf[%d]", path, i))...)
+	}
+	for i := range expr.AnyOf {
+		validationErr = append(validationErr, matchExpression(&expr.AnyOf[i], fmt.Sprintf("%s.anyOf[%d]", path, i))...)
+	}
+	for i := range expr.NoneOf {
+		validationErr = append(validationErr, matchExpression(&expr.NoneOf[i], fmt.Sprintf("%s.noneOf[%d]", path, i))...)
+	}
+
+	return validationErr
+}
+
+// CELExpression validates a CEL expression and returns a slice of errors if
+// the expression is invalid.
+func CELExpression(expr string) []error {
+	if expr == "" {
+		return nil
+	}
+	if _, err := nfdcel.NewProgram(expr); err != nil {
+		return []error{err}
+	}
+	return nil
+}
+
+// NodeSelector validates the node selector of a NodeFeatureRule and returns a
+// slice of errors if the selector is invalid.
+func NodeSelector(nodeSelector *metav1.LabelSelector) []error {
+	var validationErr []error
+	if _, err := metav1.LabelSelectorAsSelector(nodeSelector); err != nil {
+		validationErr = append(validationErr, fmt.Errorf("invalid nodeSelector: %w", err))
+	}
 	return validationErr
 }
 
@@ -22,6 +22,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
 )
 
@@ -487,6 +488,56 @@ func TestMatchFeatures(t *testing.T) {
 				fmt.Errorf("invalid feature name prefix.domain.feature (not <domain>.<feature>), cannot be used for templating"),
 			},
 		},
+		{
+			name: "Typed values",
+			matchFeature: nfdv1alpha1.FeatureMatcher{
+				{
+					Feature: "memory.hugepages",
+					MatchExpressions: &nfdv1alpha1.MatchExpressionSet{
+						"size":  &nfdv1alpha1.MatchExpression{Op: nfdv1alpha1.MatchGe, Value: nfdv1alpha1.MatchValue{"2Mi"}, Type: nfdv1alpha1.TypeQuantity},
+						"free":  &nfdv1alpha1.MatchExpression{Op: nfdv1alpha1.MatchGt, Value: nfdv1alpha1.MatchValue{"2048 kB"}, Type: nfdv1alpha1.TypeBytes},
+						"ratio": &nfdv1alpha1.MatchExpression{Op: nfdv1alpha1.MatchGtLt, Value: nfdv1alpha1.MatchValue{"0.5", "1.5"}, Type: nfdv1alpha1.TypeFloat},
+						"name":  &nfdv1alpha1.MatchExpression{Op: nfdv1alpha1.MatchIn, Value: nfdv1alpha1.MatchValue{"foo"}, Type: nfdv1alpha1.TypeFloat},
+					},
+				},
+			},
+			expectedErrors: nil,
+		},
+		{
+			name: "Mismatched typed value",
+			matchFeature: nfdv1alpha1.FeatureMatcher{
+				{
+					Feature: "memory.hugepages",
+					MatchExpressions: &nfdv1alpha1.MatchExpressionSet{
+						"size": &nfdv1alpha1.MatchExpression{Op: nfdv1alpha1.MatchGe, Value: nfdv1alpha1.MatchValue{"1Gi"}, Type: nfdv1alpha1.TypeFloat},
+					},
+					MatchName: &nfdv1alpha1.MatchExpression{Op: nfdv1alpha1.MatchGt, Value: nfdv1alpha1.MatchValue{"1x"}, Type: nfdv1alpha1.TypeBytes},
+				},
+			},
+			expectedErrors: []error{
+				fmt.Errorf("invalid expression \"size\" for feature memory.hugepages: not a float \"1Gi\""),
+				fmt.Errorf("invalid matchName for feature memory.hugepages: not a byte size \"1x\""),
+			},
+		},
+		{
+			name: "Invalid matchAggregates",
+			matchFeature: nfdv1alpha1.FeatureMatcher{
+				{
+					Feature: "network.device",
+					MatchAggregates: []nfdv1alpha1.AggregateExpression{
+						{Func: nfdv1alpha1.AggregateCount, Attribute: "mtu"},
+						{Func: nfdv1alpha1.AggregateSum},
+						{Func: nfdv1alpha1.AggregateMax, Attribute: "mtu"},
+						{Func: nfdv1alpha1.AggregateSum, Attribute: "fw", MatchExpression: nfdv1alpha1.MatchExpression{Op: nfdv1alpha1.MatchExists, Type: nfdv1alpha1.TypeVersion}},
+					},
+				},
+			},
+			expectedErrors: []error{
+				fmt.Errorf("invalid aggregate expression for feature network.device, attribute must be empty for Func \"Count\""),
+				fmt.Errorf("invalid aggregate expression for feature network.device, attribute must be specified for Func \"Sum\""),
+				fmt.Errorf("invalid aggregate expression for feature network.device, Func \"Sum\" is not supported for type \"version\""),
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -603,6 +654,143 @@ func TestTemplate(t *testing.T) {
 	}
 }
 
+func TestNodeSelector(t *testing.T) {
+	tests := []struct {
+		name         string
+		nodeSelector *metav1.LabelSelector
+		wantErr      bool
+	}{
+		{
+			name:         "Nil selector",
+			nodeSelector: nil,
+		},
+		{
+			name: "Valid selector",
+			nodeSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"pool": "gpu"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "zone", Operator: metav1.LabelSelectorOpIn, Values: []string{"a", "b"}},
+				},
+			},
+		},
+		{
+			name: "Invalid operator",
+			nodeSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "zone", Operator: "Foo"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid label key",
+			nodeSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"invalid key": "value"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := NodeSelector(tt.nodeSelector)
+			if tt.wantErr {
+				assert.Len(t, errs, 1)
+			} else {
+				assert.Empty(t, errs)
+			}
+		})
+	}
+}
+
+func TestRuleNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		want  []error
+	}{
+		{
+			name:  "Unique names",
+			names: []string{"rule-1", "rule-2"},
+		},
+		{
+			name:  "Duplicate names",
+			names: []string{"rule-1", "rule-2", "rule-1", "rule-1"},
+			want: []error{
+				fmt.Errorf("duplicate rule name \"rule-1\""),
+				fmt.Errorf("duplicate rule name \"rule-1\""),
+			},
+		},
+		{
+			name:  "Empty names are reported by Rule",
+			names: []string{"", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := RuleNames(tt.names)
+			assert.Equal(t, len(tt.want), len(errs))
+			for i := range errs {
+				assert.EqualError(t, errs[i], tt.want[i].Error())
+			}
+		})
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	tests := []struct {
+		name           string
+		expr           *nfdv1alpha1.FeatureMatchExpression
+		expectedErrors []error
+	}{
+		{
+			name: "Nil expression",
+		},
+		{
+			name: "Valid expression",
+			expr: &nfdv1alpha1.FeatureMatchExpression{
+				MatchFeatures: nfdv1alpha1.FeatureMatcher{{Feature: "domain.feature1"}},
+				NoneOf: []nfdv1alpha1.FeatureMatchExpression{
+					{
+						AnyOf: []nfdv1alpha1.FeatureMatchExpression{
+							{MatchFeatures: nfdv1alpha1.FeatureMatcher{{Feature: "domain.feature2"}}},
+							{MatchFeatures: nfdv1alpha1.FeatureMatcher{{Feature: "domain.feature3"}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid expression",
+			expr: &nfdv1alpha1.FeatureMatchExpression{
+				AllOf: []nfdv1alpha1.FeatureMatchExpression{
+					{MatchFeatures: nfdv1alpha1.FeatureMatcher{{Feature: "invalid-feature"}}},
+					{},
+				},
+			},
+			expectedErrors: []error{
+				fmt.Errorf("invalid feature name invalid-feature (not <domain>.<feature>), cannot be used for templating"),
+				fmt.Errorf("invalid matchExpression.allOf[1]: empty expression"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors := MatchExpression(tt.expr)
+			assert.Equal(t, tt.expectedErrors, errors)
+		})
+	}
+}
+
+func TestCELExpression(t *testing.T) {
+	assert.Empty(t, CELExpression(""))
+	assert.Empty(t, CELExpression(`"AVX" in flags["cpu.cpuid"]`))
+	assert.Len(t, CELExpression(`flags[`), 1)
+	assert.Len(t, CELExpression(`size(flags)`), 1, "non-bool expression should be rejected")
+}
+
 func sortErrors(errs []error) []error {
 	sort.Slice(errs, func(i, j int) bool {
 		return errs[i].Error() < errs[j].Error()
@@ -31,7 +31,7 @@ import (
 	"sigs.k8s.io/node-feature-discovery/pkg/apis/nfd/validate"
 )
 
-func DryRun(nodefeaturerulepath, nodefeaturepath string) []error {
+func DryRun(nodefeaturerulepath, nodefeaturepath string, explain bool) []error {
 	var errs []error
 	nfr := nfdv1alpha1.NodeFeatureRule{}
 	nf := nfdv1alpha1.NodeFeature{}
@@ -56,12 +56,12 @@ func DryRun(nodefeaturerulepath, nodefeaturepath string) []error {
 		return []error{fmt.Errorf("error parsing NodeFeatureRule: %w", err)}
 	}
 
-	errs = append(errs, processNodeFeatureRule(nfr, nf.Spec)...)
+	errs = append(errs, processNodeFeatureRule(nfr, nf.Spec, explain)...)
 
 	return errs
 }
 
-func processNodeFeatureRule(nodeFeatureRule nfdv1alpha1.NodeFeatureRule, nodeFeature nfdv1alpha1.NodeFeatureSpec) []error {
+func processNodeFeatureRule(nodeFeatureRule nfdv1alpha1.NodeFeatureRule, nodeFeature nfdv1alpha1.NodeFeatureSpec, explain bool) []error {
 	var errs []error
 	var taints []corev1.Taint
 
@@ -71,6 +71,9 @@ func processNodeFeatureRule(nodeFeatureRule nfdv1alpha1.NodeFeatureRule, nodeFea
 
 	for _, rule := range nodeFeatureRule.Spec.Rules {
 		fmt.Println("Processing rule: ", rule.Name)
+		if explain {
+			printRuleTrace(os.Stdout, nodefeaturerule.Explain(&rule, &nodeFeature.Features))
+		}
 		ruleOut, err := nodefeaturerule.Execute(&rule, &nodeFeature.Features, true)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("failed to process rule: %q - %w", rule.Name, err))
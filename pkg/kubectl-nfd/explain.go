@@ -0,0 +1,146 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubectlnfd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/apis/nfd/nodefeaturerule"
+)
+
+// printRuleTrace prints the evaluation trace of a rule as a tree.
+func printRuleTrace(w io.Writer, trace *nodefeaturerule.RuleTrace) {
+	fmt.Fprintf(w, "Rule %q: %s\n", trace.Name, verdict(trace.IsMatch, ""))
+	if trace.MatchFeatures == nil && len(trace.MatchAny) == 0 && trace.MatchExpression == nil && trace.CELExpression == nil {
+		fmt.Fprintln(w, "└─ no matchers, rule is always applied")
+		return
+	}
+
+	n := len(trace.MatchAny)
+	if trace.MatchFeatures != nil {
+		n++
+	}
+	if trace.MatchExpression != nil {
+		n++
+	}
+	if trace.CELExpression != nil {
+		n++
+	}
+	for i, t := range trace.MatchAny {
+		printFeatureMatcherTrace(w, fmt.Sprintf("matchAny[%d]", i), t, "", i == n-1)
+	}
+	if trace.MatchFeatures != nil {
+		printFeatureMatcherTrace(w, "matchFeatures", trace.MatchFeatures, "", trace.MatchExpression == nil && trace.CELExpression == nil)
+	}
+	if trace.MatchExpression != nil {
+		printFeatureMatchExpressionTrace(w, "matchExpression", trace.MatchExpression, "", trace.CELExpression == nil)
+	}
+	if trace.CELExpression != nil {
+		branch, _ := treeBranch("", true)
+		fmt.Fprintf(w, "%scelExpression %q: %s\n", branch, trace.CELExpression.Expression, verdict(trace.CELExpression.IsMatch, trace.CELExpression.Error))
+	}
+}
+
+func printFeatureMatchExpressionTrace(w io.Writer, name string, trace *nodefeaturerule.FeatureMatchExpressionTrace, indent string, last bool) {
+	branch, childIndent := treeBranch(indent, last)
+	fmt.Fprintf(w, "%s%s: %s\n", branch, name, verdict(trace.IsMatch, ""))
+
+	n := len(trace.AllOf) + len(trace.AnyOf) + len(trace.NoneOf)
+	if trace.MatchFeatures != nil {
+		printFeatureMatcherTrace(w, "matchFeatures", trace.MatchFeatures, childIndent, n == 0)
+	}
+	i := 0
+	for _, sub := range []struct {
+		name   string
+		traces []*nodefeaturerule.FeatureMatchExpressionTrace
+	}{{"allOf", trace.AllOf}, {"anyOf", trace.AnyOf}, {"noneOf", trace.NoneOf}} {
+		for j, t := range sub.traces {
+			i++
+			printFeatureMatchExpressionTrace(w, fmt.Sprintf("%s[%d]", sub.name, j), t, childIndent, i == n)
+		}
+	}
+}
+
+func printFeatureMatcherTrace(w io.Writer, name string, trace *nodefeaturerule.FeatureMatcherTrace, indent string, last bool) {
+	branch, childIndent := treeBranch(indent, last)
+	fmt.Fprintf(w, "%s%s: %s\n", branch, name, verdict(trace.IsMatch, ""))
+	for i, t := range trace.Terms {
+		printFeatureMatcherTermTrace(w, t, childIndent, i == len(trace.Terms)-1)
+	}
+}
+
+func printFeatureMatcherTermTrace(w io.Writer, trace *nodefeaturerule.FeatureMatcherTermTrace, indent string, last bool) {
+	branch, childIndent := treeBranch(indent, last)
+	if !trace.Available {
+		fmt.Fprintf(w, "%sfeature %s: %s\n", branch, trace.Feature, verdict(false, "feature not available"))
+		return
+	}
+	fmt.Fprintf(w, "%sfeature %s: %s\n", branch, trace.Feature, verdict(trace.IsMatch, trace.Error))
+
+	n := len(trace.MatchExpressions) + len(trace.MatchAggregates)
+	if trace.MatchName != nil {
+		n++
+	}
+	for i, t := range trace.MatchExpressions {
+		printMatchExpressionTrace(w, "matchExpressions."+t.Name, t, childIndent, i == n-1)
+	}
+	if trace.MatchName != nil {
+		printMatchExpressionTrace(w, "matchName", trace.MatchName, childIndent, len(trace.MatchAggregates) == 0)
+	}
+	for i, t := range trace.MatchAggregates {
+		printMatchExpressionTrace(w, "matchAggregates."+t.Name, t, childIndent, i == len(trace.MatchAggregates)-1)
+	}
+}
+
+func printMatchExpressionTrace(w io.Writer, name string, trace *nodefeaturerule.MatchExpressionTrace, indent string, last bool) {
+	branch, _ := treeBranch(indent, last)
+	expr := string(trace.Op)
+	if len(trace.Value) > 0 {
+		expr += " [" + strings.Join(trace.Value, ", ") + "]"
+	}
+
+	inputs := "none"
+	if len(trace.Inputs) > 0 {
+		inputs = strings.Join(trace.Inputs, ", ")
+	}
+	inputsTitle := "input"
+	if trace.Name == "" {
+		inputsTitle = "matched names"
+	}
+	fmt.Fprintf(w, "%s%s {op: %s}: %s (%s: %s)\n", branch, name, expr, verdict(trace.IsMatch, trace.Error), inputsTitle, inputs)
+}
+
+// treeBranch returns the prefix of the current node and the indentation of
+// its children.
+func treeBranch(indent string, last bool) (string, string) {
+	if last {
+		return indent + "└─ ", indent + "   "
+	}
+	return indent + "├─ ", indent + "│  "
+}
+
+func verdict(isMatch bool, err string) string {
+	switch {
+	case err != "":
+		return "ERROR: " + err
+	case isMatch:
+		return "MATCH"
+	}
+	return "NO MATCH"
+}
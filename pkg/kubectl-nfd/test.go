@@ -31,7 +31,7 @@ import (
 	"sigs.k8s.io/yaml"
 )
 
-func Test(nodefeaturerulepath, nodeName, kubeconfig string) []error {
+func Test(nodefeaturerulepath, nodeName, kubeconfig string, explain bool) []error {
 	var errs []error
 	var err error
 
@@ -73,7 +73,7 @@ func Test(nodefeaturerulepath, nodeName, kubeconfig string) []error {
 		return []error{fmt.Errorf("error parsing NodeFeatureRule: %w", err)}
 	}
 
-	errs = append(errs, processNodeFeatureRule(nfr, *features)...)
+	errs = append(errs, processNodeFeatureRule(nfr, *features, explain)...)
 
 	return errs
 }
@@ -0,0 +1,202 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdmaster
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+)
+
+// LabelFlapDetectionConfig contains the configuration of detecting labels
+// whose value changes back and forth. Flap detection is disabled if
+// MaxTransitions is zero.
+type LabelFlapDetectionConfig struct {
+	// MaxTransitions is the maximum number of changes of a label within
+	// Window. A label changing more often is considered to be flapping.
+	MaxTransitions int
+	Window         utils.DurationVal
+	// Hysteresis is the time the value of a flapping label must stay
+	// unchanged before it is applied on the node
+	Hysteresis utils.DurationVal
+}
+
+func (c *LabelFlapDetectionConfig) enabled() bool {
+	return c.MaxTransitions > 0
+}
+
+func (c *LabelFlapDetectionConfig) validate() error {
+	if c.MaxTransitions < 0 {
+		return fmt.Errorf("invalid labelFlapDetection.maxTransitions %d, must not be negative", c.MaxTransitions)
+	}
+	if c.enabled() && c.Window.Duration <= 0 {
+		return fmt.Errorf("invalid labelFlapDetection.window %v, must be greater than 0", c.Window.Duration)
+	}
+	if c.enabled() && c.Hysteresis.Duration <= 0 {
+		return fmt.Errorf("invalid labelFlapDetection.hysteresis %v, must be greater than 0", c.Hysteresis.Duration)
+	}
+	return nil
+}
+
+// labelHistory is the recent history of one label of a node.
+type labelHistory struct {
+	// value is the latest value of the label produced by nfd-master,
+	// present is false if the label was to be removed
+	value   string
+	present bool
+	// transitions holds the times the value changed within the window
+	transitions []time.Time
+	// held is true if the label is held at the value currently on the node
+	held bool
+}
+
+// labelFlapTracker tracks the recent changes of the labels of nodes, holding
+// back changes of labels that flap.
+type labelFlapTracker struct {
+	sync.Mutex
+	nodes map[string]map[string]*labelHistory
+}
+
+func newLabelFlapTracker() *labelFlapTracker {
+	return &labelFlapTracker{nodes: make(map[string]map[string]*labelHistory)}
+}
+
+// filter records the changes in the labels of a node and returns the labels
+// to be applied. Labels that have changed more than config.MaxTransitions
+// times within config.Window are held at their current value on the node
+// until their value has stayed unchanged for config.Hysteresis. The current
+// NFD-managed labels of the node are passed in nodeLabels. Returns the time
+// when the next held label is due to be released, or zero time if no labels
+// are held.
+func (t *labelFlapTracker) filter(nodeName string, nodeLabels, labels Labels, config LabelFlapDetectionConfig, now time.Time) (Labels, time.Time) {
+	t.Lock()
+	defer t.Unlock()
+
+	if !config.enabled() {
+		delete(t.nodes, nodeName)
+		return labels, time.Time{}
+	}
+
+	history, ok := t.nodes[nodeName]
+	if !ok {
+		history = make(map[string]*labelHistory)
+		t.nodes[nodeName] = history
+	}
+
+	names := make(map[string]struct{}, len(labels)+len(nodeLabels)+len(history))
+	for _, m := range []map[string]string{labels, nodeLabels} {
+		for name := range m {
+			names[name] = struct{}{}
+		}
+	}
+	for name := range history {
+		names[name] = struct{}{}
+	}
+
+	out := make(Labels, len(labels))
+	var releaseTime time.Time
+	for name := range names {
+		value, present := labels[name]
+		nodeValue, onNode := nodeLabels[name]
+
+		h, ok := history[name]
+		if !ok {
+			// Start from the value on the node
+			h = &labelHistory{value: nodeValue, present: onNode}
+			history[name] = h
+		}
+		if value != h.value || present != h.present {
+			h.value, h.present = value, present
+			h.transitions = append(h.transitions, now)
+		}
+		for len(h.transitions) > 0 && now.Sub(h.transitions[0]) >= config.Window.Duration {
+			h.transitions = h.transitions[1:]
+		}
+
+		if len(h.transitions) > config.MaxTransitions {
+			if h.transitions[len(h.transitions)-1].Equal(now) {
+				klog.InfoS("label is flapping, holding its value", "nodeName", nodeName, "labelName", name, "numTransitions", len(h.transitions))
+				labelFlaps.Inc()
+			}
+			h.held = true
+		}
+		if h.held {
+			if n := len(h.transitions); n > 0 {
+				release := h.transitions[n-1].Add(config.Hysteresis.Duration)
+				if now.Before(release) {
+					if onNode {
+						out[name] = nodeValue
+					}
+					if releaseTime.IsZero() || release.Before(releaseTime) {
+						releaseTime = release
+					}
+					continue
+				}
+			}
+			klog.V(2).InfoS("label is stable, releasing", "nodeName", nodeName, "labelName", name)
+			h.held = false
+			h.transitions = nil
+		}
+
+		if present {
+			out[name] = value
+		} else if len(h.transitions) == 0 {
+			// Nothing to track for a label that has been removed
+			delete(history, name)
+		}
+	}
+
+	return out, releaseTime
+}
+
+// holding returns true if some labels of the node are held.
+func (t *labelFlapTracker) holding(nodeName string) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	for _, h := range t.nodes[nodeName] {
+		if h.held {
+			return true
+		}
+	}
+	return false
+}
+
+// removeNode drops the data of a node, e.g. when the node has been deleted.
+func (t *labelFlapTracker) removeNode(nodeName string) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.nodes, nodeName)
+}
+
+// pruneNodes drops the data of all nodes not found in the given set of node
+// names.
+func (t *labelFlapTracker) pruneNodes(nodeNames map[string]struct{}) {
+	t.Lock()
+	defer t.Unlock()
+
+	for n := range t.nodes {
+		if _, ok := nodeNames[n]; !ok {
+			delete(t.nodes, n)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdmaster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+)
+
+func TestLabelFlapTracker(t *testing.T) {
+	config := LabelFlapDetectionConfig{
+		MaxTransitions: 2,
+		Window:         utils.DurationVal{Duration: time.Minute},
+		Hysteresis:     utils.DurationVal{Duration: 30 * time.Second},
+	}
+	t0 := time.Now()
+	tracker := newLabelFlapTracker()
+
+	// Changes within the limit are applied
+	out, release := tracker.filter("node-1", Labels{"a": "1", "b": "1"}, Labels{"a": "2", "b": "1"}, config, t0)
+	assert.Equal(t, Labels{"a": "2", "b": "1"}, out)
+	assert.True(t, release.IsZero())
+	out, _ = tracker.filter("node-1", Labels{"a": "2", "b": "1"}, Labels{"a": "1", "b": "1"}, config, t0.Add(time.Second))
+	assert.Equal(t, Labels{"a": "1", "b": "1"}, out)
+	assert.False(t, tracker.holding("node-1"))
+
+	// Label flapping more often is held at the value on the node
+	out, release = tracker.filter("node-1", Labels{"a": "1", "b": "1"}, Labels{"a": "2"}, config, t0.Add(2*time.Second))
+	assert.Equal(t, Labels{"a": "1"}, out)
+	assert.Equal(t, t0.Add(32*time.Second), release)
+	assert.True(t, tracker.holding("node-1"))
+
+	// Label is held until its value has been stable for the hysteresis period
+	out, release = tracker.filter("node-1", Labels{"a": "1"}, Labels{"a": "2"}, config, t0.Add(20*time.Second))
+	assert.Equal(t, Labels{"a": "1"}, out)
+	assert.Equal(t, t0.Add(32*time.Second), release)
+	out, release = tracker.filter("node-1", Labels{"a": "1"}, Labels{"a": "2"}, config, t0.Add(32*time.Second))
+	assert.Equal(t, Labels{"a": "2"}, out)
+	assert.True(t, release.IsZero())
+	assert.False(t, tracker.holding("node-1"))
+
+	// Removal of a flapping label is held, too
+	out = Labels{"c": "1"}
+	for i, labels := range []Labels{{}, {"c": "1"}, {}} {
+		out, _ = tracker.filter("node-2", out, labels, config, t0.Add(time.Duration(i)*time.Second))
+	}
+	assert.Equal(t, Labels{"c": "1"}, out)
+
+	// Nodes are tracked separately
+	out, _ = tracker.filter("node-3", Labels{"a": "1"}, Labels{"a": "2"}, config, t0.Add(3*time.Second))
+	assert.Equal(t, Labels{"a": "2"}, out)
+
+	tracker.removeNode("node-2")
+	assert.False(t, tracker.holding("node-2"))
+	tracker.pruneNodes(map[string]struct{}{"node-1": {}})
+	assert.Len(t, tracker.nodes, 1)
+
+	// Disabled flap detection
+	out, release = tracker.filter("node-1", Labels{"a": "1"}, Labels{"a": "2"}, LabelFlapDetectionConfig{}, t0)
+	assert.Equal(t, Labels{"a": "2"}, out)
+	assert.True(t, release.IsZero())
+}
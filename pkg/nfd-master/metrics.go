@@ -26,13 +26,23 @@ const (
 	buildInfoQuery                      = "build_info"
 	nodeUpdateRequestsQuery             = "node_update_requests_total"
 	nodeUpdatesQuery                    = "node_updates_total"
+	nodeUpdatesSkippedQuery             = "node_updates_skipped_total"
 	nodeFeatureGroupUpdateRequestsQuery = "node_feature_group_update_requests_total"
 	nodeUpdateFailuresQuery             = "node_update_failures_total"
 	nodeLabelsRejectedQuery             = "node_labels_rejected_total"
 	nodeERsRejectedQuery                = "node_extendedresources_rejected_total"
 	nodeTaintsRejectedQuery             = "node_taints_rejected_total"
+	nodeEventsDroppedQuery              = "node_events_dropped_total"
+	nodeUpdatesBlockedQuery             = "node_updates_blocked_total"
+	safeguardTrippedQuery               = "safeguard_tripped"
+	labelFlapsQuery                     = "label_flaps_total"
+	nodeFieldConflictsQuery             = "node_field_conflicts_total"
+	configReloadsQuery                  = "config_reloads_total"
+	configReloadFailuresQuery           = "config_reload_failures_total"
 	nfrProcessingTimeQuery              = "nodefeaturerule_processing_duration_seconds"
 	nfrProcessingErrorsQuery            = "nodefeaturerule_processing_errors_total"
+	nfrConflictsQuery                   = "nodefeaturerule_conflicts_total"
+	nfrShadowMatchedNodesQuery          = "nodefeaturerule_shadow_matched_nodes"
 )
 
 const (
@@ -64,6 +74,11 @@ var (
 		Name:      nodeUpdatesQuery,
 		Help:      "Number of nodes updated by the master.",
 	})
+	nodeUpdatesSkipped = prometheus.NewCounter(prometheus.CounterOpts{
+		Subsystem: nfdMasterPrefix,
+		Name:      nodeUpdatesSkippedQuery,
+		Help:      "Number of node updates skipped because the node features and rules were unchanged.",
+	})
 	nodeUpdateFailures = prometheus.NewCounter(prometheus.CounterOpts{
 		Subsystem: nfdMasterPrefix,
 		Name:      nodeUpdateFailuresQuery,
@@ -84,6 +99,41 @@ var (
 		Name:      nodeTaintsRejectedQuery,
 		Help:      "Number of node taints that were rejected by nfd-master.",
 	})
+	nodeEventsDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Subsystem: nfdMasterPrefix,
+		Name:      nodeEventsDroppedQuery,
+		Help:      "Number of node change events dropped because of rate limiting.",
+	})
+	nodeUpdatesBlocked = prometheus.NewCounter(prometheus.CounterOpts{
+		Subsystem: nfdMasterPrefix,
+		Name:      nodeUpdatesBlockedQuery,
+		Help:      "Number of node updates blocked because node updates were paused by the safeguard.",
+	})
+	safeguardTripped = prometheus.NewGauge(prometheus.GaugeOpts{
+		Subsystem: nfdMasterPrefix,
+		Name:      safeguardTrippedQuery,
+		Help:      "Whether node updates are paused because the safeguard limit was exceeded (1) or not (0).",
+	})
+	labelFlaps = prometheus.NewCounter(prometheus.CounterOpts{
+		Subsystem: nfdMasterPrefix,
+		Name:      labelFlapsQuery,
+		Help:      "Number of changes of node labels detected as flapping.",
+	})
+	nodeFieldConflicts = prometheus.NewCounter(prometheus.CounterOpts{
+		Subsystem: nfdMasterPrefix,
+		Name:      nodeFieldConflictsQuery,
+		Help:      "Number of node labels, annotations and extended resources conflicting with other field managers in server-side apply.",
+	})
+	configReloads = prometheus.NewCounter(prometheus.CounterOpts{
+		Subsystem: nfdMasterPrefix,
+		Name:      configReloadsQuery,
+		Help:      "Number of successful reloads of the configuration.",
+	})
+	configReloadFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Subsystem: nfdMasterPrefix,
+		Name:      configReloadFailuresQuery,
+		Help:      "Number of failed reloads of the configuration.",
+	})
 	nfrProcessingTime = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Subsystem: nfdMasterPrefix,
@@ -101,6 +151,26 @@ var (
 		Name:      nfrProcessingErrorsQuery,
 		Help:      "Number of errors encountered while processing NodeFeatureRule objects.",
 	})
+	nfrConflicts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: nfdMasterPrefix,
+			Name:      nfrConflictsQuery,
+			Help:      "Number of conflicting outputs of NodeFeatureRule objects that were overridden or dropped.",
+		},
+		[]string{
+			"name",
+		},
+	)
+	nfrShadowMatchedNodes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: nfdMasterPrefix,
+			Name:      nfrShadowMatchedNodesQuery,
+			Help:      "Number of nodes matched by NodeFeatureRule objects in shadow mode.",
+		},
+		[]string{
+			"name",
+		},
+	)
 )
 
 // registerVersion exposes the Operator build version.
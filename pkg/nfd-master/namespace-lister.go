@@ -62,6 +62,21 @@ func (lister *NamespaceLister) list() ([]*corev1.Namespace, error) {
 	return lister.namespaceLister.List(lister.labelsSelector)
 }
 
+// has returns true if the given namespace is selected by the lister.
+func (lister *NamespaceLister) has(namespace string) (bool, error) {
+	namespaces, err := lister.list()
+	if err != nil {
+		return false, err
+	}
+
+	for _, ns := range namespaces {
+		if ns.Name == namespace {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // stop closes the channel used by the lister
 func (lister *NamespaceLister) stop() {
 	close(lister.stopChan)
@@ -43,20 +43,23 @@ type nfdController struct {
 
 	stopChan chan struct{}
 
-	updateAllNodesChan             chan struct{}
-	updateOneNodeChan              chan string
-	updateAllNodeFeatureGroupsChan chan struct{}
-	updateNodeFeatureGroupChan     chan string
+	updateAllNodesChan         chan struct{}
+	updateOneNodeChan          chan string
+	updateNodeFeatureGroupChan chan string
 
 	namespaceLister *NamespaceLister
+	// nodeFeatureGroupNamespaceLister lists the namespaces (in addition to the
+	// namespace of nfd-master) whose NodeFeatureGroups are processed
+	nodeFeatureGroupNamespaceLister *NamespaceLister
 }
 
 type nfdApiControllerOptions struct {
-	DisableNodeFeatureGroup      bool
-	ResyncPeriod                 time.Duration
-	K8sClient                    k8sclient.Interface
-	NodeFeatureNamespaceSelector *metav1.LabelSelector
-	ListSize                     int64
+	DisableNodeFeatureGroup           bool
+	ResyncPeriod                      time.Duration
+	K8sClient                         k8sclient.Interface
+	NodeFeatureNamespaceSelector      *metav1.LabelSelector
+	NodeFeatureGroupNamespaceSelector *metav1.LabelSelector
+	ListSize                          int64
 }
 
 func init() {
@@ -65,11 +68,10 @@ func init() {
 
 func newNfdController(config *restclient.Config, nfdApiControllerOptions nfdApiControllerOptions) (*nfdController, error) {
 	c := &nfdController{
-		stopChan:                       make(chan struct{}),
-		updateAllNodesChan:             make(chan struct{}, 1),
-		updateOneNodeChan:              make(chan string),
-		updateAllNodeFeatureGroupsChan: make(chan struct{}, 1),
-		updateNodeFeatureGroupChan:     make(chan string),
+		stopChan:                   make(chan struct{}),
+		updateAllNodesChan:         make(chan struct{}, 1),
+		updateOneNodeChan:          make(chan string),
+		updateNodeFeatureGroupChan: make(chan string),
 	}
 
 	if nfdApiControllerOptions.NodeFeatureNamespaceSelector != nil {
@@ -86,6 +88,19 @@ func newNfdController(config *restclient.Config, nfdApiControllerOptions nfdApiC
 
 	}
 
+	if !nfdApiControllerOptions.DisableNodeFeatureGroup && nfdApiControllerOptions.NodeFeatureGroupNamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(nfdApiControllerOptions.NodeFeatureGroupNamespaceSelector)
+		if err != nil {
+			klog.ErrorS(err, "failed to convert label selector to map", "selector", nfdApiControllerOptions.NodeFeatureGroupNamespaceSelector)
+			return nil, err
+		}
+		c.nodeFeatureGroupNamespaceLister, err = newNamespaceLister(nfdApiControllerOptions.K8sClient, selector)
+		if err != nil {
+			klog.ErrorS(err, "couldn't create namespace lister for NodeFeatureGroups")
+			return nil, err
+		}
+	}
+
 	nfdClient := nfdclientset.NewForConfigOrDie(config)
 	klog.V(2).InfoS("initializing new NFD API controller", "options", utils.DelayedDumper(nfdApiControllerOptions))
 
@@ -119,25 +134,16 @@ func newNfdController(config *restclient.Config, nfdApiControllerOptions nfdApiC
 			} else {
 				klog.V(2).InfoS("NodeFeature namespace is not selected, skipping", "nodefeature", klog.KObj(nfr))
 			}
-			if !nfdApiControllerOptions.DisableNodeFeatureGroup {
-				c.updateAllNodeFeatureGroups()
-			}
 		},
 		UpdateFunc: func(oldObj, newObj interface{}) {
 			nfr := newObj.(*nfdv1alpha1.NodeFeature)
 			klog.V(2).InfoS("NodeFeature updated", "nodefeature", klog.KObj(nfr))
 			c.updateOneNode("NodeFeature", nfr)
-			if !nfdApiControllerOptions.DisableNodeFeatureGroup {
-				c.updateAllNodeFeatureGroups()
-			}
 		},
 		DeleteFunc: func(obj interface{}) {
 			nfr := obj.(*nfdv1alpha1.NodeFeature)
 			klog.V(2).InfoS("NodeFeature deleted", "nodefeature", klog.KObj(nfr))
 			c.updateOneNode("NodeFeature", nfr)
-			if !nfdApiControllerOptions.DisableNodeFeatureGroup {
-				c.updateAllNodeFeatureGroups()
-			}
 		},
 	}); err != nil {
 		return nil, err
@@ -152,7 +158,14 @@ func newNfdController(config *restclient.Config, nfdApiControllerOptions nfdApiC
 			c.updateAllNodes()
 		},
 		UpdateFunc: func(oldObject, newObject interface{}) {
-			klog.V(2).InfoS("NodeFeatureRule updated", "nodefeaturerule", klog.KObj(newObject.(metav1.Object)))
+			oldNfr := oldObject.(*nfdv1alpha1.NodeFeatureRule)
+			nfr := newObject.(*nfdv1alpha1.NodeFeatureRule)
+			// Status updates do not affect the nodes
+			if oldNfr.Generation == nfr.Generation {
+				klog.V(4).InfoS("NodeFeatureRule spec not changed, skipping node updates", "nodefeaturerule", klog.KObj(nfr))
+				return
+			}
+			klog.V(2).InfoS("NodeFeatureRule updated", "nodefeaturerule", klog.KObj(nfr))
 			c.updateAllNodes()
 		},
 		DeleteFunc: func(object interface{}) {
@@ -171,17 +184,29 @@ func newNfdController(config *restclient.Config, nfdApiControllerOptions nfdApiC
 			AddFunc: func(obj interface{}) {
 				nfg := obj.(*nfdv1alpha1.NodeFeatureGroup)
 				klog.V(2).InfoS("NodeFeatureGroup added", "nodeFeatureGroup", klog.KObj(nfg))
-				c.updateNodeFeatureGroup(nfg.Name)
+				c.updateNodeFeatureGroup(cache.MetaObjectToName(nfg).String())
+				if nodeFeatureGroupHasOutput(nfg) {
+					c.updateAllNodes()
+				}
 			},
 			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldNfg := oldObj.(*nfdv1alpha1.NodeFeatureGroup)
 				nfg := newObj.(*nfdv1alpha1.NodeFeatureGroup)
 				klog.V(2).InfoS("NodeFeatureGroup updated", "nodeFeatureGroup", klog.KObj(nfg))
-				c.updateNodeFeatureGroup(nfg.Name)
+				c.updateNodeFeatureGroup(cache.MetaObjectToName(nfg).String())
+				// Node labels and taints only need to be updated if the spec
+				// of the group changed
+				if oldNfg.Generation != nfg.Generation && (nodeFeatureGroupHasOutput(oldNfg) || nodeFeatureGroupHasOutput(nfg)) {
+					c.updateAllNodes()
+				}
 			},
 			DeleteFunc: func(obj interface{}) {
 				nfg := obj.(*nfdv1alpha1.NodeFeatureGroup)
 				klog.V(2).InfoS("NodeFeatureGroup deleted", "nodeFeatureGroup", klog.KObj(nfg))
-				c.updateNodeFeatureGroup(nfg.Name)
+				c.updateNodeFeatureGroup(cache.MetaObjectToName(nfg).String())
+				if nodeFeatureGroupHasOutput(nfg) {
+					c.updateAllNodes()
+				}
 			},
 		}); err != nil {
 			return nil, err
@@ -207,6 +232,9 @@ func newNfdController(config *restclient.Config, nfdApiControllerOptions nfdApiC
 func (c *nfdController) stop() {
 	close(c.stopChan)
 	c.namespaceLister.stop()
+	if c.nodeFeatureGroupNamespaceLister != nil {
+		c.nodeFeatureGroupNamespaceLister.stop()
+	}
 }
 
 func getNodeNameForObj(obj metav1.Object) (string, error) {
@@ -239,19 +267,28 @@ func (c *nfdController) isNamespaceSelected(namespace string) bool {
 		return true
 	}
 
-	namespaces, err := c.namespaceLister.list()
+	selected, err := c.namespaceLister.has(namespace)
 	if err != nil {
 		klog.ErrorS(err, "failed to query namespaces by the namespace lister")
 		return false
 	}
+	return selected
+}
 
-	for _, ns := range namespaces {
-		if ns.Name == namespace {
-			return true
-		}
+// isNodeFeatureGroupNamespaceSelected returns true if NodeFeatureGroups in
+// the given namespace are selected by the NodeFeatureGroup namespace
+// selector. No namespaces are selected if the selector is not specified.
+func (c *nfdController) isNodeFeatureGroupNamespaceSelected(namespace string) bool {
+	if c.nodeFeatureGroupNamespaceLister == nil {
+		return false
 	}
 
-	return false
+	selected, err := c.nodeFeatureGroupNamespaceLister.has(namespace)
+	if err != nil {
+		klog.ErrorS(err, "failed to query namespaces by the NodeFeatureGroup namespace lister")
+		return false
+	}
+	return selected
 }
 
 func (c *nfdController) updateAllNodes() {
@@ -261,16 +298,15 @@ func (c *nfdController) updateAllNodes() {
 	}
 }
 
+// nodeFeatureGroupHasOutput returns true if the NodeFeatureGroup creates
+// labels or taints on its member nodes.
+func nodeFeatureGroupHasOutput(nfg *nfdv1alpha1.NodeFeatureGroup) bool {
+	return len(nfg.Spec.Labels) > 0 || len(nfg.Spec.Taints) > 0
+}
+
 func (c *nfdController) updateNodeFeatureGroup(nodeFeatureGroup string) {
 	select {
 	case c.updateNodeFeatureGroupChan <- nodeFeatureGroup:
 	case <-c.stopChan:
 	}
 }
-
-func (c *nfdController) updateAllNodeFeatureGroups() {
-	select {
-	case c.updateAllNodeFeatureGroupsChan <- struct{}{}:
-	default:
-	}
-}
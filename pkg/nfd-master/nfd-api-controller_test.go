@@ -111,3 +111,24 @@ func TestIsNamespaceSelected(t *testing.T) {
 		assert.Equal(t, res, tc.expectedResult)
 	}
 }
+
+func TestIsNodeFeatureGroupNamespaceSelected(t *testing.T) {
+	fakeCli := fakeclient.NewSimpleClientset(newTestNamespace("tenant-a"), newTestNamespace("tenant-b"))
+
+	c := &nfdController{}
+	m := &nfdMaster{namespace: "nfd", nfdController: c}
+
+	// Without a selector only the namespace of nfd-master is selected
+	assert.True(t, m.isNodeFeatureGroupNamespaceSelected("nfd"))
+	assert.False(t, m.isNodeFeatureGroupNamespaceSelected("tenant-a"))
+
+	labelMap, _ := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{MatchLabels: map[string]string{"name": "tenant-a"}})
+	lister, err := newNamespaceLister(fakeCli, labelMap)
+	assert.Nil(t, err)
+	c.nodeFeatureGroupNamespaceLister = lister
+	defer lister.stop()
+
+	assert.True(t, m.isNodeFeatureGroupNamespaceSelected("nfd"))
+	assert.True(t, m.isNodeFeatureGroupNamespaceSelected("tenant-a"))
+	assert.False(t, m.isNodeFeatureGroupNamespaceSelected("tenant-b"))
+}
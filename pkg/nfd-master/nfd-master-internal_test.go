@@ -28,8 +28,10 @@ import (
 
 	. "github.com/smartystreets/goconvey/convey"
 	"golang.org/x/net/context"
+	"golang.org/x/time/rate"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -39,11 +41,13 @@ import (
 	fakecorev1client "k8s.io/client-go/kubernetes/typed/core/v1/fake"
 	clienttesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 
 	nfdclientset "sigs.k8s.io/node-feature-discovery/api/generated/clientset/versioned"
 	fakenfdclient "sigs.k8s.io/node-feature-discovery/api/generated/clientset/versioned/fake"
 	nfdscheme "sigs.k8s.io/node-feature-discovery/api/generated/clientset/versioned/scheme"
 	nfdinformers "sigs.k8s.io/node-feature-discovery/api/generated/informers/externalversions"
+	nfdlisters "sigs.k8s.io/node-feature-discovery/api/generated/listers/nfd/v1alpha1"
 	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
 	"sigs.k8s.io/node-feature-discovery/pkg/features"
 	"sigs.k8s.io/node-feature-discovery/pkg/utils"
@@ -106,7 +110,7 @@ func withNodeName(nodeName string) NfdMasterOption {
 }
 
 func withConfig(config *NFDConfig) NfdMasterOption {
-	return &nfdMasterOpt{f: func(n *nfdMaster) { n.config = config }}
+	return &nfdMasterOpt{f: func(n *nfdMaster) { n.activeConfig.Store(&runtimeConfig{NFDConfig: config}) }}
 }
 
 // withNFDClient forces to use the given client for the NFD API, without
@@ -205,7 +209,7 @@ func TestUpdateNodeObject(t *testing.T) {
 		fakeMaster := newFakeMaster(WithKubernetesClient(fakeCli))
 
 		Convey("When I successfully update the node with feature labels", func() {
-			err := fakeMaster.updateNodeObject(fakeCli, testNode, featureLabels, featureAnnotations, featureExtResources, nil)
+			err := fakeMaster.updateNodeObject(fakeCli, testNode, featureLabels, featureAnnotations, featureExtResources, nil, nil)
 			Convey("Error is nil", func() {
 				So(err, ShouldBeNil)
 			})
@@ -237,7 +241,7 @@ func TestUpdateNodeObject(t *testing.T) {
 			fakeCli.CoreV1().(*fakecorev1client.FakeCoreV1).PrependReactor("patch", "nodes", func(action clienttesting.Action) (handled bool, ret runtime.Object, err error) {
 				return true, &corev1.Node{}, errors.New("Fake error when patching node")
 			})
-			err := fakeMaster.updateNodeObject(fakeCli, testNode, nil, featureAnnotations, ExtendedResources{"": ""}, nil)
+			err := fakeMaster.updateNodeObject(fakeCli, testNode, nil, featureAnnotations, ExtendedResources{"": ""}, nil, nil)
 
 			Convey("Error is produced", func() {
 				So(err, ShouldBeError)
@@ -372,8 +376,8 @@ func TestRemovingExtResources(t *testing.T) {
 
 func TestFilterLabels(t *testing.T) {
 	fakeMaster := newFakeMaster()
-	fakeMaster.config.ExtraLabelNs = map[string]struct{}{"example.io": {}}
-	fakeMaster.deniedNs = deniedNs{
+	fakeMaster.config().ExtraLabelNs = map[string]struct{}{"example.io": {}}
+	fakeMaster.config().deniedNs = deniedNs{
 		normal:   map[string]struct{}{"": {}, "kubernetes.io": {}, "denied.ns": {}},
 		wildcard: map[string]struct{}{".kubernetes.io": {}, ".denied.subns": {}},
 	}
@@ -621,11 +625,11 @@ func TestConfigParse(t *testing.T) {
 		Convey("and no core cmdline flags have been specified", func() {
 			So(master.configure("non-existing-file", overrides), ShouldBeNil)
 			Convey("overrides should be in effect", func() {
-				So(master.config.NoPublish, ShouldResemble, true)
-				So(master.config.EnableTaints, ShouldResemble, true)
-				So(master.config.ExtraLabelNs, ShouldResemble, utils.StringSetVal{"added.ns.io": struct{}{}, "added.kubernetes.io": struct{}{}})
-				So(master.config.DenyLabelNs, ShouldResemble, utils.StringSetVal{"denied.ns.io": struct{}{}, "denied.kubernetes.io": struct{}{}})
-				So(master.config.LabelWhiteList.String(), ShouldEqual, "foo")
+				So(master.config().NoPublish, ShouldResemble, true)
+				So(master.config().EnableTaints, ShouldResemble, true)
+				So(master.config().ExtraLabelNs, ShouldResemble, utils.StringSetVal{"added.ns.io": struct{}{}, "added.kubernetes.io": struct{}{}})
+				So(master.config().DenyLabelNs, ShouldResemble, utils.StringSetVal{"denied.ns.io": struct{}{}, "denied.kubernetes.io": struct{}{}})
+				So(master.config().LabelWhiteList.String(), ShouldEqual, "foo")
 			})
 		})
 		Convey("and a non-accessible file, but cmdline flags and some overrides are specified", func() {
@@ -635,12 +639,12 @@ func TestConfigParse(t *testing.T) {
 			So(master.configure("non-existing-file", overrides), ShouldBeNil)
 
 			Convey("cmdline flags should be in effect instead overrides", func() {
-				So(master.config.ExtraLabelNs, ShouldResemble, utils.StringSetVal{"override.added.ns.io": struct{}{}})
-				So(master.config.DenyLabelNs, ShouldResemble, utils.StringSetVal{"override.denied.ns.io": struct{}{}})
+				So(master.config().ExtraLabelNs, ShouldResemble, utils.StringSetVal{"override.added.ns.io": struct{}{}})
+				So(master.config().DenyLabelNs, ShouldResemble, utils.StringSetVal{"override.denied.ns.io": struct{}{}})
 			})
 			Convey("overrides should take effect", func() {
-				So(master.config.NoPublish, ShouldBeTrue)
-				So(master.config.EnableTaints, ShouldBeTrue)
+				So(master.config().NoPublish, ShouldBeTrue)
+				So(master.config().EnableTaints, ShouldBeTrue)
 			})
 		})
 		// Create a temporary config file
@@ -656,6 +660,10 @@ leaderElection:
   leaseDuration: 20s
   renewDeadline: 4s
   retryPeriod: 30s
+nodeUpdates:
+  qps: 20
+  burst: 5
+  coalescePeriod: 2s
 `)
 		f.Close()
 		So(err, ShouldBeNil)
@@ -665,14 +673,17 @@ leaderElection:
 			So(master.configure(f.Name(), ""), ShouldBeNil)
 			Convey("specified configuration should take effect", func() {
 				// Verify core config
-				So(master.config.NoPublish, ShouldBeTrue)
-				So(master.config.EnableTaints, ShouldBeFalse)
-				So(master.config.ExtraLabelNs, ShouldResemble, utils.StringSetVal{"override.added.ns.io": struct{}{}})
-				So(master.config.DenyLabelNs, ShouldResemble, utils.StringSetVal{"denied.ns.io": struct{}{}, "denied.kubernetes.io": struct{}{}})
-				So(master.config.LabelWhiteList.String(), ShouldEqual, "foo")
-				So(master.config.LeaderElection.LeaseDuration.Seconds(), ShouldEqual, float64(20))
-				So(master.config.LeaderElection.RenewDeadline.Seconds(), ShouldEqual, float64(4))
-				So(master.config.LeaderElection.RetryPeriod.Seconds(), ShouldEqual, float64(30))
+				So(master.config().NoPublish, ShouldBeTrue)
+				So(master.config().EnableTaints, ShouldBeFalse)
+				So(master.config().ExtraLabelNs, ShouldResemble, utils.StringSetVal{"override.added.ns.io": struct{}{}})
+				So(master.config().DenyLabelNs, ShouldResemble, utils.StringSetVal{"denied.ns.io": struct{}{}, "denied.kubernetes.io": struct{}{}})
+				So(master.config().LabelWhiteList.String(), ShouldEqual, "foo")
+				So(master.config().LeaderElection.LeaseDuration.Seconds(), ShouldEqual, float64(20))
+				So(master.config().LeaderElection.RenewDeadline.Seconds(), ShouldEqual, float64(4))
+				So(master.config().LeaderElection.RetryPeriod.Seconds(), ShouldEqual, float64(30))
+				So(master.config().NodeUpdates.CoalescePeriod.Seconds(), ShouldEqual, float64(2))
+				So(master.nodeUpdateLimiter.Limit(), ShouldEqual, rate.Limit(20))
+				So(master.nodeUpdateLimiter.Burst(), ShouldEqual, 5)
 			})
 		})
 
@@ -683,13 +694,45 @@ leaderElection:
 
 			Convey("overrides should take precedence over the config file", func() {
 				// Verify core config
-				So(master.config.ExtraLabelNs, ShouldResemble, utils.StringSetVal{"added.ns.io": struct{}{}}) // from overrides
-				So(master.config.DenyLabelNs, ShouldResemble, utils.StringSetVal{"denied.ns.io": struct{}{}}) // from cmdline
+				So(master.config().ExtraLabelNs, ShouldResemble, utils.StringSetVal{"added.ns.io": struct{}{}}) // from overrides
+				So(master.config().DenyLabelNs, ShouldResemble, utils.StringSetVal{"denied.ns.io": struct{}{}}) // from cmdline
 			})
 		})
 	})
 }
 
+func TestReloadConfig(t *testing.T) {
+	Convey("When reloading configuration", t, func() {
+		master := newFakeMaster()
+		f, err := os.CreateTemp("", "nfd-test-")
+		So(err, ShouldBeNil)
+		defer os.Remove(f.Name())
+		f.Close()
+		master.configFilePath = f.Name()
+
+		So(os.WriteFile(f.Name(), []byte("noPublish: false\nnfdApiParallelism: 5\n"), 0644), ShouldBeNil)
+		So(master.configure(master.configFilePath, ""), ShouldBeNil)
+		So(master.config().NfdApiParallelism, ShouldEqual, 5)
+
+		// Valid config is applied, except options only used at startup
+		So(os.WriteFile(f.Name(), []byte("noPublish: true\nnfdApiParallelism: 20\nnodeUpdates: {qps: 5, burst: 2}\n"), 0644), ShouldBeNil)
+		So(master.reloadConfig(), ShouldBeNil)
+		So(master.config().NoPublish, ShouldBeTrue)
+		So(master.config().NfdApiParallelism, ShouldEqual, 5)
+		So(master.nodeUpdateLimiter.Limit(), ShouldEqual, rate.Limit(5))
+
+		// Invalid config is rejected and the current config kept
+		So(os.WriteFile(f.Name(), []byte("noPublish: false\nruleConflictPolicy: foo\n"), 0644), ShouldBeNil)
+		So(master.reloadConfig(), ShouldNotBeNil)
+		So(master.config().NoPublish, ShouldBeTrue)
+		So(master.nodeUpdateLimiter.Limit(), ShouldEqual, rate.Limit(5))
+
+		So(os.WriteFile(f.Name(), []byte("noPublish: [\n"), 0644), ShouldBeNil)
+		So(master.reloadConfig(), ShouldNotBeNil)
+		So(master.config().NoPublish, ShouldBeTrue)
+	})
+}
+
 func newTestNodeList() *corev1.NodeList {
 	l := corev1.NodeList{}
 
@@ -831,3 +874,684 @@ func TestGetDynamicValue(t *testing.T) {
 		})
 	}
 }
+
+func TestNodeFeatureRuleStatus(t *testing.T) {
+	Convey("When updating NodeFeatureRule status", t, func() {
+		nfr := &nfdv1alpha1.NodeFeatureRule{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-rule", Generation: 2},
+			Spec: nfdv1alpha1.NodeFeatureRuleSpec{
+				Rules: []nfdv1alpha1.Rule{
+					{
+						Name:   "rule-1",
+						Labels: map[string]string{"feature-1": "true"},
+					},
+					{
+						Name:          "rule-2",
+						Labels:        map[string]string{"feature-2": "true"},
+						MatchFeatures: nfdv1alpha1.FeatureMatcher{{Feature: "fake.attribute"}},
+					},
+				},
+			},
+		}
+		fakeCli := fakenfdclient.NewSimpleClientset(nfr)
+		fakeMaster := newFakeMaster(withNFDClient(fakeCli))
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		So(indexer.Add(nfr), ShouldBeNil)
+		fakeMaster.nfdController = &nfdController{ruleLister: nfdlisters.NewNodeFeatureRuleLister(indexer)}
+
+		Convey("Status should not be updated before any results are recorded", func() {
+			So(fakeMaster.updateNodeFeatureRuleStatuses(fakeCli), ShouldBeNil)
+			updated, err := fakeCli.NfdV1alpha1().NodeFeatureRules().Get(context.TODO(), nfr.Name, metav1.GetOptions{})
+			So(err, ShouldBeNil)
+			So(updated.Status.Conditions, ShouldBeEmpty)
+		})
+
+		Convey("Status should reflect the recorded results", func() {
+			errTime := time.Now().Truncate(time.Second)
+			fakeMaster.nfrStatus.record(nfr, "node-1", map[string]ruleResult{
+				"rule-1": {matched: true},
+				"rule-2": {matched: true},
+			})
+			fakeMaster.nfrStatus.record(nfr, "node-2", map[string]ruleResult{
+				"rule-1": {matched: true},
+				"rule-2": {err: "fake error", errTime: errTime},
+			})
+			fakeMaster.nfrStatus.record(nfr, "node-3", map[string]ruleResult{
+				"rule-1": {matched: false},
+				"rule-2": {matched: false},
+			})
+			So(fakeMaster.nfrStatus.takeDirty(), ShouldBeTrue)
+			So(fakeMaster.updateNodeFeatureRuleStatuses(fakeCli), ShouldBeNil)
+
+			updated, err := fakeCli.NfdV1alpha1().NodeFeatureRules().Get(context.TODO(), nfr.Name, metav1.GetOptions{})
+			So(err, ShouldBeNil)
+			So(updated.Status.MatchedNodes, ShouldEqual, 2)
+			So(updated.Status.UnmatchedNodes, ShouldEqual, 1)
+			So(updated.Status.Rules, ShouldResemble, []nfdv1alpha1.RuleStatus{
+				{Name: "rule-1", MatchedNodes: 2, UnmatchedNodes: 1},
+				{Name: "rule-2", MatchedNodes: 1, UnmatchedNodes: 2, LastError: &nfdv1alpha1.RuleError{
+					Message:  "fake error",
+					NodeName: "node-2",
+					Time:     metav1.NewTime(errTime),
+				}},
+			})
+			valid := meta.FindStatusCondition(updated.Status.Conditions, nfdv1alpha1.NodeFeatureRuleConditionValid)
+			So(valid, ShouldNotBeNil)
+			So(valid.Status, ShouldEqual, metav1.ConditionFalse)
+			So(valid.ObservedGeneration, ShouldEqual, 2)
+			applied := meta.FindStatusCondition(updated.Status.Conditions, nfdv1alpha1.NodeFeatureRuleConditionApplied)
+			So(applied, ShouldNotBeNil)
+			So(applied.Status, ShouldEqual, metav1.ConditionTrue)
+
+			Convey("Results of deleted nodes should be dropped", func() {
+				fakeMaster.nfrStatus.removeNode("node-2")
+				fakeMaster.nfrStatus.pruneNodes(map[string]struct{}{"node-1": {}})
+				status, ok := fakeMaster.nfrStatus.status(nfr)
+				So(ok, ShouldBeTrue)
+				So(status.MatchedNodes, ShouldEqual, 1)
+				So(status.UnmatchedNodes, ShouldEqual, 0)
+				So(meta.IsStatusConditionTrue(status.Conditions, nfdv1alpha1.NodeFeatureRuleConditionValid), ShouldBeTrue)
+			})
+		})
+
+		Convey("Results of an outdated generation should not be reported", func() {
+			fakeMaster.nfrStatus.record(nfr, "node-1", map[string]ruleResult{"rule-1": {matched: true}})
+			newNfr := nfr.DeepCopy()
+			newNfr.Generation++
+			_, ok := fakeMaster.nfrStatus.status(newNfr)
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
+
+func TestProcessNodeFeatureRuleNodeSelector(t *testing.T) {
+	Convey("When processing NodeFeatureRules with a nodeSelector", t, func() {
+		newNfr := func(name string, nodeSelector *metav1.LabelSelector) *nfdv1alpha1.NodeFeatureRule {
+			return &nfdv1alpha1.NodeFeatureRule{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec: nfdv1alpha1.NodeFeatureRuleSpec{
+					NodeSelector: nodeSelector,
+					Rules: []nfdv1alpha1.Rule{
+						{Name: "rule", Labels: map[string]string{"example.com/" + name: "true"}},
+					},
+				},
+			}
+		}
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		So(indexer.Add(newNfr("all-nodes", nil)), ShouldBeNil)
+		So(indexer.Add(newNfr("gpu-nodes", &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "gpu"}})), ShouldBeNil)
+		So(indexer.Add(newNfr("invalid-selector", &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "pool", Operator: "Foo"}},
+		})), ShouldBeNil)
+
+		fakeMaster := newFakeMaster()
+		fakeMaster.nfdController = &nfdController{ruleLister: nfdlisters.NewNodeFeatureRuleLister(indexer)}
+		node := newTestNode()
+
+		Convey("Rules should only be applied on selected nodes", func() {
+			labels, _, _, _, _ := fakeMaster.processNodeFeatureRule(node, nfdv1alpha1.NewFeatures())
+			So(labels, ShouldResemble, Labels{"example.com/all-nodes": "true"})
+
+			node.Labels["pool"] = "gpu"
+			labels, _, _, _, _ = fakeMaster.processNodeFeatureRule(node, nfdv1alpha1.NewFeatures())
+			So(labels, ShouldResemble, Labels{
+				"example.com/all-nodes": "true",
+				"example.com/gpu-nodes": "true",
+			})
+		})
+	})
+}
+
+func TestProcessNodeFeatureRuleShadow(t *testing.T) {
+	Convey("When processing NodeFeatureRules in shadow mode", t, func() {
+		shadowNfr := &nfdv1alpha1.NodeFeatureRule{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-shadow", Generation: 1},
+			Spec: nfdv1alpha1.NodeFeatureRuleSpec{
+				Shadow: true,
+				Rules: []nfdv1alpha1.Rule{
+					{
+						Name:   "rule-1",
+						Labels: map[string]string{"example.com/shadow": "true"},
+						Taints: []corev1.Taint{
+							{Key: "example.com/shadow", Value: "true", Effect: corev1.TaintEffectNoSchedule},
+							{Key: "example.com/shadow", Value: "true", Effect: corev1.TaintEffectNoExecute},
+						},
+					},
+				},
+			},
+		}
+		nfr := &nfdv1alpha1.NodeFeatureRule{
+			ObjectMeta: metav1.ObjectMeta{Name: "b-normal"},
+			Spec: nfdv1alpha1.NodeFeatureRuleSpec{
+				Rules: []nfdv1alpha1.Rule{
+					{
+						Name:   "rule-1",
+						Labels: map[string]string{"example.com/normal": "true"},
+						MatchFeatures: nfdv1alpha1.FeatureMatcher{
+							{
+								Feature: nfdv1alpha1.RuleBackrefDomain + "." + nfdv1alpha1.RuleBackrefFeature,
+								MatchExpressions: &nfdv1alpha1.MatchExpressionSet{
+									"example.com/shadow": &nfdv1alpha1.MatchExpression{Op: nfdv1alpha1.MatchDoesNotExist},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		So(indexer.Add(shadowNfr), ShouldBeNil)
+		So(indexer.Add(nfr), ShouldBeNil)
+
+		fakeMaster := newFakeMaster(withConfig(&NFDConfig{EnableTaints: true}))
+		fakeMaster.nfdController = &nfdController{ruleLister: nfdlisters.NewNodeFeatureRuleLister(indexer)}
+
+		Convey("Output of the shadow rules should not be applied", func() {
+			features := nfdv1alpha1.NewFeatures()
+			features.InsertAttributeFeatures(nfdv1alpha1.RuleBackrefDomain, nfdv1alpha1.RuleBackrefFeature, map[string]string{"example.com/other": "true"})
+			labels, _, _, taints, _ := fakeMaster.processNodeFeatureRule(newTestNode(), features)
+			So(labels, ShouldResemble, Labels{"example.com/normal": "true"})
+			So(taints, ShouldBeEmpty)
+		})
+
+		Convey("Output of the shadow rules should be reported in the status", func() {
+			for _, n := range []string{"node-1", "node-2"} {
+				node := newTestNode()
+				node.Name = n
+				fakeMaster.processNodeFeatureRule(node, nfdv1alpha1.NewFeatures())
+			}
+			status, ok := fakeMaster.nfrStatus.status(shadowNfr)
+			So(ok, ShouldBeTrue)
+			So(status.MatchedNodes, ShouldEqual, 2)
+			So(status.Rules[0].ShadowOutput, ShouldResemble, &nfdv1alpha1.RuleShadowOutput{
+				Labels: []nfdv1alpha1.ShadowOutputItem{{Name: "example.com/shadow", Value: "true", Nodes: 2}},
+				Taints: []nfdv1alpha1.ShadowOutputItem{
+					{Name: "example.com/shadow:NoExecute", Value: "true", Nodes: 2},
+					{Name: "example.com/shadow:NoSchedule", Value: "true", Nodes: 2},
+				},
+			})
+			cond := meta.FindStatusCondition(status.Conditions, nfdv1alpha1.NodeFeatureRuleConditionApplied)
+			So(cond.Status, ShouldEqual, metav1.ConditionFalse)
+			So(cond.Reason, ShouldEqual, "ShadowMode")
+
+			status, ok = fakeMaster.nfrStatus.status(nfr)
+			So(ok, ShouldBeTrue)
+			So(status.Rules[0].ShadowOutput, ShouldBeNil)
+		})
+	})
+}
+
+func TestProcessNodeFeatureRuleConflictError(t *testing.T) {
+	Convey("When NodeFeatureRules conflict with the error conflict policy", t, func() {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		var nfrs []*nfdv1alpha1.NodeFeatureRule
+		for _, name := range []string{"a", "b", "c"} {
+			nfr := &nfdv1alpha1.NodeFeatureRule{
+				ObjectMeta: metav1.ObjectMeta{Name: "nfr-" + name, Generation: 1},
+				Spec: nfdv1alpha1.NodeFeatureRuleSpec{
+					Rules: []nfdv1alpha1.Rule{
+						{
+							Name:   "rule-1",
+							Labels: map[string]string{"example.com/conflict": name, "example.com/" + name: "true"},
+						},
+					},
+				},
+			}
+			So(indexer.Add(nfr), ShouldBeNil)
+			nfrs = append(nfrs, nfr)
+		}
+
+		fakeMaster := newFakeMaster(withConfig(&NFDConfig{RuleConflictPolicy: RuleConflictPolicyError}))
+		fakeMaster.nfdController = &nfdController{ruleLister: nfdlisters.NewNodeFeatureRuleLister(indexer)}
+
+		labels, _, _, _, _ := fakeMaster.processNodeFeatureRule(newTestNode(), nfdv1alpha1.NewFeatures())
+		Convey("None of the conflicting values should be applied", func() {
+			So(labels, ShouldResemble, Labels{"example.com/a": "true", "example.com/b": "true", "example.com/c": "true"})
+		})
+		Convey("The conflict should be reported in the status of all the rules involved", func() {
+			for _, nfr := range nfrs {
+				status, ok := fakeMaster.nfrStatus.status(nfr)
+				So(ok, ShouldBeTrue)
+				So(status.Rules[0].LastError, ShouldNotBeNil)
+				So(status.Rules[0].LastError.Message, ShouldContainSubstring, `conflicting values for label "example.com/conflict"`)
+				So(status.Rules[0].LastError.Message, ShouldContainSubstring, nfr.Name)
+			}
+		})
+	})
+}
+
+func TestNodeFeatureGroupMembership(t *testing.T) {
+	Convey("When evaluating NodeFeatureGroups", t, func() {
+		newNodeFeature := func(nodeName, module, resourceVersion string) *nfdv1alpha1.NodeFeature {
+			nf := nfdv1alpha1.NewNodeFeatureSpec()
+			nf.Features.Flags["kernel.loadedmodule"] = nfdv1alpha1.NewFlagFeatures(module)
+			return &nfdv1alpha1.NodeFeature{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            nodeName,
+					Namespace:       "nfd",
+					ResourceVersion: resourceVersion,
+					Labels:          map[string]string{nfdv1alpha1.NodeFeatureObjNodeNameLabel: nodeName},
+				},
+				Spec: *nf,
+			}
+		}
+		nfg := &nfdv1alpha1.NodeFeatureGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "nfg-1", Namespace: "nfd", Generation: 1},
+			Spec: nfdv1alpha1.NodeFeatureGroupSpec{
+				Rules: []nfdv1alpha1.GroupRule{
+					{
+						Name: "rule-1",
+						MatchFeatures: nfdv1alpha1.FeatureMatcher{
+							{
+								Feature:          "kernel.loadedmodule",
+								MatchExpressions: &nfdv1alpha1.MatchExpressionSet{"foo": &nfdv1alpha1.MatchExpression{Op: nfdv1alpha1.MatchExists}},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		nodes := []runtime.Object{}
+		for _, n := range []string{"node-1", "node-2", "node-3"} {
+			node := newTestNode()
+			node.Name = n
+			nodes = append(nodes, node)
+		}
+		featureIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		So(featureIndexer.Add(newNodeFeature("node-1", "foo", "1")), ShouldBeNil)
+		So(featureIndexer.Add(newNodeFeature("node-2", "bar", "1")), ShouldBeNil)
+		groupIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		So(groupIndexer.Add(nfg), ShouldBeNil)
+
+		nfdCli := fakenfdclient.NewSimpleClientset(nfg)
+		fakeMaster := newFakeMaster(WithKubernetesClient(fakeclient.NewSimpleClientset(nodes...)), withNFDClient(nfdCli))
+		fakeMaster.namespace = "nfd"
+		fakeMaster.nfdController = &nfdController{
+			featureLister:      nfdlisters.NewNodeFeatureLister(featureIndexer),
+			ruleLister:         nfdlisters.NewNodeFeatureRuleLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+			featureGroupLister: nfdlisters.NewNodeFeatureGroupLister(groupIndexer),
+		}
+		fakeMaster.updaterPool.nfgQueue = workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[string]())
+		defer fakeMaster.updaterPool.nfgQueue.ShutDown()
+
+		getStatus := func() nfdv1alpha1.NodeFeatureGroupStatus {
+			obj, err := nfdCli.NfdV1alpha1().NodeFeatureGroups("nfd").Get(context.TODO(), nfg.Name, metav1.GetOptions{})
+			So(err, ShouldBeNil)
+			return obj.Status
+		}
+		nodeNames := func(nodes []nfdv1alpha1.FeatureGroupNode) []string {
+			names := []string{}
+			for _, n := range nodes {
+				names = append(names, n.Name)
+			}
+			return names
+		}
+
+		Convey("The group should be evaluated against all nodes", func() {
+			So(fakeMaster.nfdAPIUpdateNodeFeatureGroup(nfdCli, nfg), ShouldBeNil)
+			status := getStatus()
+			So(nodeNames(status.Nodes), ShouldResemble, []string{"node-1"})
+			So(status.Nodes[0].MatchedRules, ShouldResemble, []string{"rule-1"})
+			So(status.Nodes[0].JoinTime.IsZero(), ShouldBeFalse)
+			So(status.NodeCount, ShouldEqual, 1)
+			So(meta.IsStatusConditionTrue(status.Conditions, nfdv1alpha1.NodeFeatureGroupConditionValid), ShouldBeTrue)
+			So(meta.IsStatusConditionTrue(status.Conditions, nfdv1alpha1.NodeFeatureGroupConditionPopulated), ShouldBeTrue)
+			So(meta.IsStatusConditionFalse(status.Conditions, nfdv1alpha1.NodeFeatureGroupConditionTruncated), ShouldBeTrue)
+
+			Convey("Membership should be updated incrementally when features of a node change", func() {
+				// Updates not changing the membership should not trigger a status update
+				node, err := fakeMaster.k8sClient.CoreV1().Nodes().Get(context.TODO(), "node-1", metav1.GetOptions{})
+				So(err, ShouldBeNil)
+				So(fakeMaster.nfdAPIUpdateOneNode(fakeMaster.k8sClient, node), ShouldBeNil)
+				So(fakeMaster.updaterPool.nfgQueue.Len(), ShouldEqual, 0)
+
+				So(featureIndexer.Update(newNodeFeature("node-2", "foo", "2")), ShouldBeNil)
+				node, err = fakeMaster.k8sClient.CoreV1().Nodes().Get(context.TODO(), "node-2", metav1.GetOptions{})
+				So(err, ShouldBeNil)
+				So(fakeMaster.nfdAPIUpdateOneNode(fakeMaster.k8sClient, node), ShouldBeNil)
+				So(fakeMaster.updaterPool.nfgQueue.Len(), ShouldEqual, 1)
+
+				updated, err := nfdCli.NfdV1alpha1().NodeFeatureGroups("nfd").Get(context.TODO(), nfg.Name, metav1.GetOptions{})
+				So(err, ShouldBeNil)
+				trackedStatus, ok := fakeMaster.nfgMembership.status(updated)
+				So(ok, ShouldBeTrue)
+				So(nodeNames(trackedStatus.Nodes), ShouldResemble, []string{"node-1", "node-2"})
+				// Join time of existing members must be retained
+				So(trackedStatus.Nodes[0].JoinTime, ShouldResemble, status.Nodes[0].JoinTime)
+
+				So(fakeMaster.nfdAPIUpdateNodeFeatureGroup(nfdCli, updated), ShouldBeNil)
+				So(getStatus().Nodes, ShouldResemble, trackedStatus.Nodes)
+				So(getStatus().NodeCount, ShouldEqual, 2)
+			})
+
+			Convey("Deleted nodes should be removed from the group", func() {
+				fakeMaster.removeNodeFromNodeFeatureGroups("node-1")
+				So(fakeMaster.updaterPool.nfgQueue.Len(), ShouldEqual, 1)
+				status, ok := fakeMaster.nfgMembership.status(nfg)
+				So(ok, ShouldBeTrue)
+				So(status.Nodes, ShouldBeEmpty)
+				So(status.NodeCount, ShouldEqual, 0)
+				So(meta.IsStatusConditionFalse(status.Conditions, nfdv1alpha1.NodeFeatureGroupConditionPopulated), ShouldBeTrue)
+			})
+
+			Convey("Join time should be retained from the status after a restart", func() {
+				joinTime := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+				updated, err := nfdCli.NfdV1alpha1().NodeFeatureGroups("nfd").Get(context.TODO(), nfg.Name, metav1.GetOptions{})
+				So(err, ShouldBeNil)
+				updated.Status.Nodes[0].JoinTime = joinTime
+				updated, err = nfdCli.NfdV1alpha1().NodeFeatureGroups("nfd").UpdateStatus(context.TODO(), updated, metav1.UpdateOptions{})
+				So(err, ShouldBeNil)
+
+				fakeMaster.nfgMembership = newNfgMembershipTracker()
+				So(fakeMaster.nfdAPIUpdateNodeFeatureGroup(nfdCli, updated), ShouldBeNil)
+				So(nodeNames(getStatus().Nodes), ShouldResemble, []string{"node-1"})
+				So(getStatus().Nodes[0].JoinTime.Equal(&joinTime), ShouldBeTrue)
+			})
+
+			Convey("The group should be re-evaluated when its spec changes", func() {
+				nfgUpdated := nfg.DeepCopy()
+				nfgUpdated.Generation = 2
+				_, ok := fakeMaster.nfgMembership.status(nfgUpdated)
+				So(ok, ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestNodeFeatureGroupStatusTruncation(t *testing.T) {
+	Convey("When a NodeFeatureGroup has more members than are listed in detail", t, func() {
+		nfg := &nfdv1alpha1.NodeFeatureGroup{ObjectMeta: metav1.ObjectMeta{Name: "nfg-1", Namespace: "nfd", Generation: 1}}
+		tracker := newNfgMembershipTracker()
+		tracker.beginEvaluation(nfg)
+		numNodes := maxNodeFeatureGroupNodeDetails + 10
+		results := make(map[string]nfgNodeResult, numNodes)
+		for i := range numNodes {
+			results[fmt.Sprintf("node-%03d", i)] = nfgNodeResult{matchedRules: []string{"rule-1"}, vars: map[string]string{"var": "val"}}
+		}
+		tracker.finishEvaluation(nfg, results)
+
+		status, ok := tracker.status(nfg)
+		So(ok, ShouldBeTrue)
+		Convey("All nodes should be listed and counted", func() {
+			So(status.NodeCount, ShouldEqual, numNodes)
+			So(status.Nodes, ShouldHaveLength, numNodes)
+			So(status.Nodes[numNodes-1].Name, ShouldEqual, fmt.Sprintf("node-%03d", numNodes-1))
+			So(status.Nodes[numNodes-1].JoinTime.IsZero(), ShouldBeFalse)
+		})
+		Convey("Details should only be listed for the first nodes", func() {
+			So(status.Nodes[maxNodeFeatureGroupNodeDetails-1].MatchedRules, ShouldResemble, []string{"rule-1"})
+			So(status.Nodes[maxNodeFeatureGroupNodeDetails-1].Vars, ShouldResemble, map[string]string{"var": "val"})
+			So(status.Nodes[maxNodeFeatureGroupNodeDetails].MatchedRules, ShouldBeEmpty)
+			So(status.Nodes[maxNodeFeatureGroupNodeDetails].Vars, ShouldBeEmpty)
+			So(meta.IsStatusConditionTrue(status.Conditions, nfdv1alpha1.NodeFeatureGroupConditionTruncated), ShouldBeTrue)
+		})
+	})
+}
+
+func TestNodeFeaturesCache(t *testing.T) {
+	Convey("When updating nodes", t, func() {
+		nf := &nfdv1alpha1.NodeFeature{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            testNodeName,
+				Namespace:       "nfd",
+				ResourceVersion: "1",
+				Labels:          map[string]string{nfdv1alpha1.NodeFeatureObjNodeNameLabel: testNodeName},
+			},
+			Spec: *nfdv1alpha1.NewNodeFeatureSpec(),
+		}
+		nf.Spec.Features.Attributes["test.feature"] = nfdv1alpha1.NewAttributeFeatures(map[string]string{"attr": "1"})
+		featureIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		So(featureIndexer.Add(nf), ShouldBeNil)
+		ruleIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+
+		node := newTestNode()
+		fakeCli := fakeclient.NewSimpleClientset(node)
+		fakeMaster := newFakeMaster(WithKubernetesClient(fakeCli))
+		fakeMaster.namespace = "nfd"
+		fakeMaster.nfdController = &nfdController{
+			featureLister: nfdlisters.NewNodeFeatureLister(featureIndexer),
+			ruleLister:    nfdlisters.NewNodeFeatureRuleLister(ruleIndexer),
+		}
+
+		So(fakeMaster.nfdAPIUpdateOneNode(fakeCli, node), ShouldBeNil)
+		cached, featuresKey, err := fakeMaster.getNodeFeatures(testNodeName)
+		So(err, ShouldBeNil)
+		evaluationKey := nodeEvaluationKey(0, featuresKey, "", "", node)
+		So(fakeMaster.nodeFeatures.isEvaluated(testNodeName, evaluationKey), ShouldBeTrue)
+		getNode := func() *corev1.Node {
+			n, err := fakeCli.CoreV1().Nodes().Get(context.TODO(), testNodeName, metav1.GetOptions{})
+			So(err, ShouldBeNil)
+			return n
+		}
+		// Patching fails if the node has no labels or annotations
+		seeded := getNode()
+		seeded.Labels = map[string]string{"example.com/unrelated": "true"}
+		seeded.Annotations = map[string]string{"example.com/unrelated": "true"}
+		_, err = fakeCli.CoreV1().Nodes().Update(context.TODO(), seeded, metav1.UpdateOptions{})
+		So(err, ShouldBeNil)
+
+		Convey("Merged features should be cached until NodeFeature objects change", func() {
+			nodeFeatures, _, err := fakeMaster.getNodeFeatures(testNodeName)
+			So(err, ShouldBeNil)
+			So(nodeFeatures, ShouldPointTo, cached)
+
+			nfUpdated := nf.DeepCopy()
+			nfUpdated.ResourceVersion = "2"
+			nfUpdated.Spec.Features.Attributes["test.feature"].Elements["attr"] = "2"
+			So(featureIndexer.Update(nfUpdated), ShouldBeNil)
+			nodeFeatures, newFeaturesKey, err := fakeMaster.getNodeFeatures(testNodeName)
+			So(err, ShouldBeNil)
+			So(nodeFeatures, ShouldNotPointTo, cached)
+			So(nodeFeatures.Spec.Features.Attributes["test.feature"].Elements["attr"], ShouldEqual, "2")
+			So(fakeMaster.nodeFeatures.isEvaluated(testNodeName, nodeEvaluationKey(0, newFeaturesKey, "", "", node)), ShouldBeFalse)
+		})
+
+		Convey("Node should be re-evaluated when NodeFeatureRules change", func() {
+			nfr := &nfdv1alpha1.NodeFeatureRule{ObjectMeta: metav1.ObjectMeta{Name: "nfr-1", ResourceVersion: "1", Generation: 1}}
+			So(ruleIndexer.Add(nfr), ShouldBeNil)
+			rulesKey := nodeFeatureRulesKey([]*nfdv1alpha1.NodeFeatureRule{nfr})
+			So(fakeMaster.nodeFeatures.isEvaluated(testNodeName, nodeEvaluationKey(0, featuresKey, rulesKey, "", node)), ShouldBeFalse)
+
+			// Status updates of the rules do not change the key
+			nfrStatusUpdated := nfr.DeepCopy()
+			nfrStatusUpdated.ResourceVersion = "2"
+			nfrStatusUpdated.Status.MatchedNodes = 1
+			So(nodeFeatureRulesKey([]*nfdv1alpha1.NodeFeatureRule{nfrStatusUpdated}), ShouldEqual, rulesKey)
+		})
+
+		Convey("Node should be re-evaluated when node labels change", func() {
+			node.Labels["foo"] = "bar"
+			So(fakeMaster.nodeFeatures.isEvaluated(testNodeName, nodeEvaluationKey(0, featuresKey, "", "", node)), ShouldBeFalse)
+		})
+
+		Convey("Node should be re-evaluated when the configuration is reloaded", func() {
+			nfUpdated := nf.DeepCopy()
+			nfUpdated.ResourceVersion = "2"
+			nfUpdated.Spec.Labels = map[string]string{"feature.node.kubernetes.io/foo": "true", "feature.node.kubernetes.io/bar": "true"}
+			So(featureIndexer.Update(nfUpdated), ShouldBeNil)
+			So(fakeMaster.nfdAPIUpdateOneNode(fakeCli, getNode()), ShouldBeNil)
+			So(fakeMaster.nfdAPIUpdateOneNode(fakeCli, getNode()), ShouldBeNil)
+			So(getNode().Labels, ShouldContainKey, "feature.node.kubernetes.io/foo")
+
+			f, err := os.CreateTemp(t.TempDir(), "nfd-test-")
+			So(err, ShouldBeNil)
+			f.Close()
+			fakeMaster.configFilePath = f.Name()
+			So(os.WriteFile(f.Name(), []byte("labelWhiteList: \"^bar$\"\n"), 0644), ShouldBeNil)
+			So(fakeMaster.reloadConfig(), ShouldBeNil)
+
+			So(fakeMaster.nfdAPIUpdateOneNode(fakeCli, getNode()), ShouldBeNil)
+			n := getNode()
+			So(n.Labels, ShouldNotContainKey, "feature.node.kubernetes.io/foo")
+			So(n.Labels, ShouldContainKey, "feature.node.kubernetes.io/bar")
+		})
+
+		Convey("Managed taints removed from the node should be restored on resync", func() {
+			fakeMaster.config().EnableTaints = true
+			taint := corev1.Taint{Key: "example.com/taint", Value: "true", Effect: corev1.TaintEffectNoSchedule}
+			nfr := &nfdv1alpha1.NodeFeatureRule{
+				ObjectMeta: metav1.ObjectMeta{Name: "nfr-taint", Generation: 1},
+				Spec: nfdv1alpha1.NodeFeatureRuleSpec{
+					Rules: []nfdv1alpha1.Rule{
+						{
+							Name:   "taint-rule",
+							Taints: []corev1.Taint{taint},
+							MatchFeatures: nfdv1alpha1.FeatureMatcher{
+								{
+									Feature:          "test.feature",
+									MatchExpressions: &nfdv1alpha1.MatchExpressionSet{"attr": &nfdv1alpha1.MatchExpression{Op: nfdv1alpha1.MatchExists}},
+								},
+							},
+						},
+					},
+				},
+			}
+			So(ruleIndexer.Add(nfr), ShouldBeNil)
+
+			// Taint the node and let the evaluation settle
+			So(fakeMaster.nfdAPIUpdateOneNode(fakeCli, getNode()), ShouldBeNil)
+			So(fakeMaster.nfdAPIUpdateOneNode(fakeCli, getNode()), ShouldBeNil)
+			n := getNode()
+			So(n.Spec.Taints, ShouldContain, taint)
+
+			// Resync of an unchanged node is skipped
+			So(fakeMaster.nfdAPIUpdateOneNode(fakeCli, n), ShouldBeNil)
+			So(getNode().ResourceVersion, ShouldEqual, n.ResourceVersion)
+
+			// Taint removed by someone else is restored
+			n.Spec.Taints = nil
+			_, err := fakeCli.CoreV1().Nodes().Update(context.TODO(), n, metav1.UpdateOptions{})
+			So(err, ShouldBeNil)
+			So(fakeMaster.nfdAPIUpdateOneNode(fakeCli, getNode()), ShouldBeNil)
+			So(getNode().Spec.Taints, ShouldContain, taint)
+		})
+	})
+}
+
+func TestNodeFeatureGroupOutput(t *testing.T) {
+	Convey("When merging the output of NodeFeatureGroups", t, func() {
+		nfg1 := &nfdv1alpha1.NodeFeatureGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "nfg-1", Namespace: "nfd"},
+			Spec: nfdv1alpha1.NodeFeatureGroupSpec{
+				Labels: map[string]string{"example.com/foo": "group", "example.com/bar": "1"},
+				Taints: []corev1.Taint{
+					{Key: "example.com/taint-1", Value: "group", Effect: corev1.TaintEffectNoSchedule},
+					{Key: "example.com/taint-2", Value: "group", Effect: corev1.TaintEffectNoSchedule},
+				},
+			},
+		}
+		nfg2 := &nfdv1alpha1.NodeFeatureGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "nfg-2", Namespace: "nfd"},
+			Spec:       nfdv1alpha1.NodeFeatureGroupSpec{Labels: map[string]string{"example.com/bar": "2", "example.com/baz": "2"}},
+		}
+		nfgOtherNs := &nfdv1alpha1.NodeFeatureGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "nfg-0", Namespace: "tenant"},
+			Spec: nfdv1alpha1.NodeFeatureGroupSpec{
+				Labels: map[string]string{"example.com/tenant": "true"},
+				Taints: []corev1.Taint{{Key: "example.com/tenant", Value: "true", Effect: corev1.TaintEffectNoExecute}},
+			},
+		}
+		labels := Labels{"example.com/foo": "rule"}
+		taints := []corev1.Taint{{Key: "example.com/taint-1", Value: "rule", Effect: corev1.TaintEffectNoSchedule}}
+
+		taints = mergeNodeFeatureGroupOutput("node-1", "nfd", []*nfdv1alpha1.NodeFeatureGroup{nfgOtherNs, nfg1, nfg2}, labels, taints)
+
+		Convey("Existing labels and taints should take precedence", func() {
+			So(labels, ShouldResemble, Labels{
+				"example.com/foo": "rule",
+				"example.com/bar": "1",
+				"example.com/baz": "2",
+			})
+			So(taints, ShouldResemble, []corev1.Taint{
+				{Key: "example.com/taint-1", Value: "rule", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "example.com/taint-2", Value: "group", Effect: corev1.TaintEffectNoSchedule},
+			})
+		})
+
+		Convey("Output of groups outside the nfd-master namespace should be ignored", func() {
+			So(labels, ShouldNotContainKey, "example.com/tenant")
+			So(taints, ShouldHaveLength, 2)
+		})
+	})
+
+	Convey("When updating a node", t, func() {
+		nf := &nfdv1alpha1.NodeFeature{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            testNodeName,
+				Namespace:       "nfd",
+				ResourceVersion: "1",
+				Labels:          map[string]string{nfdv1alpha1.NodeFeatureObjNodeNameLabel: testNodeName},
+			},
+			Spec: *nfdv1alpha1.NewNodeFeatureSpec(),
+		}
+		nf.Spec.Features.Flags["kernel.loadedmodule"] = nfdv1alpha1.NewFlagFeatures("foo")
+		nfg := &nfdv1alpha1.NodeFeatureGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "nfg-1", Namespace: "nfd", Generation: 1},
+			Spec: nfdv1alpha1.NodeFeatureGroupSpec{
+				Rules: []nfdv1alpha1.GroupRule{
+					{
+						Name: "rule-1",
+						MatchFeatures: nfdv1alpha1.FeatureMatcher{
+							{
+								Feature:          "kernel.loadedmodule",
+								MatchExpressions: &nfdv1alpha1.MatchExpressionSet{"foo": &nfdv1alpha1.MatchExpression{Op: nfdv1alpha1.MatchExists}},
+							},
+						},
+					},
+				},
+				Labels: map[string]string{"example.com/pool": "foo"},
+			},
+		}
+		featureIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		So(featureIndexer.Add(nf), ShouldBeNil)
+		groupIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		So(groupIndexer.Add(nfg), ShouldBeNil)
+
+		node := newTestNode()
+		node.Labels["existing"] = "label"
+		node.Annotations["existing"] = "annotation"
+		fakeCli := fakeclient.NewSimpleClientset(node)
+		fakeMaster := newFakeMaster(WithKubernetesClient(fakeCli), withConfig(&NFDConfig{ExtraLabelNs: utils.StringSetVal{"example.com": struct{}{}}, Restrictions: Restrictions{AllowOverwrite: true}}))
+		fakeMaster.namespace = "nfd"
+		fakeMaster.nfdController = &nfdController{
+			featureLister:      nfdlisters.NewNodeFeatureLister(featureIndexer),
+			ruleLister:         nfdlisters.NewNodeFeatureRuleLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+			featureGroupLister: nfdlisters.NewNodeFeatureGroupLister(groupIndexer),
+		}
+		fakeMaster.updaterPool.nfgQueue = workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[string]())
+		defer fakeMaster.updaterPool.nfgQueue.ShutDown()
+
+		getNode := func() *corev1.Node {
+			n, err := fakeCli.CoreV1().Nodes().Get(context.TODO(), testNodeName, metav1.GetOptions{})
+			So(err, ShouldBeNil)
+			return n
+		}
+
+		So(fakeMaster.nfdAPIUpdateOneNode(fakeCli, getNode()), ShouldBeNil)
+
+		Convey("Labels of the group should be created on member nodes", func() {
+			So(getNode().Labels, ShouldContainKey, "example.com/pool")
+		})
+
+		Convey("Labels of the group should be removed when the node leaves the group", func() {
+			nfUpdated := nf.DeepCopy()
+			nfUpdated.ResourceVersion = "2"
+			nfUpdated.Spec.Features.Flags["kernel.loadedmodule"] = nfdv1alpha1.NewFlagFeatures("bar")
+			So(featureIndexer.Update(nfUpdated), ShouldBeNil)
+
+			So(fakeMaster.nfdAPIUpdateOneNode(fakeCli, getNode()), ShouldBeNil)
+			So(getNode().Labels, ShouldNotContainKey, "example.com/pool")
+		})
+
+		Convey("Labels should be updated when the group spec changes", func() {
+			nfgUpdated := nfg.DeepCopy()
+			nfgUpdated.Generation = 2
+			nfgUpdated.Spec.Labels["example.com/pool"] = "bar"
+			So(groupIndexer.Update(nfgUpdated), ShouldBeNil)
+
+			So(fakeMaster.nfdAPIUpdateOneNode(fakeCli, getNode()), ShouldBeNil)
+			So(getNode().Labels["example.com/pool"], ShouldEqual, "bar")
+		})
+	})
+}
@@ -22,27 +22,36 @@ import (
 	"maps"
 	"net/http"
 	"os"
+	"os/signal"
 	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"golang.org/x/net/context"
+	"golang.org/x/time/rate"
 	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	k8sLabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	k8sclient "k8s.io/client-go/kubernetes"
+	k8sscheme "k8s.io/client-go/kubernetes/scheme"
 	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/events"
 	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/klog/v2"
@@ -72,11 +81,14 @@ type Annotations map[string]string
 // Restrictions contains the restrictions on the NF and NFR Crs
 type Restrictions struct {
 	NodeFeatureNamespaceSelector *metav1.LabelSelector
-	DisableLabels                bool
-	DisableExtendedResources     bool
-	DisableAnnotations           bool
-	DenyNodeFeatureLabels        bool
-	AllowOverwrite               bool
+	// NodeFeatureGroupNamespaceSelector selects the namespaces, in addition
+	// to the namespace of nfd-master, whose NodeFeatureGroups are processed
+	NodeFeatureGroupNamespaceSelector *metav1.LabelSelector
+	DisableLabels                     bool
+	DisableExtendedResources          bool
+	DisableAnnotations                bool
+	DenyNodeFeatureLabels             bool
+	AllowOverwrite                    bool
 }
 
 // NFDConfig contains the configuration settings of NfdMaster.
@@ -92,6 +104,32 @@ type NFDConfig struct {
 	Klog              klogutils.KlogConfigOpts
 	Restrictions      Restrictions
 	InformerPageSize  int64
+	// RuleConflictPolicy specifies how conflicting outputs of
+	// NodeFeatureRules are resolved
+	RuleConflictPolicy RuleConflictPolicy
+	// Safeguard limits the number of nodes that may lose a label or gain a
+	// disruptive taint within a time window
+	Safeguard SafeguardConfig
+	// LabelFlapDetection specifies how labels whose value changes back and
+	// forth are held back
+	LabelFlapDetection LabelFlapDetectionConfig
+	// NodeUpdates limits the rate of node updates
+	NodeUpdates NodeUpdatesConfig
+	// NodeUpdateStrategy specifies how node objects are updated
+	NodeUpdateStrategy NodeUpdateStrategy
+}
+
+// NodeUpdatesConfig contains the configuration for limiting the rate of node
+// updates.
+type NodeUpdatesConfig struct {
+	// QPS is the maximum number of node updates per second, zero meaning
+	// no limit
+	QPS float64
+	// Burst is the maximum number of node updates in a burst
+	Burst int
+	// CoalescePeriod is the time node updates are delayed so that repeated
+	// updates of the same node are merged into one
+	CoalescePeriod utils.DurationVal
 }
 
 // LeaderElectionConfig contains the configuration for leader election
@@ -124,6 +162,11 @@ type Args struct {
 	Options              string
 	EnableLeaderElection bool
 	MetricsPort          int
+	WebhookPort          int
+	WebhookCertFile      string
+	WebhookKeyFile       string
+	// ReloadOnSighup enables re-loading the configuration on SIGHUP
+	ReloadOnSighup bool
 
 	Overrides ConfigOverrideArgs
 }
@@ -133,6 +176,15 @@ type deniedNs struct {
 	wildcard utils.StringSetVal
 }
 
+// runtimeConfig is the configuration of nfd-master together with the data
+// derived from it.
+type runtimeConfig struct {
+	*NFDConfig
+	deniedNs deniedNs
+	// generation is incremented every time the configuration is applied
+	generation int64
+}
+
 type NfdMaster interface {
 	Run() error
 	Stop()
@@ -150,19 +202,46 @@ type nfdMaster struct {
 	k8sClient      k8sclient.Interface
 	nfdClient      nfdclientset.Interface
 	updaterPool    *updaterPool
-	deniedNs
-	config *NFDConfig
+	nfrStatus      *nfrStatusTracker
+	nodeFeatures   *nodeFeaturesCache
+	nfgMembership  *nfgMembershipTracker
+	// nodeEventRecorder emits events on node changes and NodeFeatureRule
+	// conflicts. The events.k8s.io API is used as it supports setting the
+	// originating NodeFeatureRule (or the affected node) as the related
+	// object. nodeEventBroadcaster is nil if nodeEventRecorder was set via
+	// opts by tests.
+	nodeEventBroadcaster events.EventBroadcaster
+	nodeEventRecorder    events.EventRecorder
+	nodeEventLimiter     *nodeEventLimiter
+	safeguard            *nodeUpdateSafeguard
+	labelFlaps           *labelFlapTracker
+	// nodeUpdateLimiter limits the rate of node updates, it is nil if rate
+	// limiting has not been configured
+	nodeUpdateLimiter *rate.Limiter
+	// activeConfig is the configuration in use, replaced as a whole when the
+	// configuration is reloaded
+	activeConfig atomic.Pointer[runtimeConfig]
 
 	// isLeader indicates if this instance is the leader, changing dynamically
 	isLeader bool
 }
 
+// configReloadRatelimit is the time to wait for further changes of the
+// config file before re-loading it.
+const configReloadRatelimit = time.Second
+
 // NewNfdMaster creates a new NfdMaster server instance.
 func NewNfdMaster(opts ...NfdMasterOption) (NfdMaster, error) {
 	nfd := &nfdMaster{
-		nodeName:  utils.NodeName(),
-		namespace: utils.GetKubernetesNamespace(),
-		stop:      make(chan struct{}),
+		nodeName:         utils.NodeName(),
+		namespace:        utils.GetKubernetesNamespace(),
+		stop:             make(chan struct{}),
+		nfrStatus:        newNfrStatusTracker(),
+		nodeFeatures:     newNodeFeaturesCache(),
+		nfgMembership:    newNfgMembershipTracker(),
+		nodeEventLimiter: newNodeEventLimiter(),
+		safeguard:        newNodeUpdateSafeguard(),
+		labelFlaps:       newLabelFlapTracker(),
 	}
 
 	for _, o := range opts {
@@ -209,6 +288,11 @@ func NewNfdMaster(opts ...NfdMasterOption) (NfdMaster, error) {
 		nfd.nfdClient = c
 	}
 
+	if nfd.nodeEventRecorder == nil {
+		nfd.nodeEventBroadcaster = events.NewBroadcaster(&events.EventSinkImpl{Interface: nfd.k8sClient.EventsV1()})
+		nfd.nodeEventRecorder = nfd.nodeEventBroadcaster.NewRecorder(newEventScheme(), "nfd-master")
+	}
+
 	nfd.updaterPool = newUpdaterPool(nfd)
 
 	return nfd, nil
@@ -238,6 +322,15 @@ func (f *nfdMasterOpt) apply(n *nfdMaster) {
 	f.f(n)
 }
 
+// newEventScheme returns a scheme for resolving the object references of
+// events, covering both core Kubernetes and NFD API types.
+func newEventScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	utilruntime.Must(k8sscheme.AddToScheme(s))
+	utilruntime.Must(nfdv1alpha1.AddToScheme(s))
+	return s
+}
+
 func newDefaultConfig() *NFDConfig {
 	return &NFDConfig{
 		DenyLabelNs:       utils.StringSetVal{},
@@ -260,6 +353,18 @@ func newDefaultConfig() *NFDConfig {
 			AllowOverwrite:           true,
 			DenyNodeFeatureLabels:    false,
 		},
+		RuleConflictPolicy: RuleConflictPolicyHighestPriorityWins,
+		Safeguard: SafeguardConfig{
+			Window: utils.DurationVal{Duration: time.Duration(10) * time.Minute},
+		},
+		LabelFlapDetection: LabelFlapDetectionConfig{
+			Window:     utils.DurationVal{Duration: time.Duration(10) * time.Minute},
+			Hysteresis: utils.DurationVal{Duration: time.Duration(5) * time.Minute},
+		},
+		NodeUpdates: NodeUpdatesConfig{
+			Burst: 10,
+		},
+		NodeUpdateStrategy: NodeUpdateStrategyJSONPatch,
 	}
 }
 
@@ -280,13 +385,24 @@ func (m *nfdMaster) Run() error {
 		return m.prune()
 	}
 
+	if m.nodeEventBroadcaster != nil {
+		if err := m.nodeEventBroadcaster.StartRecordingToSinkWithContext(context.Background()); err != nil {
+			return err
+		}
+	}
+
 	if err := m.startNfdApiController(); err != nil {
 		return err
 	}
 
-	m.updaterPool.start(m.config.NfdApiParallelism)
+	// Restore the state of the safeguard before updating any nodes
+	if err := m.startSafeguardInformer(); err != nil {
+		return err
+	}
+
+	m.updaterPool.start(m.config().NfdApiParallelism)
 
-	if !m.config.NoPublish {
+	if !m.config().NoPublish {
 		err := m.updateMasterNode()
 		if err != nil {
 			return fmt.Errorf("failed to update master node: %w", err)
@@ -305,8 +421,18 @@ func (m *nfdMaster) Run() error {
 		nodeLabelsRejected,
 		nodeERsRejected,
 		nodeTaintsRejected,
+		nodeEventsDropped,
 		nfrProcessingTime,
-		nfrProcessingErrors)
+		nfrProcessingErrors,
+		nfrConflicts,
+		nfrShadowMatchedNodes,
+		nodeUpdatesSkipped,
+		nodeUpdatesBlocked,
+		safeguardTripped,
+		labelFlaps,
+		nodeFieldConflicts,
+		configReloads,
+		configReloadFailures)
 	httpMux.Handle("/metrics", promhttp.HandlerFor(promRegistry, promhttp.HandlerOpts{}))
 	registerVersion(version.Get())
 
@@ -317,10 +443,20 @@ func (m *nfdMaster) Run() error {
 		m.isLeader = true
 	}
 	go m.nfdAPIUpdateHandler()
+	go m.nfrStatusUpdater()
 
 	// Register health probe (at this point we're "ready and live")
 	httpMux.HandleFunc("/healthz", m.Healthz)
 
+	// Register safeguard status endpoint
+	httpMux.HandleFunc("GET /safeguard", m.safeguardStatusHandler)
+
+	// Start validating admission webhook server
+	if m.args.WebhookPort > 0 {
+		stopWebhook := m.startWebhookServer()
+		defer stopWebhook()
+	}
+
 	// Start HTTP server
 	httpServer := http.Server{Addr: fmt.Sprintf(":%d", m.args.Port), Handler: httpMux}
 	go func() {
@@ -329,9 +465,43 @@ func (m *nfdMaster) Run() error {
 	}()
 	defer httpServer.Close()
 
-	<-m.stop
-	klog.InfoS("shutting down nfd-master")
-	return nil
+	// Watch the config file for changes
+	var configTrigger <-chan struct{}
+	if m.configFilePath != "" {
+		configWatch, err := utils.CreateFsWatcher(configReloadRatelimit, m.configFilePath)
+		if err != nil {
+			return err
+		}
+		defer configWatch.Close()
+		configTrigger = configWatch.Events
+	}
+
+	var sighup chan os.Signal
+	if m.args.ReloadOnSighup {
+		sighup = make(chan os.Signal, 1)
+		signal.Notify(sighup, syscall.SIGHUP)
+		defer signal.Stop(sighup)
+	}
+
+	for {
+		select {
+		case <-configTrigger:
+			klog.InfoS("config file changed, reloading configuration", "path", m.configFilePath)
+			if err := m.reloadConfig(); err != nil {
+				klog.ErrorS(err, "failed to reload configuration, keeping the current configuration")
+			}
+
+		case <-sighup:
+			klog.InfoS("SIGHUP received, reloading configuration")
+			if err := m.reloadConfig(); err != nil {
+				klog.ErrorS(err, "failed to reload configuration, keeping the current configuration")
+			}
+
+		case <-m.stop:
+			klog.InfoS("shutting down nfd-master")
+			return nil
+		}
+	}
 }
 
 func (m *nfdMaster) Healthz(writer http.ResponseWriter, _ *http.Request) {
@@ -344,7 +514,6 @@ func (m *nfdMaster) nfdAPIUpdateHandler() {
 	updateAll := true
 	updateNodes := make(map[string]struct{})
 	nodeFeatureGroup := make(map[string]struct{})
-	updateAllNodeFeatureGroups := false
 	rateLimit := time.After(time.Second)
 	for {
 		select {
@@ -352,8 +521,6 @@ func (m *nfdMaster) nfdAPIUpdateHandler() {
 			updateAll = true
 		case nodeName := <-m.nfdController.updateOneNodeChan:
 			updateNodes[nodeName] = struct{}{}
-		case <-m.nfdController.updateAllNodeFeatureGroupsChan:
-			updateAllNodeFeatureGroups = true
 		case nodeFeatureGroupName := <-m.nfdController.updateNodeFeatureGroupChan:
 			nodeFeatureGroup[nodeFeatureGroupName] = struct{}{}
 		case <-rateLimit:
@@ -376,21 +543,12 @@ func (m *nfdMaster) nfdAPIUpdateHandler() {
 				}
 			}
 			// NodeFeatureGroup
-			errUpdateAllNFG := false
-			if updateAllNodeFeatureGroups {
-				if err := m.nfdAPIUpdateAllNodeFeatureGroups(); err != nil {
-					klog.ErrorS(err, "failed to update NodeFeatureGroups")
-					errUpdateAllNFG = true
-				}
-			} else {
-				for nodeFeatureGroupName := range nodeFeatureGroup {
-					m.updaterPool.addNodeFeatureGroup(nodeFeatureGroupName)
-				}
+			for nodeFeatureGroupName := range nodeFeatureGroup {
+				m.updaterPool.addNodeFeatureGroup(nodeFeatureGroupName)
 			}
 
 			// Reset "work queue" and timer
 			updateAll = errUpdateAll
-			updateAllNodeFeatureGroups = errUpdateAllNFG
 			nodeFeatureGroup = map[string]struct{}{}
 			updateNodes = map[string]struct{}{}
 			rateLimit = time.After(time.Second)
@@ -402,13 +560,16 @@ func (m *nfdMaster) nfdAPIUpdateHandler() {
 func (m *nfdMaster) Stop() {
 	m.nfdController.stop()
 	m.updaterPool.stop()
+	if m.nodeEventBroadcaster != nil {
+		m.nodeEventBroadcaster.Shutdown()
+	}
 
 	close(m.stop)
 }
 
 // Prune erases all NFD related properties from the node objects of the cluster.
 func (m *nfdMaster) prune() error {
-	if m.config.NoPublish {
+	if m.config().NoPublish {
 		klog.InfoS("skipping pruning of nodes as noPublish config option is set")
 		return nil
 	}
@@ -422,7 +583,7 @@ func (m *nfdMaster) prune() error {
 		klog.InfoS("pruning node...", "nodeName", node.Name)
 
 		// Prune labels and extended resources
-		err := m.updateNodeObject(m.k8sClient, &node, Labels{}, Annotations{}, ExtendedResources{}, []corev1.Taint{})
+		err := m.updateNodeObject(m.k8sClient, &node, Labels{}, Annotations{}, ExtendedResources{}, []corev1.Taint{}, nil)
 		if err != nil {
 			nodeUpdateFailures.Inc()
 			return fmt.Errorf("failed to prune node %q: %v", node.Name, err)
@@ -458,7 +619,7 @@ func (m *nfdMaster) updateMasterNode() error {
 	p := createPatches(sets.New([]string{m.instanceAnnotation(nfdv1alpha1.MasterVersionAnnotation)}...),
 		node.Annotations,
 		nil,
-		"/metadata/annotations", m.config.Restrictions.AllowOverwrite)
+		"/metadata/annotations", m.config().Restrictions.AllowOverwrite)
 
 	err = patchNode(m.k8sClient, node.Name, p)
 	if err != nil {
@@ -482,7 +643,7 @@ func (m *nfdMaster) filterFeatureLabels(labels Labels, features *nfdv1alpha1.Fea
 		}
 	}
 
-	if len(outLabels) > 0 && m.config.Restrictions.DisableLabels {
+	if len(outLabels) > 0 && m.config().Restrictions.DisableLabels {
 		klog.V(2).InfoS("node labels are disabled in configuration (restrictions.disableLabels=true)")
 		outLabels = Labels{}
 	}
@@ -503,26 +664,37 @@ func (m *nfdMaster) filterFeatureLabel(name, value string, features *nfdv1alpha1
 	}
 
 	// Validate
-	ns, base := splitNs(name)
-	err := validate.Label(name, filteredValue)
-	if err == validate.ErrNSNotAllowed || isNamespaceDenied(ns, m.deniedNs.wildcard, m.deniedNs.normal) {
-		if _, ok := m.config.ExtraLabelNs[ns]; !ok {
-			return "", fmt.Errorf("namespace %q is not allowed", ns)
-		}
-	} else if err != nil {
-		if !nfdfeatures.NFDFeatureGate.Enabled(nfdfeatures.DisableAutoPrefix) || err != validate.ErrUnprefixedKeysNotAllowed {
-			return "", err
-		}
+	if err := m.validateFeatureLabel(name, filteredValue); err != nil {
+		return "", err
 	}
 
 	// Skip if label doesn't match labelWhiteList
-	if m.config.LabelWhiteList != nil && !m.config.LabelWhiteList.MatchString(base) {
-		return "", fmt.Errorf("%s (%s) does not match the whitelist (%s)", base, name, m.config.LabelWhiteList.String())
+	_, base := splitNs(name)
+	if m.config().LabelWhiteList != nil && !m.config().LabelWhiteList.MatchString(base) {
+		return "", fmt.Errorf("%s (%s) does not match the whitelist (%s)", base, name, m.config().LabelWhiteList.String())
 	}
 
 	return filteredValue, nil
 }
 
+// validateFeatureLabel validates a label name and value, taking into account
+// the allowed and denied label namespaces.
+func (m *nfdMaster) validateFeatureLabel(name, value string) error {
+	ns, _ := splitNs(name)
+	config := m.config()
+	err := validate.Label(name, value)
+	if err == validate.ErrNSNotAllowed || isNamespaceDenied(ns, config.deniedNs.wildcard, config.deniedNs.normal) {
+		if _, ok := config.ExtraLabelNs[ns]; !ok {
+			return fmt.Errorf("namespace %q is not allowed", ns)
+		}
+	} else if err != nil {
+		if !nfdfeatures.NFDFeatureGate.Enabled(nfdfeatures.DisableAutoPrefix) || err != validate.ErrUnprefixedKeysNotAllowed {
+			return err
+		}
+	}
+	return nil
+}
+
 func getDynamicValue(value string, features *nfdv1alpha1.Features) (string, error) {
 	// value is a string in the form of attribute.featureset.elements
 	split := strings.SplitN(value[1:], ".", 3)
@@ -579,26 +751,29 @@ func (m *nfdMaster) nfdAPIUpdateAllNodes() error {
 		return err
 	}
 
+	m.safeguard.setNodeCount(len(nodes.Items))
+
+	nodeNames := make(map[string]struct{}, len(nodes.Items))
 	for _, node := range nodes.Items {
-		m.updaterPool.addNode(node.Name)
+		nodeNames[node.Name] = struct{}{}
+		m.updaterPool.addNodeResync(node.Name)
 	}
+	// Drop stale NodeFeatureRule results and cached features of nodes that
+	// have been deleted
+	m.nfrStatus.pruneNodes(nodeNames)
+	m.nodeFeatures.pruneNodes(nodeNames)
+	m.labelFlaps.pruneNodes(nodeNames)
 
 	return nil
 }
 
-// getAndMergeNodeFeatures merges the NodeFeature objects of the given node into a single NodeFeatureSpec.
-// The Name field of the returned NodeFeatureSpec contains the node name.
-func (m *nfdMaster) getAndMergeNodeFeatures(nodeName string) (*nfdv1alpha1.NodeFeature, error) {
-	nodeFeatures := &nfdv1alpha1.NodeFeature{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: nodeName,
-		},
-	}
-
+// getNodeFeatureObjs returns the NodeFeature objects of the given node, in
+// the order they are merged.
+func (m *nfdMaster) getNodeFeatureObjs(nodeName string) ([]*nfdv1alpha1.NodeFeature, error) {
 	sel := k8sLabels.SelectorFromSet(k8sLabels.Set{nfdv1alpha1.NodeFeatureObjNodeNameLabel: nodeName})
 	objs, err := m.nfdController.featureLister.List(sel)
 	if err != nil {
-		return &nfdv1alpha1.NodeFeature{}, fmt.Errorf("failed to get NodeFeature resources for node %q: %w", nodeName, err)
+		return nil, fmt.Errorf("failed to get NodeFeature resources for node %q: %w", nodeName, err)
 	}
 
 	filteredObjs := []*nfdv1alpha1.NodeFeature{}
@@ -608,11 +783,6 @@ func (m *nfdMaster) getAndMergeNodeFeatures(nodeName string) (*nfdv1alpha1.NodeF
 		}
 	}
 
-	// Node without a running NFD-Worker
-	if len(filteredObjs) == 0 {
-		return &nfdv1alpha1.NodeFeature{}, nil
-	}
-
 	// Sort our objects
 	sort.Slice(filteredObjs, func(i, j int) bool {
 		// Objects in our nfd namespace gets into the beginning of the list
@@ -630,43 +800,62 @@ func (m *nfdMaster) getAndMergeNodeFeatures(nodeName string) (*nfdv1alpha1.NodeF
 		return filteredObjs[i].Namespace < filteredObjs[j].Namespace
 	})
 
-	if len(filteredObjs) > 0 {
-		// Merge in features
-		//
-		// NOTE: changing the rule api to support handle multiple objects instead
-		// of merging would probably perform better with lot less data to copy.
-		features := filteredObjs[0].Spec.DeepCopy()
+	return filteredObjs, nil
+}
+
+// mergeNodeFeatures merges the NodeFeature objects of the given node into a
+// single NodeFeatureSpec. The Name field of the returned NodeFeature contains
+// the node name, or, is empty if there are no NodeFeature objects (i.e. the
+// node does not have a running nfd-worker).
+func (m *nfdMaster) mergeNodeFeatures(nodeName string, filteredObjs []*nfdv1alpha1.NodeFeature) *nfdv1alpha1.NodeFeature {
+	// Node without a running NFD-Worker
+	if len(filteredObjs) == 0 {
+		return &nfdv1alpha1.NodeFeature{}
+	}
+
+	nodeFeatures := &nfdv1alpha1.NodeFeature{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeName,
+		},
+	}
 
-		if m.config.Restrictions.DenyNodeFeatureLabels && m.isThirdPartyNodeFeature(*filteredObjs[0], nodeName, m.namespace) {
+	// Merge in features. The result is cached by the caller so the
+	// objects are merged only when they change.
+	features := filteredObjs[0].Spec.DeepCopy()
+	m.restoreFailedSources(nodeName, filteredObjs[0], features)
+
+	if m.config().Restrictions.DenyNodeFeatureLabels && m.isThirdPartyNodeFeature(*filteredObjs[0], nodeName, m.namespace) {
+		klog.V(2).InfoS("node feature labels are disabled in configuration (restrictions.denyNodeFeatureLabels=true)")
+		features.Labels = nil
+	}
+
+	if !nfdfeatures.NFDFeatureGate.Enabled(nfdfeatures.DisableAutoPrefix) {
+		features.Labels = addNsToMapKeys(features.Labels, nfdv1alpha1.FeatureLabelNs)
+	}
+
+	for _, o := range filteredObjs[1:] {
+		s := o.Spec.DeepCopy()
+		m.restoreFailedSources(nodeName, o, s)
+		if m.config().Restrictions.DenyNodeFeatureLabels && m.isThirdPartyNodeFeature(*o, nodeName, m.namespace) {
 			klog.V(2).InfoS("node feature labels are disabled in configuration (restrictions.denyNodeFeatureLabels=true)")
-			features.Labels = nil
+			s.Labels = nil
 		}
 
 		if !nfdfeatures.NFDFeatureGate.Enabled(nfdfeatures.DisableAutoPrefix) {
-			features.Labels = addNsToMapKeys(features.Labels, nfdv1alpha1.FeatureLabelNs)
+			s.Labels = addNsToMapKeys(s.Labels, nfdv1alpha1.FeatureLabelNs)
 		}
 
-		for _, o := range filteredObjs[1:] {
-			s := o.Spec.DeepCopy()
-			if m.config.Restrictions.DenyNodeFeatureLabels && m.isThirdPartyNodeFeature(*o, nodeName, m.namespace) {
-				klog.V(2).InfoS("node feature labels are disabled in configuration (restrictions.denyNodeFeatureLabels=true)")
-				s.Labels = nil
-			}
-
-			if !nfdfeatures.NFDFeatureGate.Enabled(nfdfeatures.DisableAutoPrefix) {
-				s.Labels = addNsToMapKeys(s.Labels, nfdv1alpha1.FeatureLabelNs)
-			}
+		s.MergeInto(features)
+	}
 
-			s.MergeInto(features)
-		}
+	addSourceStatusFeature(&features.Features, filteredObjs)
 
-		// Set the merged features to the NodeFeature object
-		nodeFeatures.Spec = *features
+	// Set the merged features to the NodeFeature object
+	nodeFeatures.Spec = *features
 
-		klog.V(4).InfoS("merged nodeFeatureSpecs", "newNodeFeatureSpec", utils.DelayedDumper(features))
-	}
+	klog.V(4).InfoS("merged nodeFeatureSpecs", "newNodeFeatureSpec", utils.DelayedDumper(features))
 
-	return nodeFeatures, nil
+	return nodeFeatures
 }
 
 // isThirdPartyNodeFeature determines whether a node feature is a third party one or created by nfd-worker
@@ -674,98 +863,212 @@ func (m *nfdMaster) isThirdPartyNodeFeature(nodeFeature nfdv1alpha1.NodeFeature,
 	return nodeFeature.Namespace != namespace || nodeFeature.Name != nodeName
 }
 
-func (m *nfdMaster) nfdAPIUpdateOneNode(cli k8sclient.Interface, node *corev1.Node) error {
+// getNodeFeatures returns the merged NodeFeature objects of a node, using
+// the cache of merged features if none of the objects has changed. The
+// returned object is shared and must not be modified. The Name field of the
+// returned object is empty if the node does not have any NodeFeature objects.
+// Also returns the key identifying the NodeFeature objects.
+func (m *nfdMaster) getNodeFeatures(nodeName string) (*nfdv1alpha1.NodeFeature, string, error) {
+	objs, err := m.getNodeFeatureObjs(nodeName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	featuresKey := nodeFeaturesKey(objs)
+	if nodeFeatures, ok := m.nodeFeatures.get(nodeName, featuresKey); ok {
+		return nodeFeatures, featuresKey, nil
+	}
+
 	// Merge all NodeFeature objects into a single NodeFeatureSpec
-	nodeFeatures, err := m.getAndMergeNodeFeatures(node.Name)
+	nodeFeatures := m.mergeNodeFeatures(nodeName, objs)
+	m.nodeFeatures.set(nodeName, featuresKey, nodeFeatures)
+	return nodeFeatures, featuresKey, nil
+}
+
+func (m *nfdMaster) nfdAPIUpdateOneNode(cli k8sclient.Interface, node *corev1.Node) error {
+	nodeFeatures, featuresKey, err := m.getNodeFeatures(node.Name)
+	if err != nil {
+		return err
+	}
+
+	// Skip the update if nothing has changed since the previous update of
+	// the node, e.g. on resync
+	ruleSpecs, err := m.nfdController.ruleLister.List(k8sLabels.Everything())
 	if err != nil {
-		return fmt.Errorf("failed to merge NodeFeature objects for node %q: %w", node.Name, err)
+		return fmt.Errorf("failed to list NodeFeatureRule resources: %w", err)
+	}
+	var nodeFeatureGroups []*nfdv1alpha1.NodeFeatureGroup
+	if m.nfdController.featureGroupLister != nil {
+		if nodeFeatureGroups, err = m.listNodeFeatureGroups(); err != nil {
+			return err
+		}
+	}
+	evaluationKey := nodeEvaluationKey(m.config().generation, featuresKey, nodeFeatureRulesKey(ruleSpecs), nodeFeatureGroupsKey(nodeFeatureGroups), node)
+	if m.nodeFeatures.isEvaluated(node.Name, evaluationKey) {
+		klog.V(2).InfoS("no changes in node features or rules, skipping node update", "nodeName", node.Name)
+		nodeUpdatesSkipped.Inc()
+		return nil
 	}
 
+	// Update the NodeFeatureGroups the node is a member of
+	memberOf := m.updateNodeFeatureGroupMembership(node.Name, nodeFeatures, nodeFeatureGroups)
+
 	// Update node labels et al. This may also mean removing all NFD-owned
 	// labels (et al.), for example  in the case no NodeFeature objects are
-	// present.
-	if err := m.refreshNodeFeatures(cli, node, nodeFeatures.Spec.Labels, &nodeFeatures.Spec.Features); err != nil {
+	// present. Rule processing modifies the features so operate on a copy.
+	nodeFeatures = nodeFeatures.DeepCopy()
+	if err := m.refreshNodeFeatures(cli, node, nodeFeatures.Spec.Labels, &nodeFeatures.Spec.Features, memberOf); err != nil {
 		return err
 	}
+	// Nodes with flapping labels held back need to be re-evaluated when the
+	// labels are released
+	if !m.labelFlaps.holding(node.Name) {
+		m.nodeFeatures.setEvaluated(node.Name, evaluationKey)
+	}
 
 	return nil
 }
 
-func (m *nfdMaster) nfdAPIUpdateAllNodeFeatureGroups() error {
-	klog.V(1).InfoS("updating all NodeFeatureGroups")
-
-	nodeFeatureGroupsList, err := m.nfdController.featureGroupLister.List(labels.Everything())
+// listNodeFeatureGroups returns the NodeFeatureGroups in the selected
+// namespaces, sorted by namespace and name.
+func (m *nfdMaster) listNodeFeatureGroups() ([]*nfdv1alpha1.NodeFeatureGroup, error) {
+	nodeFeatureGroups, err := m.nfdController.featureGroupLister.List(labels.Everything())
 	if err != nil {
-		return fmt.Errorf("failed to get NodeFeatureGroup objects: %w", err)
+		return nil, fmt.Errorf("failed to get NodeFeatureGroup objects: %w", err)
 	}
 
-	if len(nodeFeatureGroupsList) > 0 {
-		for _, nodeFeatureGroup := range nodeFeatureGroupsList {
-			m.updaterPool.nfgQueue.Add(nodeFeatureGroup.Name)
+	selected := make([]*nfdv1alpha1.NodeFeatureGroup, 0, len(nodeFeatureGroups))
+	for _, nfg := range nodeFeatureGroups {
+		if m.isNodeFeatureGroupNamespaceSelected(nfg.Namespace) {
+			selected = append(selected, nfg)
 		}
-	} else {
-		klog.V(2).InfoS("no NodeFeatureGroup objects found")
 	}
+	sort.Slice(selected, func(i, j int) bool { return nfgKey(selected[i]) < nfgKey(selected[j]) })
+	return selected, nil
+}
 
-	return nil
+// updateNodeFeatureGroupMembership evaluates NodeFeatureGroups against one
+// node and queues a status update for the groups whose membership changed.
+// Returns the groups the node is a member of.
+func (m *nfdMaster) updateNodeFeatureGroupMembership(nodeName string, nodeFeatures *nfdv1alpha1.NodeFeature, nodeFeatureGroups []*nfdv1alpha1.NodeFeatureGroup) []*nfdv1alpha1.NodeFeatureGroup {
+	var memberOf []*nfdv1alpha1.NodeFeatureGroup
+	for _, nfg := range nodeFeatureGroups {
+		var res nfgNodeResult
+		if nodeFeatures.Name != "" {
+			res = evaluateNodeFeatureGroupRules(nfg, &nodeFeatures.Spec.Features)
+		}
+		if res.isMember() {
+			memberOf = append(memberOf, nfg)
+		}
+		if m.nfgMembership.updateNode(nfg, nodeName, res) {
+			klog.V(2).InfoS("NodeFeatureGroup membership changed", "nodeFeatureGroup", klog.KObj(nfg), "nodeName", nodeName, "member", res.isMember(), "matchedRules", res.matchedRules)
+			m.updaterPool.addNodeFeatureGroup(nfgKey(nfg))
+		}
+	}
+	return memberOf
 }
 
-func (m *nfdMaster) nfdAPIUpdateNodeFeatureGroup(nfdClient nfdclientset.Interface, nodeFeatureGroup *nfdv1alpha1.NodeFeatureGroup) error {
+// isNodeFeatureGroupNamespaceSelected returns true if NodeFeatureGroups in the
+// given namespace are processed. NodeFeatureGroups in the namespace of
+// nfd-master are always processed, other namespaces need to be selected with
+// the nodeFeatureGroupNamespaceSelector config option.
+func (m *nfdMaster) isNodeFeatureGroupNamespaceSelected(namespace string) bool {
+	return namespace == m.namespace || m.nfdController.isNodeFeatureGroupNamespaceSelected(namespace)
+}
+
+// removeNodeFromNodeFeatureGroups removes a node that has been deleted from
+// all NodeFeatureGroups.
+func (m *nfdMaster) removeNodeFromNodeFeatureGroups(nodeName string) {
+	for _, key := range m.nfgMembership.removeNode(nodeName) {
+		m.updaterPool.addNodeFeatureGroup(key)
+	}
+}
+
+// evaluateNodeFeatureGroupRules evaluates the rules of a NodeFeatureGroup
+// against the features of a node. The node is a member of the group if any of
+// the rules matches.
+func evaluateNodeFeatureGroupRules(nodeFeatureGroup *nfdv1alpha1.NodeFeatureGroup, features *nfdv1alpha1.Features) nfgNodeResult {
+	res := nfgNodeResult{}
+	// Vars from the rule output are fed back to the features, operate on a copy
+	features = features.DeepCopy()
+	for _, rule := range nodeFeatureGroup.Spec.Rules {
+		ruleOut, err := nodefeaturerule.ExecuteGroupRule(&rule, features, true)
+		if err != nil {
+			klog.ErrorS(err, "failed to evaluate rule", "ruleName", rule.Name, "nodeFeatureGroup", klog.KObj(nodeFeatureGroup))
+			if res.err == "" {
+				res.err = fmt.Sprintf("rule %q: %v", rule.Name, err)
+			}
+			continue
+		}
+
+		if ruleOut.MatchStatus.IsMatch {
+			res.matchedRules = append(res.matchedRules, rule.Name)
+			if len(ruleOut.Vars) > 0 {
+				if res.vars == nil {
+					res.vars = make(map[string]string, len(ruleOut.Vars))
+				}
+				maps.Copy(res.vars, ruleOut.Vars)
+			}
+		}
+
+		// Feed back vars from rule output to features map for subsequent rules to match
+		features.InsertAttributeFeatures(nfdv1alpha1.RuleBackrefDomain, nfdv1alpha1.RuleBackrefFeature, ruleOut.Vars)
+	}
+	return res
+}
+
+// evaluateNodeFeatureGroup evaluates a NodeFeatureGroup against all nodes of
+// the cluster.
+func (m *nfdMaster) evaluateNodeFeatureGroup(nodeFeatureGroup *nfdv1alpha1.NodeFeatureGroup) error {
 	klog.V(2).InfoS("evaluating NodeFeatureGroup", "nodeFeatureGroup", klog.KObj(nodeFeatureGroup))
 
+	m.nfgMembership.beginEvaluation(nodeFeatureGroup)
+
 	// Get all Nodes
 	nodes, err := getNodes(m.k8sClient)
 	if err != nil {
 		return fmt.Errorf("failed to get nodes: %w", err)
 	}
-	nodeFeaturesList := make([]*nfdv1alpha1.Features, 0)
+
+	// Execute rules and create matching groups
+	results := make(map[string]nfgNodeResult, len(nodes.Items))
 	for _, node := range nodes.Items {
-		// Merge all NodeFeature objects into a single NodeFeatureSpec
-		nodeFeatures, err := m.getAndMergeNodeFeatures(node.Name)
+		nodeFeatures, _, err := m.getNodeFeatures(node.Name)
 		if err != nil {
-			return fmt.Errorf("failed to merge NodeFeature objects for node %q: %w", node.Name, err)
+			return err
 		}
 		if nodeFeatures.Name == "" {
 			// Nothing to do for this node
 			continue
 		}
-		nodeFeaturesList = append(nodeFeaturesList, &nodeFeatures.Spec.Features)
+		results[node.Name] = evaluateNodeFeatureGroupRules(nodeFeatureGroup, &nodeFeatures.Spec.Features)
 	}
 
-	// Execute rules and create matching groups
-	nodePool := make([]nfdv1alpha1.FeatureGroupNode, 0)
-	nodeGroupValidator := make(map[string]bool)
-	for _, features := range nodeFeaturesList {
-		for _, rule := range nodeFeatureGroup.Spec.Rules {
-			ruleOut, err := nodefeaturerule.ExecuteGroupRule(&rule, features, true)
-			if err != nil {
-				klog.ErrorS(err, "failed to evaluate rule", "ruleName", rule.Name)
-				continue
-			}
-
-			if ruleOut.MatchStatus.IsMatch {
-				system := features.Attributes["system.name"]
-				nodeName := system.Elements["nodename"]
-				if _, ok := nodeGroupValidator[nodeName]; !ok {
-					nodePool = append(nodePool, nfdv1alpha1.FeatureGroupNode{
-						Name: nodeName,
-					})
-					nodeGroupValidator[nodeName] = true
-				}
-			}
+	m.nfgMembership.finishEvaluation(nodeFeatureGroup, results)
+	return nil
+}
 
-			// Feed back vars from rule output to features map for subsequent rules to match
-			features.InsertAttributeFeatures(nfdv1alpha1.RuleBackrefDomain, nfdv1alpha1.RuleBackrefFeature, ruleOut.Vars)
+// nfdAPIUpdateNodeFeatureGroup updates the status of a NodeFeatureGroup. The
+// group is evaluated against all nodes if its spec has changed, otherwise the
+// incrementally updated membership is used.
+func (m *nfdMaster) nfdAPIUpdateNodeFeatureGroup(nfdClient nfdclientset.Interface, nodeFeatureGroup *nfdv1alpha1.NodeFeatureGroup) error {
+	status, ok := m.nfgMembership.status(nodeFeatureGroup)
+	if !ok {
+		if err := m.evaluateNodeFeatureGroup(nodeFeatureGroup); err != nil {
+			return err
+		}
+		if status, ok = m.nfgMembership.status(nodeFeatureGroup); !ok {
+			return fmt.Errorf("evaluation of NodeFeatureGroup %q was superseded", nodeFeatureGroup.Name)
 		}
 	}
 
 	// Update the NodeFeatureGroup object with the updated featureGroupRules
 	nodeFeatureGroupUpdated := nodeFeatureGroup.DeepCopy()
-	nodeFeatureGroupUpdated.Status.Nodes = nodePool
+	nodeFeatureGroupUpdated.Status = status
 
 	if !apiequality.Semantic.DeepEqual(nodeFeatureGroup, nodeFeatureGroupUpdated) {
 		klog.InfoS("updating NodeFeatureGroup object", "nodeFeatureGroup", klog.KObj(nodeFeatureGroup))
-		nodeFeatureGroupUpdated, err = nfdClient.NfdV1alpha1().NodeFeatureGroups(m.namespace).UpdateStatus(context.TODO(), nodeFeatureGroupUpdated, metav1.UpdateOptions{})
+		nodeFeatureGroupUpdated, err := nfdClient.NfdV1alpha1().NodeFeatureGroups(nodeFeatureGroup.Namespace).UpdateStatus(context.TODO(), nodeFeatureGroupUpdated, metav1.UpdateOptions{})
 		if err != nil {
 			return fmt.Errorf("failed to update NodeFeatureGroup object: %w", err)
 		}
@@ -817,23 +1120,24 @@ func filterExtendedResource(name, value string, features *nfdv1alpha1.Features)
 	return filteredValue, nil
 }
 
-func (m *nfdMaster) refreshNodeFeatures(cli k8sclient.Interface, node *corev1.Node, labels map[string]string, features *nfdv1alpha1.Features) error {
+func (m *nfdMaster) refreshNodeFeatures(cli k8sclient.Interface, node *corev1.Node, labels map[string]string, features *nfdv1alpha1.Features, nodeFeatureGroups []*nfdv1alpha1.NodeFeatureGroup) error {
 	if !nfdfeatures.NFDFeatureGate.Enabled(nfdfeatures.DisableAutoPrefix) {
 		labels = addNsToMapKeys(labels, nfdv1alpha1.FeatureLabelNs)
 	} else if labels == nil {
 		labels = make(map[string]string)
 	}
 
-	crLabels, crAnnotations, crExtendedResources, crTaints := m.processNodeFeatureRule(node.Name, features)
+	crLabels, crAnnotations, crExtendedResources, crTaints, crOwners := m.processNodeFeatureRule(node, features)
 
 	// Labels
 	maps.Copy(labels, crLabels)
+	crTaints = mergeNodeFeatureGroupOutput(node.Name, m.namespace, nodeFeatureGroups, labels, crTaints)
 	labels = m.filterFeatureLabels(labels, features)
 
 	// Extended resources
 	extendedResources := m.filterExtendedResources(features, crExtendedResources)
 
-	if len(extendedResources) > 0 && m.config.Restrictions.DisableExtendedResources {
+	if len(extendedResources) > 0 && m.config().Restrictions.DisableExtendedResources {
 		klog.V(2).InfoS("extended resources are disabled in configuration (restrictions.disableExtendedResources=true)")
 		extendedResources = map[string]string{}
 	}
@@ -843,16 +1147,33 @@ func (m *nfdMaster) refreshNodeFeatures(cli k8sclient.Interface, node *corev1.No
 
 	// Taints
 	var taints []corev1.Taint
-	if m.config.EnableTaints {
+	if m.config().EnableTaints {
 		taints = filterTaints(crTaints)
 	}
 
-	if m.config.NoPublish {
+	if m.config().NoPublish {
 		klog.V(1).InfoS("node update skipped, NoPublish=true", "nodeName", node.Name)
 		return nil
 	}
 
-	err := m.updateNodeObject(cli, node, labels, annotations, extendedResources, taints)
+	// Hold back changes of flapping labels
+	nodeLabels := make(Labels)
+	for _, name := range m.nfdManagedLabels(node) {
+		if value, ok := node.Labels[name]; ok {
+			nodeLabels[name] = value
+		}
+	}
+	now := time.Now()
+	labels, releaseTime := m.labelFlaps.filter(node.Name, nodeLabels, labels, m.config().LabelFlapDetection, now)
+	if !releaseTime.IsZero() {
+		m.updaterPool.addNodeAfter(node.Name, releaseTime.Sub(now))
+	}
+
+	if !m.checkSafeguard(node, labels, taints) {
+		return errNodeUpdatesPaused
+	}
+
+	err := m.updateNodeObject(cli, node, labels, annotations, extendedResources, taints, crOwners)
 	if err != nil {
 		klog.ErrorS(err, "failed to update node", "nodeName", node.Name)
 		return err
@@ -863,8 +1184,9 @@ func (m *nfdMaster) refreshNodeFeatures(cli k8sclient.Interface, node *corev1.No
 
 // setTaints sets node taints and annotations based on the taints passed via
 // nodeFeatureRule custom resorce. If empty list of taints is passed, currently
-// NFD owned taints and annotations are removed from the node.
-func (m *nfdMaster) setTaints(cli k8sclient.Interface, taints []corev1.Taint, node *corev1.Node) error {
+// NFD owned taints and annotations are removed from the node. Returns the
+// changes made to the taints of the node.
+func (m *nfdMaster) setTaints(cli k8sclient.Interface, taints []corev1.Taint, node *corev1.Node) ([]nodeChange, error) {
 	// De-serialize the taints annotation into corev1.Taint type for comparision below.
 	var err error
 	oldTaints := []corev1.Taint{}
@@ -872,12 +1194,13 @@ func (m *nfdMaster) setTaints(cli k8sclient.Interface, taints []corev1.Taint, no
 		sts := strings.Split(val, ",")
 		oldTaints, _, err = taintutils.ParseTaints(sts)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	// Delete old nfd-managed taints that are not found in the set of new taints.
 	taintsUpdated := false
+	var changes []nodeChange
 	newNode := node.DeepCopy()
 	for _, taintToRemove := range oldTaints {
 		if taintutils.TaintExists(taints, &taintToRemove) {
@@ -890,21 +1213,35 @@ func (m *nfdMaster) setTaints(cli k8sclient.Interface, taints []corev1.Taint, no
 		}
 		taintsUpdated = taintsUpdated || removed
 		newNode.Spec.Taints = newTaints
+		if removed {
+			changes = append(changes, nodeChange{outputType: ruleOutputTaint, name: taintToRemove.Key + ":" + string(taintToRemove.Effect), op: nodeChangeRemoved})
+		}
 	}
 
 	// Add new taints found in the set of new taints.
 	for _, taint := range taints {
 		var updated bool
+		existed := taintutils.TaintExists(newNode.Spec.Taints, &taint)
 		newNode, updated, err = taintutils.AddOrUpdateTaint(newNode, &taint)
 		if err != nil {
-			return fmt.Errorf("failed to add %q taint on node %v", taint, node.Name)
+			return nil, fmt.Errorf("failed to add %q taint on node %v", taint, node.Name)
 		}
 		taintsUpdated = taintsUpdated || updated
+		if updated {
+			op := nodeChangeAdded
+			if existed {
+				op = nodeChangeChanged
+			}
+			changes = append(changes, nodeChange{outputType: ruleOutputTaint, name: taint.Key + ":" + string(taint.Effect), op: op})
+		}
 	}
 
 	if taintsUpdated {
+		if err := m.waitNodeUpdateLimiter(); err != nil {
+			return nil, err
+		}
 		if err := controller.PatchNodeTaints(context.TODO(), cli, node.Name, node, newNode); err != nil {
-			return fmt.Errorf("failed to patch the node %v", node.Name)
+			return nil, fmt.Errorf("failed to patch the node %v", node.Name)
 		}
 		klog.InfoS("updated node taints", "nodeName", node.Name)
 	}
@@ -924,26 +1261,34 @@ func (m *nfdMaster) setTaints(cli k8sclient.Interface, taints []corev1.Taint, no
 	patches := createPatches(sets.New([]string{nfdv1alpha1.NodeTaintsAnnotation}...),
 		node.Annotations, newAnnotations,
 		"/metadata/annotations",
-		m.config.Restrictions.AllowOverwrite,
+		m.config().Restrictions.AllowOverwrite,
 	)
 	if len(patches) > 0 {
+		if err := m.waitNodeUpdateLimiter(); err != nil {
+			return changes, err
+		}
 		if err := patchNode(cli, node.Name, patches); err != nil {
-			return fmt.Errorf("error while patching node object: %w", err)
+			return changes, fmt.Errorf("error while patching node object: %w", err)
 		}
 		klog.V(1).InfoS("patched node annotations for taints", "nodeName", node.Name)
 	}
-	return nil
+	return changes, nil
 }
 
-func (m *nfdMaster) processNodeFeatureRule(nodeName string, features *nfdv1alpha1.Features) (Labels, Annotations, ExtendedResources, []corev1.Taint) {
+// processNodeFeatureRule evaluates NodeFeatureRules against a node. In
+// addition to the merged output of the rules, returns the rules that produced
+// each output item, keyed by "<output type>/<name>".
+func (m *nfdMaster) processNodeFeatureRule(node *corev1.Node, features *nfdv1alpha1.Features) (Labels, Annotations, ExtendedResources, []corev1.Taint, map[string]ruleOutputOwner) {
 	if m.nfdController == nil {
-		return nil, nil, nil, nil
+		return nil, nil, nil, nil, nil
 	}
 
+	nodeName := node.Name
+
 	extendedResources := ExtendedResources{}
 	labels := make(map[string]string)
 	annotations := make(map[string]string)
-	var taints []corev1.Taint
+	merger := newRuleOutputMerger(m.config().RuleConflictPolicy)
 	ruleSpecs, err := m.nfdController.ruleLister.List(k8sLabels.Everything())
 	sort.Slice(ruleSpecs, func(i, j int) bool {
 		return ruleSpecs[i].Name < ruleSpecs[j].Name
@@ -951,12 +1296,30 @@ func (m *nfdMaster) processNodeFeatureRule(nodeName string, features *nfdv1alpha
 
 	if err != nil {
 		klog.ErrorS(err, "failed to list NodeFeatureRule resources")
-		return nil, nil, nil, nil
+		return nil, nil, nil, nil, nil
 	}
 
 	// Process all rule CRs
 	processStart := time.Now()
+	var conflicts []ruleConflict
+	var processed []*nfdv1alpha1.NodeFeatureRule
+	nfrResults := make(map[string]map[string]ruleResult, len(ruleSpecs))
 	for _, spec := range ruleSpecs {
+		if selected, err := nodeSelectedByRule(spec, node); err != nil {
+			klog.ErrorS(err, "failed to process NodeFeatureRule", "nodefeaturerule", klog.KObj(spec), "nodeName", nodeName)
+			nfrProcessingErrors.Inc()
+			results := make(map[string]ruleResult, len(spec.Spec.Rules))
+			for _, rule := range spec.Spec.Rules {
+				results[rule.Name] = ruleResult{err: err.Error(), errTime: time.Now()}
+			}
+			m.nfrStatus.record(spec, nodeName, results)
+			continue
+		} else if !selected {
+			klog.V(2).InfoS("node not selected by NodeFeatureRule, skipping", "nodefeaturerule", klog.KObj(spec), "nodeName", nodeName)
+			m.nfrStatus.removeNodeResults(spec, nodeName)
+			continue
+		}
+
 		t := time.Now()
 		switch {
 		case klog.V(3).Enabled():
@@ -964,14 +1327,31 @@ func (m *nfdMaster) processNodeFeatureRule(nodeName string, features *nfdv1alpha
 		case klog.V(1).Enabled():
 			klog.InfoS("executing NodeFeatureRule", "nodefeaturerule", klog.KObj(spec), "nodeName", nodeName)
 		}
+		// Rules in shadow mode must not affect the evaluation of other
+		// NodeFeatureRules via rule back-references
+		ruleFeatures := features
+		if spec.Spec.Shadow {
+			ruleFeatures = features.DeepCopy()
+		}
+		results := make(map[string]ruleResult, len(spec.Spec.Rules))
 		for _, rule := range spec.Spec.Rules {
-			ruleOut, err := nodefeaturerule.Execute(&rule, features, true)
+			ruleOut, err := nodefeaturerule.Execute(&rule, ruleFeatures, true)
 			if err != nil {
 				klog.ErrorS(err, "failed to process rule", "ruleName", rule.Name, "nodefeaturerule", klog.KObj(spec), "nodeName", nodeName)
 				nfrProcessingErrors.Inc()
+				results[rule.Name] = ruleResult{err: err.Error(), errTime: time.Now()}
+				continue
+			}
+			results[rule.Name] = ruleResult{matched: ruleMatched(&rule, ruleOut)}
+
+			if spec.Spec.Shadow {
+				klog.V(2).InfoS("NodeFeatureRule in shadow mode, not applying rule output", "ruleName", rule.Name, "nodefeaturerule", klog.KObj(spec), "nodeName", nodeName)
+				results[rule.Name] = ruleResult{matched: results[rule.Name].matched, shadowOutput: m.shadowRuleOutput(ruleOut, ruleFeatures)}
+
+				ruleFeatures.InsertAttributeFeatures(nfdv1alpha1.RuleBackrefDomain, nfdv1alpha1.RuleBackrefFeature, ruleOut.Labels)
+				ruleFeatures.InsertAttributeFeatures(nfdv1alpha1.RuleBackrefDomain, nfdv1alpha1.RuleBackrefFeature, ruleOut.Vars)
 				continue
 			}
-			taints = append(taints, ruleOut.Taints...)
 
 			l := ruleOut.Labels
 			e := ruleOut.ExtendedResources
@@ -981,26 +1361,190 @@ func (m *nfdMaster) processNodeFeatureRule(nodeName string, features *nfdv1alpha
 				e = addNsToMapKeys(ruleOut.ExtendedResources, nfdv1alpha1.ExtendedResourceNs)
 				a = addNsToMapKeys(ruleOut.Annotations, nfdv1alpha1.FeatureAnnotationNs)
 			}
-			maps.Copy(labels, l)
-			maps.Copy(extendedResources, e)
-			maps.Copy(annotations, a)
+			owner := ruleOutputOwner{nfr: spec, rule: rule.Name}
+			ruleConflicts := merger.mergeMap(ruleOutputLabel, labels, l, owner)
+			ruleConflicts = append(ruleConflicts, merger.mergeMap(ruleOutputExtendedResource, extendedResources, e, owner)...)
+			ruleConflicts = append(ruleConflicts, merger.mergeMap(ruleOutputAnnotation, annotations, a, owner)...)
+			ruleConflicts = append(ruleConflicts, merger.mergeTaints(ruleOut.Taints, owner)...)
+			conflicts = append(conflicts, ruleConflicts...)
 
 			// Feed back rule output to features map for subsequent rules to match
 			features.InsertAttributeFeatures(nfdv1alpha1.RuleBackrefDomain, nfdv1alpha1.RuleBackrefFeature, ruleOut.Labels)
 			features.InsertAttributeFeatures(nfdv1alpha1.RuleBackrefDomain, nfdv1alpha1.RuleBackrefFeature, ruleOut.Vars)
 		}
+		processed = append(processed, spec)
+		nfrResults[spec.Name] = results
 		nfrProcessingTime.WithLabelValues(spec.Name, nodeName).Observe(time.Since(t).Seconds())
 	}
 	processingTime := time.Since(processStart)
 	klog.V(2).InfoS("processed NodeFeatureRule objects", "nodeName", nodeName, "objectCount", len(ruleSpecs), "duration", processingTime)
 
-	return labels, annotations, extendedResources, taints
+	// With the error policy a conflict is an error of all the rules involved
+	if m.config().RuleConflictPolicy == RuleConflictPolicyError {
+		now := time.Now()
+		for _, c := range conflicts {
+			for _, o := range c.losers() {
+				if r := nfrResults[o.nfr.Name][o.rule]; r.err == "" {
+					r.err, r.errTime = c.String(), now
+					nfrResults[o.nfr.Name][o.rule] = r
+				}
+			}
+		}
+	}
+	for _, spec := range processed {
+		m.nfrStatus.record(spec, nodeName, nfrResults[spec.Name])
+	}
+	m.reportRuleConflicts(node, conflicts)
+
+	return labels, annotations, extendedResources, merger.taints, merger.owners
+}
+
+// mergeNodeFeatureGroupOutput merges the labels and taints of the
+// NodeFeatureGroups a node is a member of to the labels and taints created
+// from NodeFeature objects and NodeFeatureRules, which take precedence. Of
+// conflicting NodeFeatureGroups the one that is first in order wins. Only
+// NodeFeatureGroups in the given namespace (the namespace of nfd-master) are
+// allowed to modify nodes, the output of groups in other namespaces is
+// ignored.
+func mergeNodeFeatureGroupOutput(nodeName, namespace string, nodeFeatureGroups []*nfdv1alpha1.NodeFeatureGroup, labels Labels, taints []corev1.Taint) []corev1.Taint {
+	for _, nfg := range nodeFeatureGroups {
+		if nfg.Namespace != namespace {
+			if nodeFeatureGroupHasOutput(nfg) {
+				klog.V(2).InfoS("ignoring labels and taints of NodeFeatureGroup outside the nfd-master namespace", "nodeName", nodeName, "nodeFeatureGroup", klog.KObj(nfg), "namespace", namespace)
+			}
+			continue
+		}
+		l := nfg.Spec.Labels
+		if !nfdfeatures.NFDFeatureGate.Enabled(nfdfeatures.DisableAutoPrefix) {
+			l = addNsToMapKeys(nfg.Spec.Labels, nfdv1alpha1.FeatureLabelNs)
+		}
+		for name, value := range l {
+			if old, ok := labels[name]; ok {
+				if old != value {
+					klog.V(2).InfoS("label already set, ignoring value from NodeFeatureGroup", "nodeName", nodeName, "nodeFeatureGroup", klog.KObj(nfg), "labelKey", name, "labelValue", value, "existingValue", old)
+				}
+				continue
+			}
+			labels[name] = value
+		}
+
+		for _, taint := range nfg.Spec.Taints {
+			if i := slices.IndexFunc(taints, func(t corev1.Taint) bool { return t.MatchTaint(&taint) }); i >= 0 {
+				if taints[i].Value != taint.Value {
+					klog.V(2).InfoS("taint already set, ignoring value from NodeFeatureGroup", "nodeName", nodeName, "nodeFeatureGroup", klog.KObj(nfg), "taint", taint.ToString(), "existingTaint", taints[i].ToString())
+				}
+				continue
+			}
+			taints = append(taints, taint)
+		}
+	}
+	return taints
+}
+
+// shadowRuleOutput returns the output of a rule as it would be applied on
+// the node, i.e. the same filtering is applied as for rules that are not in
+// shadow mode. Items that would be rejected are silently dropped.
+func (m *nfdMaster) shadowRuleOutput(ruleOut nodefeaturerule.RuleOutput, features *nfdv1alpha1.Features) *shadowOutput {
+	out := &shadowOutput{
+		labels:            make(map[string]string, len(ruleOut.Labels)),
+		extendedResources: make(map[string]string, len(ruleOut.ExtendedResources)),
+	}
+
+	l := ruleOut.Labels
+	e := ruleOut.ExtendedResources
+	a := ruleOut.Annotations
+	if !nfdfeatures.NFDFeatureGate.Enabled(nfdfeatures.DisableAutoPrefix) {
+		l = addNsToMapKeys(ruleOut.Labels, nfdv1alpha1.FeatureLabelNs)
+		e = addNsToMapKeys(ruleOut.ExtendedResources, nfdv1alpha1.ExtendedResourceNs)
+		a = addNsToMapKeys(ruleOut.Annotations, nfdv1alpha1.FeatureAnnotationNs)
+	}
+
+	if !m.config().Restrictions.DisableLabels {
+		for name, value := range l {
+			if value, err := m.filterFeatureLabel(name, value, features); err == nil {
+				out.labels[name] = value
+			}
+		}
+	}
+	if !m.config().Restrictions.DisableExtendedResources {
+		for name, value := range e {
+			if value, err := filterExtendedResource(name, value, features); err == nil {
+				out.extendedResources[name] = value
+			}
+		}
+	}
+	if !m.config().Restrictions.DisableAnnotations {
+		out.annotations = make(map[string]string, len(a))
+		for name, value := range a {
+			if err := validate.Annotation(name, value); err == nil {
+				out.annotations[name] = value
+			}
+		}
+	}
+	if m.config().EnableTaints {
+		for _, taint := range ruleOut.Taints {
+			if err := validate.Taint(&taint); err == nil {
+				out.taints = append(out.taints, taint)
+			}
+		}
+	}
+	return out
+}
+
+// nodeSelectedByRule returns true if the node matches the nodeSelector of the
+// NodeFeatureRule.
+func nodeSelectedByRule(nfr *nfdv1alpha1.NodeFeatureRule, node *corev1.Node) (bool, error) {
+	if nfr.Spec.NodeSelector == nil {
+		return true, nil
+	}
+	sel, err := metav1.LabelSelectorAsSelector(nfr.Spec.NodeSelector)
+	if err != nil {
+		return false, fmt.Errorf("invalid nodeSelector: %w", err)
+	}
+	return sel.Matches(k8sLabels.Set(node.Labels)), nil
+}
+
+// reportRuleConflicts reports conflicts between the outputs of rules via
+// logs, metrics and events. Only conflicts that were not present in the
+// previous evaluation of the node are reported, so that an ongoing conflict
+// is not reported again on every update of the node.
+func (m *nfdMaster) reportRuleConflicts(node *corev1.Node, conflicts []ruleConflict) {
+	keys := sets.New[string]()
+	for _, c := range conflicts {
+		keys.Insert(c.key())
+	}
+	newConflicts := m.nodeFeatures.setRuleConflicts(node.Name, keys)
+
+	for _, c := range conflicts {
+		if !newConflicts.Has(c.key()) {
+			klog.V(2).InfoS("conflicting NodeFeatureRule output", "nodeName", node.Name, "conflict", c.String())
+			continue
+		}
+		// Report each conflict once, even if several rules of the
+		// conflicting objects are involved
+		newConflicts.Delete(c.key())
+		klog.InfoS("conflicting NodeFeatureRule output", "nodeName", node.Name, "conflict", c.String())
+
+		// Report the conflict on the object(s) whose value was not applied
+		for _, o := range c.losers() {
+			nfrConflicts.WithLabelValues(o.nfr.Name).Inc()
+			if m.nodeEventRecorder != nil {
+				m.nodeEventRecorder.Eventf(o.nfr, node, corev1.EventTypeWarning, "RuleConflict", "EvaluateNodeFeatureRule", "node %s: %s", node.Name, c)
+			}
+		}
+	}
 }
 
 // updateNodeObject ensures the Kubernetes node object is up to date,
 // creating new labels and extended resources where necessary and removing
-// outdated ones. Also updates the corresponding annotations.
-func (m *nfdMaster) updateNodeObject(cli k8sclient.Interface, node *corev1.Node, labels Labels, featureAnnotations Annotations, extendedResources ExtendedResources, taints []corev1.Taint) error {
+// outdated ones. Also updates the corresponding annotations. Changes in
+// labels, taints and extended resources are reported as events on the node,
+// with owners specifying the NodeFeatureRules that produced them.
+func (m *nfdMaster) updateNodeObject(cli k8sclient.Interface, node *corev1.Node, labels Labels, featureAnnotations Annotations, extendedResources ExtendedResources, taints []corev1.Taint, owners map[string]ruleOutputOwner) error {
+	if m.config().NodeUpdateStrategy == NodeUpdateStrategyServerSideApply {
+		return m.applyNodeObject(cli, node, labels, featureAnnotations, extendedResources, taints, owners)
+	}
+
 	annotations := make(Annotations)
 
 	// Store names of labels in an annotation
@@ -1041,7 +1585,8 @@ func (m *nfdMaster) updateNodeObject(cli k8sclient.Interface, node *corev1.Node,
 	// Create JSON patches for changes in labels and annotations
 	oldLabels := stringToNsNames(node.Annotations[m.instanceAnnotation(nfdv1alpha1.FeatureLabelsAnnotation)], nfdv1alpha1.FeatureLabelNs)
 	oldAnnotations := stringToNsNames(node.Annotations[m.instanceAnnotation(nfdv1alpha1.FeatureAnnotationsTrackingAnnotation)], nfdv1alpha1.FeatureAnnotationNs)
-	patches := createPatches(sets.New(oldLabels...), node.Labels, labels, "/metadata/labels", m.config.Restrictions.AllowOverwrite)
+	patches := createPatches(sets.New(oldLabels...), node.Labels, labels, "/metadata/labels", m.config().Restrictions.AllowOverwrite)
+	changes := nodeChangesFromPatches(ruleOutputLabel, "/metadata/labels", patches)
 	oldAnnotations = append(oldAnnotations, []string{
 		m.instanceAnnotation(nfdv1alpha1.FeatureLabelsAnnotation),
 		m.instanceAnnotation(nfdv1alpha1.ExtendedResourceAnnotation),
@@ -1049,10 +1594,18 @@ func (m *nfdMaster) updateNodeObject(cli k8sclient.Interface, node *corev1.Node,
 		// Clean up deprecated/stale nfd version annotations
 		m.instanceAnnotation(nfdv1alpha1.MasterVersionAnnotation),
 		m.instanceAnnotation(nfdv1alpha1.WorkerVersionAnnotation)}...)
-	patches = append(patches, createPatches(sets.New(oldAnnotations...), node.Annotations, annotations, "/metadata/annotations", m.config.Restrictions.AllowOverwrite)...)
+	patches = append(patches, createPatches(sets.New(oldAnnotations...), node.Annotations, annotations, "/metadata/annotations", m.config().Restrictions.AllowOverwrite)...)
 
 	// patch node status with extended resource changes
 	statusPatches := m.createExtendedResourcePatches(node, extendedResources)
+
+	// Limit the rate of node updates
+	if len(patches) > 0 || len(statusPatches) > 0 {
+		if err := m.waitNodeUpdateLimiter(); err != nil {
+			return err
+		}
+	}
+
 	err := patchNodeStatus(cli, node.Name, statusPatches)
 	if err != nil {
 		return fmt.Errorf("error while patching extended resources: %w", err)
@@ -1071,15 +1624,29 @@ func (m *nfdMaster) updateNodeObject(cli k8sclient.Interface, node *corev1.Node,
 		klog.V(1).InfoS("no updates to node", "nodeName", node.Name)
 	}
 
+	changes = append(changes, nodeChangesFromPatches(ruleOutputExtendedResource, "/status/capacity", statusPatches)...)
+
 	// Set taints
-	err = m.setTaints(cli, taints, node)
-	if err != nil {
-		return err
-	}
+	taintChanges, err := m.setTaints(cli, taints, node)
+	changes = append(changes, taintChanges...)
+	m.recordNodeEvents(node, changes, owners)
 
 	return err
 }
 
+// waitNodeUpdateLimiter blocks until the node update rate limiter allows one
+// more update of a node object. Waiting is aborted when the updater pool is
+// stopped.
+func (m *nfdMaster) waitNodeUpdateLimiter() error {
+	if m.nodeUpdateLimiter == nil {
+		return nil
+	}
+	if err := m.nodeUpdateLimiter.Wait(m.updaterPool.context()); err != nil {
+		return fmt.Errorf("node update rate limiter failed: %w", err)
+	}
+	return nil
+}
+
 // createPatches is a generic helper that returns json patch operations to perform
 func createPatches(removeKeys sets.Set[string], oldItems map[string]string, newItems map[string]string, jsonPath string, overwrite bool) []utils.JsonPatch {
 	patches := []utils.JsonPatch{}
@@ -1146,6 +1713,15 @@ func (m *nfdMaster) createExtendedResourcePatches(n *corev1.Node, extendedResour
 
 // Parse configuration options
 func (m *nfdMaster) configure(filepath string, overrides string) error {
+	c, err := m.parseConfig(filepath, overrides)
+	if err != nil {
+		return err
+	}
+	return m.applyConfig(c)
+}
+
+// parseConfig reads and validates the configuration.
+func (m *nfdMaster) parseConfig(filepath string, overrides string) (*NFDConfig, error) {
 	// Create a new default config
 	c := newDefaultConfig()
 
@@ -1156,12 +1732,12 @@ func (m *nfdMaster) configure(filepath string, overrides string) error {
 			if os.IsNotExist(err) {
 				klog.InfoS("config file not found, using defaults", "path", filepath)
 			} else {
-				return fmt.Errorf("error reading config file: %w", err)
+				return nil, fmt.Errorf("error reading config file: %w", err)
 			}
 		} else {
 			err = yaml.Unmarshal(data, c)
 			if err != nil {
-				return fmt.Errorf("failed to parse config file: %w", err)
+				return nil, fmt.Errorf("failed to parse config file: %w", err)
 			}
 
 			klog.InfoS("configuration file parsed", "path", filepath)
@@ -1170,7 +1746,7 @@ func (m *nfdMaster) configure(filepath string, overrides string) error {
 
 	// Parse config overrides
 	if err := yaml.Unmarshal([]byte(overrides), c); err != nil {
-		return fmt.Errorf("failed to parse -options: %w", err)
+		return nil, fmt.Errorf("failed to parse -options: %w", err)
 	}
 	if m.args.Overrides.NoPublish != nil {
 		c.NoPublish = *m.args.Overrides.NoPublish
@@ -1198,22 +1774,118 @@ func (m *nfdMaster) configure(filepath string, overrides string) error {
 	}
 
 	if c.NfdApiParallelism <= 0 {
-		return fmt.Errorf("the maximum number of concurrent labelers should be a non-zero positive number")
+		return nil, fmt.Errorf("the maximum number of concurrent labelers should be a non-zero positive number")
+	}
+
+	switch c.RuleConflictPolicy {
+	case RuleConflictPolicyHighestPriorityWins, RuleConflictPolicyFirstWins, RuleConflictPolicyError:
+	default:
+		return nil, fmt.Errorf("invalid ruleConflictPolicy %q, must be one of %q, %q or %q", c.RuleConflictPolicy,
+			RuleConflictPolicyHighestPriorityWins, RuleConflictPolicyFirstWins, RuleConflictPolicyError)
+	}
+
+	if err := c.Safeguard.validate(); err != nil {
+		return nil, err
+	}
+	if err := c.LabelFlapDetection.validate(); err != nil {
+		return nil, err
+	}
+	switch c.NodeUpdateStrategy {
+	case NodeUpdateStrategyJSONPatch, NodeUpdateStrategyServerSideApply:
+	default:
+		return nil, fmt.Errorf("invalid nodeUpdateStrategy %q, must be one of %q or %q", c.NodeUpdateStrategy,
+			NodeUpdateStrategyJSONPatch, NodeUpdateStrategyServerSideApply)
+	}
+	if c.NodeUpdates.QPS < 0 {
+		return nil, fmt.Errorf("invalid nodeUpdates.qps %v, must not be negative", c.NodeUpdates.QPS)
+	}
+	if c.NodeUpdates.QPS > 0 && c.NodeUpdates.Burst <= 0 {
+		return nil, fmt.Errorf("invalid nodeUpdates.burst %d, must be greater than 0", c.NodeUpdates.Burst)
+	}
+	if c.NodeUpdates.CoalescePeriod.Duration < 0 {
+		return nil, fmt.Errorf("invalid nodeUpdates.coalescePeriod %v, must not be negative", c.NodeUpdates.CoalescePeriod.Duration)
 	}
 
-	m.config = c
+	return c, nil
+}
+
+// config returns the configuration in use.
+func (m *nfdMaster) config() *runtimeConfig {
+	return m.activeConfig.Load()
+}
 
+// applyConfig takes the given configuration into use.
+func (m *nfdMaster) applyConfig(c *NFDConfig) error {
 	if err := klogutils.MergeKlogConfiguration(m.args.Klog, c.Klog); err != nil {
 		return err
 	}
 
+	limit := rate.Inf
+	if c.NodeUpdates.QPS > 0 {
+		limit = rate.Limit(c.NodeUpdates.QPS)
+	}
+	if m.nodeUpdateLimiter == nil {
+		m.nodeUpdateLimiter = rate.NewLimiter(limit, c.NodeUpdates.Burst)
+	} else {
+		m.nodeUpdateLimiter.SetLimit(limit)
+		m.nodeUpdateLimiter.SetBurst(c.NodeUpdates.Burst)
+	}
+
 	// Pre-process DenyLabelNS into 2 lists: one for normal ns, and the other for wildcard ns
 	normalDeniedNs, wildcardDeniedNs := preProcessDeniedNamespaces(c.DenyLabelNs)
-	m.deniedNs.normal = normalDeniedNs
-	m.deniedNs.wildcard = wildcardDeniedNs
+	rc := &runtimeConfig{NFDConfig: c, deniedNs: deniedNs{normal: normalDeniedNs, wildcard: wildcardDeniedNs}}
+	if old := m.activeConfig.Load(); old != nil {
+		rc.generation = old.generation + 1
+	}
+	m.activeConfig.Store(rc)
+
+	klog.InfoS("configuration successfully updated", "configuration", utils.DelayedDumper(c))
+
+	return nil
+}
+
+// reloadConfig re-reads the configuration. The current configuration is kept
+// if the new one is invalid. Options that are only used at startup keep their
+// current value until nfd-master is restarted.
+func (m *nfdMaster) reloadConfig() error {
+	c, err := m.parseConfig(m.configFilePath, m.args.Options)
+	if err != nil {
+		configReloadFailures.Inc()
+		return err
+	}
 
-	klog.InfoS("configuration successfully updated", "configuration", utils.DelayedDumper(m.config))
+	old := m.config().NFDConfig
+	for name, changed := range map[string]bool{
+		"resyncPeriod":      c.ResyncPeriod != old.ResyncPeriod,
+		"leaderElection":    c.LeaderElection != old.LeaderElection,
+		"nfdApiParallelism": c.NfdApiParallelism != old.NfdApiParallelism,
+		"informerPageSize":  c.InformerPageSize != old.InformerPageSize,
+		"restrictions.nodeFeatureNamespaceSelector": !apiequality.Semantic.DeepEqual(c.Restrictions.NodeFeatureNamespaceSelector,
+			old.Restrictions.NodeFeatureNamespaceSelector),
+		"restrictions.nodeFeatureGroupNamespaceSelector": !apiequality.Semantic.DeepEqual(c.Restrictions.NodeFeatureGroupNamespaceSelector,
+			old.Restrictions.NodeFeatureGroupNamespaceSelector),
+	} {
+		if changed {
+			klog.InfoS("configuration option cannot be changed at run-time, restart nfd-master to apply it", "option", name)
+		}
+	}
+	c.ResyncPeriod = old.ResyncPeriod
+	c.LeaderElection = old.LeaderElection
+	c.NfdApiParallelism = old.NfdApiParallelism
+	c.InformerPageSize = old.InformerPageSize
+	c.Restrictions.NodeFeatureNamespaceSelector = old.Restrictions.NodeFeatureNamespaceSelector
+	c.Restrictions.NodeFeatureGroupNamespaceSelector = old.Restrictions.NodeFeatureGroupNamespaceSelector
 
+	if err := m.applyConfig(c); err != nil {
+		configReloadFailures.Inc()
+		return err
+	}
+	configReloads.Inc()
+
+	// Re-process all nodes with the new configuration
+	if m.nfdController != nil {
+		m.nfdController.updateAllNodes()
+	}
 	return nil
 }
 
@@ -1298,11 +1970,12 @@ func (m *nfdMaster) startNfdApiController() error {
 	}
 	klog.InfoS("starting the nfd api controller")
 	m.nfdController, err = newNfdController(kubeconfig, nfdApiControllerOptions{
-		ResyncPeriod:                 m.config.ResyncPeriod.Duration,
-		K8sClient:                    m.k8sClient,
-		NodeFeatureNamespaceSelector: m.config.Restrictions.NodeFeatureNamespaceSelector,
-		DisableNodeFeatureGroup:      !nfdfeatures.NFDFeatureGate.Enabled(nfdfeatures.NodeFeatureGroupAPI),
-		ListSize:                     m.config.InformerPageSize,
+		ResyncPeriod:                      m.config().ResyncPeriod.Duration,
+		K8sClient:                         m.k8sClient,
+		NodeFeatureNamespaceSelector:      m.config().Restrictions.NodeFeatureNamespaceSelector,
+		NodeFeatureGroupNamespaceSelector: m.config().Restrictions.NodeFeatureGroupNamespaceSelector,
+		DisableNodeFeatureGroup:           !nfdfeatures.NFDFeatureGate.Enabled(nfdfeatures.NodeFeatureGroupAPI),
+		ListSize:                          m.config().InformerPageSize,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to initialize CRD controller: %w", err)
@@ -1326,9 +1999,9 @@ func (m *nfdMaster) startLeaderElectionHandler() {
 	config := leaderelection.LeaderElectionConfig{
 		Lock: lock,
 		// make it configurable?
-		LeaseDuration: m.config.LeaderElection.LeaseDuration.Duration,
-		RetryPeriod:   m.config.LeaderElection.RetryPeriod.Duration,
-		RenewDeadline: m.config.LeaderElection.RenewDeadline.Duration,
+		LeaseDuration: m.config().LeaderElection.LeaseDuration.Duration,
+		RetryPeriod:   m.config().LeaderElection.RetryPeriod.Duration,
+		RenewDeadline: m.config().LeaderElection.RenewDeadline.Duration,
 		Callbacks: leaderelection.LeaderCallbacks{
 			OnStartedLeading: func(_ context.Context) {
 				m.isLeader = true
@@ -1368,7 +2041,7 @@ func (m *nfdMaster) filterFeatureAnnotations(annotations map[string]string) map[
 		outAnnotations[annotation] = value
 	}
 
-	if len(outAnnotations) > 0 && m.config.Restrictions.DisableAnnotations {
+	if len(outAnnotations) > 0 && m.config().Restrictions.DisableAnnotations {
 		klog.V(2).InfoS("node annotations are disabled in configuration (restrictions.disableAnnotations=true)")
 		outAnnotations = map[string]string{}
 	}
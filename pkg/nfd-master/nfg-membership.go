@@ -0,0 +1,311 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdmaster
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+)
+
+// maxNodeFeatureGroupNodeDetails is the maximum number of member nodes whose
+// matched rules and vars are reported in the status of a NodeFeatureGroup.
+// The remaining nodes are listed with their name and join time only.
+const maxNodeFeatureGroupNodeDetails = 100
+
+// nfgNodeResult is the result of evaluating a NodeFeatureGroup against one
+// node.
+type nfgNodeResult struct {
+	// matchedRules contains the names of the matching rules, the node is a
+	// member of the group if any rule matched
+	matchedRules []string
+	vars         map[string]string
+	err          string
+}
+
+func (r nfgNodeResult) isMember() bool {
+	return len(r.matchedRules) > 0
+}
+
+// nfgMember describes one member node of a NodeFeatureGroup.
+type nfgMember struct {
+	matchedRules []string
+	vars         map[string]string
+	joinTime     time.Time
+}
+
+// nfgMembership holds the member nodes of one NodeFeatureGroup.
+type nfgMembership struct {
+	// generation of the NodeFeatureGroup object the membership was
+	// evaluated for
+	generation int64
+	// complete is true when the group has been evaluated against all nodes
+	complete bool
+	nodes    map[string]*nfgMember
+	// errors contains the evaluation errors per node
+	errors map[string]string
+	// pending holds per-node results received while the group is being
+	// evaluated against all nodes. They override the results of the full
+	// evaluation as they may be based on more recent features.
+	pending map[string]nfgNodeResult
+	// prevNodes holds the members of the previous generation, used to
+	// retain the join time of nodes that stay in the group
+	prevNodes map[string]*nfgMember
+}
+
+// set stores the result of one node. Returns true if the membership of the
+// node changed.
+func (g *nfgMembership) set(nodeName string, res nfgNodeResult, now time.Time) bool {
+	changed := false
+	if res.err != g.errors[nodeName] {
+		changed = true
+		if res.err == "" {
+			delete(g.errors, nodeName)
+		} else {
+			g.errors[nodeName] = res.err
+		}
+	}
+
+	old, wasMember := g.nodes[nodeName]
+	if !res.isMember() {
+		delete(g.nodes, nodeName)
+		return changed || wasMember
+	}
+
+	// Timestamps are serialized with a precision of one second
+	m := &nfgMember{matchedRules: res.matchedRules, vars: res.vars, joinTime: now.Truncate(time.Second)}
+	switch {
+	case wasMember:
+		m.joinTime = old.joinTime
+		changed = changed || !slices.Equal(old.matchedRules, m.matchedRules) || !maps.Equal(old.vars, m.vars)
+	case g.prevNodes[nodeName] != nil:
+		m.joinTime = g.prevNodes[nodeName].joinTime
+		changed = true
+	default:
+		changed = true
+	}
+	g.nodes[nodeName] = m
+	return changed
+}
+
+// nfgMembershipTracker keeps track of the member nodes of NodeFeatureGroups,
+// making it possible to update the membership incrementally, one node at a
+// time, when the features of a node change. The groups are keyed by
+// <namespace>/<name>.
+type nfgMembershipTracker struct {
+	sync.Mutex
+	groups map[string]*nfgMembership
+}
+
+func nfgKey(nfg *nfdv1alpha1.NodeFeatureGroup) string {
+	return cache.MetaObjectToName(nfg).String()
+}
+
+func newNfgMembershipTracker() *nfgMembershipTracker {
+	return &nfgMembershipTracker{groups: make(map[string]*nfgMembership)}
+}
+
+// beginEvaluation marks the start of evaluating a NodeFeatureGroup against
+// all nodes. If the group is not tracked yet, e.g. after nfd-master was
+// restarted, the join times of the existing members are taken from the status
+// of the NodeFeatureGroup object.
+func (t *nfgMembershipTracker) beginEvaluation(nfg *nfdv1alpha1.NodeFeatureGroup) {
+	t.Lock()
+	defer t.Unlock()
+
+	g := &nfgMembership{
+		generation: nfg.Generation,
+		nodes:      make(map[string]*nfgMember),
+		errors:     make(map[string]string),
+		pending:    make(map[string]nfgNodeResult),
+	}
+	if old, ok := t.groups[nfgKey(nfg)]; ok {
+		g.prevNodes = old.nodes
+		if !old.complete {
+			g.prevNodes = old.prevNodes
+		}
+	} else {
+		g.prevNodes = make(map[string]*nfgMember, len(nfg.Status.Nodes))
+		for _, n := range nfg.Status.Nodes {
+			if !n.JoinTime.IsZero() {
+				g.prevNodes[n.Name] = &nfgMember{joinTime: n.JoinTime.Time}
+			}
+		}
+	}
+	t.groups[nfgKey(nfg)] = g
+}
+
+// finishEvaluation stores the results of evaluating a NodeFeatureGroup
+// against all nodes. Results of a superseded evaluation are dropped.
+func (t *nfgMembershipTracker) finishEvaluation(nfg *nfdv1alpha1.NodeFeatureGroup, results map[string]nfgNodeResult) {
+	t.Lock()
+	defer t.Unlock()
+
+	g, ok := t.groups[nfgKey(nfg)]
+	if !ok || g.generation != nfg.Generation || g.complete {
+		return
+	}
+	maps.Copy(results, g.pending)
+
+	now := time.Now()
+	for nodeName, res := range results {
+		g.set(nodeName, res, now)
+	}
+	g.pending = nil
+	g.prevNodes = nil
+	g.complete = true
+}
+
+// updateNode updates the membership of one node in a NodeFeatureGroup.
+// Returns true if the membership changed. Updates are ignored for
+// NodeFeatureGroups that have not been evaluated for their current
+// generation, as a full evaluation is pending for them anyway.
+func (t *nfgMembershipTracker) updateNode(nfg *nfdv1alpha1.NodeFeatureGroup, nodeName string, res nfgNodeResult) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	g, ok := t.groups[nfgKey(nfg)]
+	if !ok || g.generation != nfg.Generation {
+		return false
+	}
+	if !g.complete {
+		g.pending[nodeName] = res
+		return false
+	}
+	return g.set(nodeName, res, time.Now())
+}
+
+// removeNode drops a node from all NodeFeatureGroups. Returns the keys of the
+// groups whose membership changed.
+func (t *nfgMembershipTracker) removeNode(nodeName string) []string {
+	t.Lock()
+	defer t.Unlock()
+
+	var changed []string
+	for key, g := range t.groups {
+		if !g.complete {
+			g.pending[nodeName] = nfgNodeResult{}
+		} else if g.set(nodeName, nfgNodeResult{}, time.Now()) {
+			changed = append(changed, key)
+		}
+	}
+	return changed
+}
+
+// status calculates the status of a NodeFeatureGroup from the tracked
+// membership. Returns false if the group has not been evaluated for its
+// current generation.
+func (t *nfgMembershipTracker) status(nfg *nfdv1alpha1.NodeFeatureGroup) (nfdv1alpha1.NodeFeatureGroupStatus, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	g, ok := t.groups[nfgKey(nfg)]
+	if !ok || g.generation != nfg.Generation || !g.complete {
+		return nfdv1alpha1.NodeFeatureGroupStatus{}, false
+	}
+
+	status := nfdv1alpha1.NodeFeatureGroupStatus{
+		Conditions: nfg.Status.DeepCopy().Conditions,
+		NodeCount:  int32(len(g.nodes)),
+		Nodes:      make([]nfdv1alpha1.FeatureGroupNode, 0, len(g.nodes)),
+	}
+	for i, nodeName := range slices.Sorted(maps.Keys(g.nodes)) {
+		m := g.nodes[nodeName]
+		n := nfdv1alpha1.FeatureGroupNode{
+			Name:     nodeName,
+			JoinTime: metav1.NewTime(m.joinTime),
+		}
+		if i < maxNodeFeatureGroupNodeDetails {
+			n.MatchedRules = slices.Clone(m.matchedRules)
+			n.Vars = maps.Clone(m.vars)
+		}
+		status.Nodes = append(status.Nodes, n)
+	}
+
+	if len(g.errors) == 0 {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               nfdv1alpha1.NodeFeatureGroupConditionValid,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: nfg.Generation,
+			Reason:             "EvaluationSucceeded",
+			Message:            "all rules were evaluated without errors",
+		})
+	} else {
+		nodeNames := slices.Sorted(maps.Keys(g.errors))
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               nfdv1alpha1.NodeFeatureGroupConditionValid,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: nfg.Generation,
+			Reason:             "EvaluationFailed",
+			Message:            fmt.Sprintf("failed to evaluate rules on %d node(s), node %s: %s", len(nodeNames), nodeNames[0], g.errors[nodeNames[0]]),
+		})
+	}
+
+	if status.NodeCount > 0 {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               nfdv1alpha1.NodeFeatureGroupConditionPopulated,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: nfg.Generation,
+			Reason:             "NodesMatched",
+			Message:            fmt.Sprintf("%d node(s) in the group", status.NodeCount),
+		})
+	} else {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               nfdv1alpha1.NodeFeatureGroupConditionPopulated,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: nfg.Generation,
+			Reason:             "NoMatchingNodes",
+			Message:            "none of the nodes matched",
+		})
+	}
+
+	if len(g.nodes) > maxNodeFeatureGroupNodeDetails {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               nfdv1alpha1.NodeFeatureGroupConditionTruncated,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: nfg.Generation,
+			Reason:             "NodeDetailsTruncated",
+			Message:            fmt.Sprintf("matched rules and vars are listed for the first %d of %d node(s)", maxNodeFeatureGroupNodeDetails, len(g.nodes)),
+		})
+	} else {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               nfdv1alpha1.NodeFeatureGroupConditionTruncated,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: nfg.Generation,
+			Reason:             "AllNodeDetailsListed",
+			Message:            "matched rules and vars are listed for all nodes",
+		})
+	}
+
+	return status, true
+}
+
+// forget drops a NodeFeatureGroup that no longer exists.
+func (t *nfgMembershipTracker) forget(key string) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.groups, key)
+}
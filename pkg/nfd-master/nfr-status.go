@@ -0,0 +1,447 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdmaster
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sLabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	nfdclientset "sigs.k8s.io/node-feature-discovery/api/generated/clientset/versioned"
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+	"sigs.k8s.io/node-feature-discovery/pkg/apis/nfd/nodefeaturerule"
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+)
+
+// nfrStatusUpdateInterval is the minimum interval between consecutive
+// updates of the status of NodeFeatureRule objects.
+const nfrStatusUpdateInterval = 10 * time.Second
+
+// maxShadowOutputItems is the maximum number of items of each output type
+// reported in the shadow output of one rule.
+const maxShadowOutputItems = 50
+
+// ruleResult is the result of evaluating one rule against one node.
+type ruleResult struct {
+	matched bool
+	err     string
+	errTime time.Time
+	// shadowOutput is the output the rule would produce on the node, only
+	// recorded for NodeFeatureRules in shadow mode
+	shadowOutput *shadowOutput
+}
+
+// shadowOutput is the output of a rule in shadow mode on one node.
+type shadowOutput struct {
+	labels            map[string]string
+	annotations       map[string]string
+	extendedResources map[string]string
+	taints            []corev1.Taint
+}
+
+// nfrResults holds the per-node evaluation results of one NodeFeatureRule
+// object.
+type nfrResults struct {
+	// generation of the NodeFeatureRule object the results were recorded for
+	generation int64
+	// nodes maps node names to per-rule results
+	nodes map[string]map[string]ruleResult
+}
+
+// nfrStatusTracker keeps track of the results of NodeFeatureRule evaluation
+// so that they can be reported in the status of the NodeFeatureRule objects.
+type nfrStatusTracker struct {
+	sync.Mutex
+	rules map[string]*nfrResults
+	dirty bool
+}
+
+func newNfrStatusTracker() *nfrStatusTracker {
+	return &nfrStatusTracker{rules: make(map[string]*nfrResults)}
+}
+
+// record stores the results of evaluating a NodeFeatureRule against a node.
+// Results recorded for earlier generations of the object are dropped.
+func (t *nfrStatusTracker) record(nfr *nfdv1alpha1.NodeFeatureRule, nodeName string, results map[string]ruleResult) {
+	t.Lock()
+	defer t.Unlock()
+
+	r, ok := t.rules[nfr.Name]
+	if !ok || r.generation != nfr.Generation {
+		r = &nfrResults{generation: nfr.Generation, nodes: make(map[string]map[string]ruleResult)}
+		t.rules[nfr.Name] = r
+	}
+	// Retain the timestamp of an unchanged error to avoid needless status updates
+	for name, res := range results {
+		if old, ok := r.nodes[nodeName][name]; ok && res.err != "" && old.err == res.err {
+			res.errTime = old.errTime
+			results[name] = res
+		}
+	}
+	r.nodes[nodeName] = results
+	t.dirty = true
+}
+
+// removeNodeResults drops the results of one NodeFeatureRule recorded for a
+// node, e.g. when the node is no longer selected by the NodeFeatureRule.
+func (t *nfrStatusTracker) removeNodeResults(nfr *nfdv1alpha1.NodeFeatureRule, nodeName string) {
+	t.Lock()
+	defer t.Unlock()
+
+	r, ok := t.rules[nfr.Name]
+	if !ok {
+		return
+	}
+	if r.generation != nfr.Generation {
+		// Results of an older generation are stale anyway
+		t.rules[nfr.Name] = &nfrResults{generation: nfr.Generation, nodes: make(map[string]map[string]ruleResult)}
+		t.dirty = true
+	} else if _, ok := r.nodes[nodeName]; ok {
+		delete(r.nodes, nodeName)
+		t.dirty = true
+	}
+}
+
+// removeNode drops all results recorded for a node.
+func (t *nfrStatusTracker) removeNode(nodeName string) {
+	t.Lock()
+	defer t.Unlock()
+
+	for _, r := range t.rules {
+		if _, ok := r.nodes[nodeName]; ok {
+			delete(r.nodes, nodeName)
+			t.dirty = true
+		}
+	}
+}
+
+// pruneNodes drops the results of all nodes not found in the given set of
+// node names.
+func (t *nfrStatusTracker) pruneNodes(nodeNames map[string]struct{}) {
+	t.Lock()
+	defer t.Unlock()
+
+	for _, r := range t.rules {
+		for n := range r.nodes {
+			if _, ok := nodeNames[n]; !ok {
+				delete(r.nodes, n)
+				t.dirty = true
+			}
+		}
+	}
+}
+
+// status calculates the status of a NodeFeatureRule object from the recorded
+// results. Returns false if no results have been recorded for the current
+// generation of the object.
+func (t *nfrStatusTracker) status(nfr *nfdv1alpha1.NodeFeatureRule) (nfdv1alpha1.NodeFeatureRuleStatus, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	r, ok := t.rules[nfr.Name]
+	if !ok || r.generation != nfr.Generation {
+		return nfdv1alpha1.NodeFeatureRuleStatus{}, false
+	}
+
+	status := nfdv1alpha1.NodeFeatureRuleStatus{
+		Conditions: nfr.Status.DeepCopy().Conditions,
+		Rules:      make([]nfdv1alpha1.RuleStatus, len(nfr.Spec.Rules)),
+	}
+	for i, rule := range nfr.Spec.Rules {
+		status.Rules[i].Name = rule.Name
+	}
+
+	var shadowOutputs []shadowOutputCounter
+	if nfr.Spec.Shadow {
+		shadowOutputs = make([]shadowOutputCounter, len(status.Rules))
+	}
+
+	failedRules := []string{}
+	for nodeName, results := range r.nodes {
+		nodeMatched := false
+		for i := range status.Rules {
+			rs := &status.Rules[i]
+			res, ok := results[rs.Name]
+			if !ok {
+				continue
+			}
+			if res.matched {
+				rs.MatchedNodes++
+				nodeMatched = true
+			} else {
+				rs.UnmatchedNodes++
+			}
+			if shadowOutputs != nil && res.shadowOutput != nil {
+				shadowOutputs[i].add(res.shadowOutput)
+			}
+			if res.err != "" && (rs.LastError == nil || res.errTime.After(rs.LastError.Time.Time) ||
+				(res.errTime.Equal(rs.LastError.Time.Time) && nodeName < rs.LastError.NodeName)) {
+				rs.LastError = &nfdv1alpha1.RuleError{
+					Message:  res.err,
+					NodeName: nodeName,
+					Time:     metav1.NewTime(res.errTime),
+				}
+			}
+		}
+		if nodeMatched {
+			status.MatchedNodes++
+		} else {
+			status.UnmatchedNodes++
+		}
+	}
+	for i, rs := range status.Rules {
+		if rs.LastError != nil {
+			failedRules = append(failedRules, rs.Name)
+		}
+		if shadowOutputs != nil {
+			status.Rules[i].ShadowOutput = shadowOutputs[i].ruleShadowOutput()
+		}
+	}
+	sort.Strings(failedRules)
+
+	if len(failedRules) == 0 {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               nfdv1alpha1.NodeFeatureRuleConditionValid,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: nfr.Generation,
+			Reason:             "EvaluationSucceeded",
+			Message:            "all rules were evaluated without errors",
+		})
+	} else {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               nfdv1alpha1.NodeFeatureRuleConditionValid,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: nfr.Generation,
+			Reason:             "EvaluationFailed",
+			Message:            fmt.Sprintf("failed to evaluate rules %v, see status.rules for details", failedRules),
+		})
+	}
+
+	if nfr.Spec.Shadow {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               nfdv1alpha1.NodeFeatureRuleConditionApplied,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: nfr.Generation,
+			Reason:             "ShadowMode",
+			Message:            fmt.Sprintf("shadow mode, would match %d of %d nodes", status.MatchedNodes, status.MatchedNodes+status.UnmatchedNodes),
+		})
+	} else if status.MatchedNodes > 0 {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               nfdv1alpha1.NodeFeatureRuleConditionApplied,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: nfr.Generation,
+			Reason:             "NodesMatched",
+			Message:            fmt.Sprintf("matched %d of %d nodes", status.MatchedNodes, status.MatchedNodes+status.UnmatchedNodes),
+		})
+	} else {
+		msg := fmt.Sprintf("none of the %d nodes matched", status.UnmatchedNodes)
+		if status.UnmatchedNodes == 0 {
+			msg = "no nodes were evaluated"
+		}
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               nfdv1alpha1.NodeFeatureRuleConditionApplied,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: nfr.Generation,
+			Reason:             "NoMatchingNodes",
+			Message:            msg,
+		})
+	}
+
+	return status, true
+}
+
+// shadowOutputCounter counts the nodes per distinct output item of a rule in
+// shadow mode.
+type shadowOutputCounter struct {
+	labels            map[[2]string]int32
+	annotations       map[[2]string]int32
+	extendedResources map[[2]string]int32
+	taints            map[[2]string]int32
+}
+
+func (c *shadowOutputCounter) add(out *shadowOutput) {
+	countMap := func(counts *map[[2]string]int32, items map[string]string) {
+		if *counts == nil {
+			*counts = make(map[[2]string]int32)
+		}
+		for name, value := range items {
+			(*counts)[[2]string{name, value}]++
+		}
+	}
+	countMap(&c.labels, out.labels)
+	countMap(&c.annotations, out.annotations)
+	countMap(&c.extendedResources, out.extendedResources)
+
+	// A taint is identified by its key and effect
+	taints := make(map[string]string, len(out.taints))
+	for _, t := range out.taints {
+		taints[t.Key+":"+string(t.Effect)] = t.Value
+	}
+	countMap(&c.taints, taints)
+}
+
+func (c *shadowOutputCounter) ruleShadowOutput() *nfdv1alpha1.RuleShadowOutput {
+	out := &nfdv1alpha1.RuleShadowOutput{}
+	toItems := func(counts map[[2]string]int32) []nfdv1alpha1.ShadowOutputItem {
+		if len(counts) == 0 {
+			return nil
+		}
+		items := make([]nfdv1alpha1.ShadowOutputItem, 0, len(counts))
+		for k, n := range counts {
+			items = append(items, nfdv1alpha1.ShadowOutputItem{Name: k[0], Value: k[1], Nodes: n})
+		}
+		// Most common items first
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].Nodes != items[j].Nodes {
+				return items[i].Nodes > items[j].Nodes
+			}
+			if items[i].Name != items[j].Name {
+				return items[i].Name < items[j].Name
+			}
+			return items[i].Value < items[j].Value
+		})
+		if len(items) > maxShadowOutputItems {
+			items = items[:maxShadowOutputItems]
+			out.Truncated = true
+		}
+		return items
+	}
+	out.Labels = toItems(c.labels)
+	out.Annotations = toItems(c.annotations)
+	out.ExtendedResources = toItems(c.extendedResources)
+	out.Taints = toItems(c.taints)
+	return out
+}
+
+// takeDirty returns true if new results have been recorded since the previous
+// call and clears the flag.
+func (t *nfrStatusTracker) takeDirty() bool {
+	t.Lock()
+	defer t.Unlock()
+
+	dirty := t.dirty
+	t.dirty = false
+	return dirty
+}
+
+// setDirty forces the next status update round to update all objects.
+func (t *nfrStatusTracker) setDirty() {
+	t.Lock()
+	defer t.Unlock()
+	t.dirty = true
+}
+
+// forget drops the results of NodeFeatureRule objects that no longer exist.
+// Returns the names of the dropped objects.
+func (t *nfrStatusTracker) forget(existing map[string]struct{}) []string {
+	t.Lock()
+	defer t.Unlock()
+
+	var forgotten []string
+	for name := range t.rules {
+		if _, ok := existing[name]; !ok {
+			delete(t.rules, name)
+			forgotten = append(forgotten, name)
+		}
+	}
+	return forgotten
+}
+
+// nfrStatusUpdater periodically updates the status of NodeFeatureRule
+// objects, rate-limiting the updates to one round per
+// nfrStatusUpdateInterval.
+func (m *nfdMaster) nfrStatusUpdater() {
+	ticker := time.NewTicker(nfrStatusUpdateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !m.isLeader || !m.nfrStatus.takeDirty() {
+				continue
+			}
+			if err := m.updateNodeFeatureRuleStatuses(m.nfdClient); err != nil {
+				klog.ErrorS(err, "failed to update NodeFeatureRule status")
+				// Try again on the next round
+				m.nfrStatus.setDirty()
+			}
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// updateNodeFeatureRuleStatuses updates the status of all NodeFeatureRule
+// objects based on the recorded evaluation results.
+func (m *nfdMaster) updateNodeFeatureRuleStatuses(cli nfdclientset.Interface) error {
+	nfrs, err := m.nfdController.ruleLister.List(k8sLabels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list NodeFeatureRule objects: %w", err)
+	}
+
+	existing := make(map[string]struct{}, len(nfrs))
+	var errs []error
+	for _, nfr := range nfrs {
+		existing[nfr.Name] = struct{}{}
+
+		status, ok := m.nfrStatus.status(nfr)
+		if ok && nfr.Spec.Shadow {
+			nfrShadowMatchedNodes.WithLabelValues(nfr.Name).Set(float64(status.MatchedNodes))
+		} else if !nfr.Spec.Shadow {
+			nfrShadowMatchedNodes.DeleteLabelValues(nfr.Name)
+		}
+		if !ok || apiequality.Semantic.DeepEqual(nfr.Status, status) {
+			continue
+		}
+
+		nfrUpdated := nfr.DeepCopy()
+		nfrUpdated.Status = status
+		klog.V(2).InfoS("updating NodeFeatureRule status", "nodefeaturerule", klog.KObj(nfr))
+		nfrUpdated, err = cli.NfdV1alpha1().NodeFeatureRules().UpdateStatus(context.TODO(), nfrUpdated, metav1.UpdateOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to update status of NodeFeatureRule %q: %w", nfr.Name, err))
+			continue
+		}
+		klog.V(4).InfoS("NodeFeatureRule status updated", "nodefeaturerule", utils.DelayedDumper(nfrUpdated))
+	}
+	for _, name := range m.nfrStatus.forget(existing) {
+		nfrShadowMatchedNodes.DeleteLabelValues(name)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d NodeFeatureRule status update(s) failed, first error: %w", len(errs), errs[0])
+	}
+	return nil
+}
+
+// ruleMatched returns true if the rule matched, i.e. its output was applied.
+// Rules without any matchers always match.
+func ruleMatched(rule *nfdv1alpha1.Rule, out nodefeaturerule.RuleOutput) bool {
+	if len(rule.MatchFeatures) == 0 && len(rule.MatchAny) == 0 && rule.MatchExpression == nil && rule.CELExpression == "" {
+		return true
+	}
+	return out.MatchStatus != nil && out.MatchStatus.IsMatch
+}
@@ -0,0 +1,382 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdmaster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1apply "k8s.io/client-go/applyconfigurations/core/v1"
+	k8sclient "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+)
+
+// NodeUpdateStrategy specifies how nfd-master updates node objects.
+type NodeUpdateStrategy string
+
+const (
+	// NodeUpdateStrategyJSONPatch updates nodes with JSON patches, keeping
+	// track of the labels, annotations and extended resources managed by
+	// nfd-master in node annotations.
+	NodeUpdateStrategyJSONPatch NodeUpdateStrategy = "jsonPatch"
+	// NodeUpdateStrategyServerSideApply updates nodes with server-side
+	// apply, ownership of labels, annotations and extended resources being
+	// tracked in the managedFields of the node.
+	NodeUpdateStrategyServerSideApply NodeUpdateStrategy = "serverSideApply"
+)
+
+const (
+	// nodeFieldManager is the field manager used in server-side apply
+	nodeFieldManager = "nfd-master"
+
+	// Field paths used in server-side apply conflicts
+	labelsFieldPath      = ".metadata.labels."
+	annotationsFieldPath = ".metadata.annotations."
+	capacityFieldPath    = ".status.capacity."
+)
+
+// fieldManager returns the name of the field manager of this nfd-master
+// instance.
+func (m *nfdMaster) fieldManager() string {
+	if m.args.Instance == "" {
+		return nodeFieldManager
+	}
+	return nodeFieldManager + "-" + m.args.Instance
+}
+
+// managedNodeFields holds the keys of the labels, annotations and capacity of
+// a node owned by a field manager.
+type managedNodeFields struct {
+	labels      sets.Set[string]
+	annotations sets.Set[string]
+	capacity    sets.Set[string]
+}
+
+// getManagedNodeFields returns the labels, annotations and capacity of the
+// node applied by the given field manager.
+func getManagedNodeFields(node *corev1.Node, manager string) managedNodeFields {
+	owned := managedNodeFields{
+		labels:      sets.New[string](),
+		annotations: sets.New[string](),
+		capacity:    sets.New[string](),
+	}
+	for _, mf := range node.ManagedFields {
+		if mf.Manager != manager || mf.Operation != metav1.ManagedFieldsOperationApply || mf.FieldsV1 == nil {
+			continue
+		}
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal(mf.FieldsV1.Raw, &fields); err != nil {
+			klog.ErrorS(err, "failed to parse managed fields", "nodeName", node.Name, "manager", manager)
+			continue
+		}
+		owned.labels.Insert(fieldKeys(fields, "f:metadata", "f:labels")...)
+		owned.annotations.Insert(fieldKeys(fields, "f:metadata", "f:annotations")...)
+		owned.capacity.Insert(fieldKeys(fields, "f:status", "f:capacity")...)
+	}
+	return owned
+}
+
+// fieldKeys returns the map keys at the given path of FieldsV1 managed fields.
+func fieldKeys(fields map[string]interface{}, path ...string) []string {
+	for _, p := range path {
+		f, ok := fields[p].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		fields = f
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if strings.HasPrefix(k, "f:") {
+			keys = append(keys, strings.TrimPrefix(k, "f:"))
+		}
+	}
+	return keys
+}
+
+// nfdManagedLabels returns the names of the labels of the node that are
+// managed by nfd-master.
+func (m *nfdMaster) nfdManagedLabels(node *corev1.Node) []string {
+	if m.config().NodeUpdateStrategy == NodeUpdateStrategyServerSideApply {
+		return sets.List(getManagedNodeFields(node, m.fieldManager()).labels)
+	}
+	return stringToNsNames(node.Annotations[m.instanceAnnotation(nfdv1alpha1.FeatureLabelsAnnotation)], nfdv1alpha1.FeatureLabelNs)
+}
+
+// fieldConflicts returns the conflicting keys under the given field path,
+// and the conflict messages, from a server-side apply conflict error.
+func fieldConflicts(err error, fieldPath string) map[string]string {
+	var status apierrors.APIStatus
+	if !apierrors.IsConflict(err) || !errors.As(err, &status) || status.Status().Details == nil {
+		return nil
+	}
+	conflicts := make(map[string]string)
+	for _, c := range status.Status().Details.Causes {
+		if c.Type == metav1.CauseTypeFieldManagerConflict && strings.HasPrefix(c.Field, fieldPath) {
+			conflicts[strings.TrimPrefix(c.Field, fieldPath)] = c.Message
+		}
+	}
+	return conflicts
+}
+
+// applyNodeObject is the server-side apply counterpart of updateNodeObject.
+// Labels, annotations and extended resources no longer produced are removed
+// by the API server as they are not included in the applied configuration.
+// Taints are managed with JSON patches also in this mode as the taints of a
+// node are an atomic list. Conflicts with other field managers are reported
+// and either overwritten or skipped, depending on AllowOverwrite.
+func (m *nfdMaster) applyNodeObject(cli k8sclient.Interface, node *corev1.Node, labels Labels, annotations Annotations, extendedResources ExtendedResources, taints []corev1.Taint, owners map[string]ruleOutputOwner) error {
+	owned := getManagedNodeFields(node, m.fieldManager())
+
+	// Clean up labels et al. managed in JSON patch mode, and the
+	// annotations tracking them
+	if err := m.removeLegacyNodeFields(cli, node, labels, annotations, extendedResources); err != nil {
+		return err
+	}
+
+	labelPatches := createPatches(owned.labels, node.Labels, labels, "/metadata/labels", true)
+	annotationPatches := createPatches(owned.annotations, node.Annotations, annotations, "/metadata/annotations", true)
+	changes := nodeChangesFromPatches(ruleOutputLabel, "/metadata/labels", labelPatches)
+
+	// Apply also if some of the items are not owned yet, e.g. after
+	// migrating from JSON patch mode
+	metadataChanged := len(labelPatches) > 0 || len(annotationPatches) > 0 ||
+		!owned.labels.IsSuperset(sets.KeySet(labels)) || !owned.annotations.IsSuperset(sets.KeySet(annotations))
+	statusPatches := m.createCapacityPatches(node, owned.capacity, extendedResources)
+	statusChanged := len(statusPatches) > 0 || !owned.capacity.IsSuperset(sets.KeySet(extendedResources))
+
+	// Limit the rate of node updates
+	if metadataChanged || statusChanged {
+		if err := m.waitNodeUpdateLimiter(); err != nil {
+			return err
+		}
+	}
+
+	statusChanges := nodeChangesFromPatches(ruleOutputExtendedResource, "/status/capacity", statusPatches)
+	if statusChanged {
+		skipped, err := m.applyNodeStatus(cli, node, owned.capacity, extendedResources)
+		if err != nil {
+			return fmt.Errorf("error while applying extended resources: %w", err)
+		}
+		statusChanges = slices.DeleteFunc(statusChanges, func(c nodeChange) bool { return skipped.Has(c.name) })
+	}
+	if metadataChanged {
+		skipped, err := m.applyNodeMetadata(cli, node, labels, annotations)
+		if err != nil {
+			return fmt.Errorf("error while applying node object: %w", err)
+		}
+		changes = slices.DeleteFunc(changes, func(c nodeChange) bool { return skipped.Has(c.name) })
+	}
+
+	if metadataChanged || statusChanged {
+		nodeUpdates.Inc()
+		klog.InfoS("node updated", "nodeName", node.Name)
+	} else {
+		klog.V(1).InfoS("no updates to node", "nodeName", node.Name)
+	}
+
+	changes = append(changes, statusChanges...)
+
+	// Set taints
+	taintChanges, err := m.setTaints(cli, taints, node)
+	changes = append(changes, taintChanges...)
+	m.recordNodeEvents(node, changes, owners)
+
+	return err
+}
+
+// applyNodeMetadata applies the labels and annotations of a node. Returns the
+// labels skipped because of conflicts with other field managers.
+func (m *nfdMaster) applyNodeMetadata(cli k8sclient.Interface, node *corev1.Node, labels Labels, annotations Annotations) (sets.Set[string], error) {
+	skipped := sets.New[string]()
+	apply := func(force bool) error {
+		cfg := corev1apply.Node(node.Name).WithLabels(labels).WithAnnotations(annotations)
+		_, err := cli.CoreV1().Nodes().Apply(context.TODO(), cfg, metav1.ApplyOptions{FieldManager: m.fieldManager(), Force: force})
+		return err
+	}
+
+	err := apply(false)
+	labelConflicts := fieldConflicts(err, labelsFieldPath)
+	annotationConflicts := fieldConflicts(err, annotationsFieldPath)
+	if len(labelConflicts) == 0 && len(annotationConflicts) == 0 {
+		return skipped, err
+	}
+
+	m.reportFieldConflicts(node, "label", labelConflicts)
+	m.reportFieldConflicts(node, "annotation", annotationConflicts)
+	if m.config().Restrictions.AllowOverwrite {
+		return skipped, apply(true)
+	}
+
+	// Skip the conflicting keys, leaving them to the other field managers
+	labels = maps.Clone(labels)
+	annotations = maps.Clone(annotations)
+	for k := range labelConflicts {
+		delete(labels, k)
+		skipped.Insert(k)
+	}
+	for k := range annotationConflicts {
+		delete(annotations, k)
+	}
+	return skipped, apply(false)
+}
+
+// applyNodeStatus applies the extended resources of a node. Allocatable is
+// filled in by the kubelet based on capacity but the allocatable of removed
+// extended resources is removed explicitly. Returns the extended resources
+// skipped because of conflicts with other field managers.
+func (m *nfdMaster) applyNodeStatus(cli k8sclient.Interface, node *corev1.Node, owned sets.Set[string], extendedResources ExtendedResources) (sets.Set[string], error) {
+	skipped := sets.New[string]()
+	capacity := corev1.ResourceList{}
+	for name, value := range extendedResources {
+		q, err := resource.ParseQuantity(value)
+		if err != nil {
+			return skipped, fmt.Errorf("invalid value %q of extended resource %q: %w", value, name, err)
+		}
+		capacity[corev1.ResourceName(name)] = q
+	}
+	apply := func(force bool) error {
+		// Don't claim the (empty) capacity map itself when there is nothing to
+		// apply, conflicting with other field managers
+		status := corev1apply.NodeStatus()
+		if len(capacity) > 0 {
+			status = status.WithCapacity(capacity)
+		}
+		cfg := corev1apply.Node(node.Name).WithStatus(status)
+		_, err := cli.CoreV1().Nodes().ApplyStatus(context.TODO(), cfg, metav1.ApplyOptions{FieldManager: m.fieldManager(), Force: force})
+		return err
+	}
+
+	err := apply(false)
+	if conflicts := fieldConflicts(err, capacityFieldPath); len(conflicts) > 0 {
+		m.reportFieldConflicts(node, "extended resource", conflicts)
+		if m.config().Restrictions.AllowOverwrite {
+			err = apply(true)
+		} else {
+			// Skip the conflicting resources, leaving them to the other
+			// field managers
+			for k := range conflicts {
+				delete(capacity, corev1.ResourceName(k))
+				skipped.Insert(k)
+			}
+			err = apply(false)
+		}
+	}
+	if err != nil {
+		return skipped, err
+	}
+
+	var patches []utils.JsonPatch
+	for name := range owned {
+		if _, ok := capacity[corev1.ResourceName(name)]; ok || skipped.Has(name) {
+			continue
+		}
+		if _, ok := node.Status.Allocatable[corev1.ResourceName(name)]; ok {
+			patches = append(patches, utils.NewJsonPatch("remove", "/status/allocatable", name, ""))
+		}
+	}
+	return skipped, patchNodeStatus(cli, node.Name, patches)
+}
+
+// createCapacityPatches returns the changes in the extended resources of a
+// node, in the form of JSON patches, compared to the extended resources
+// owned by nfd-master.
+func (m *nfdMaster) createCapacityPatches(node *corev1.Node, owned sets.Set[string], extendedResources ExtendedResources) []utils.JsonPatch {
+	oldItems := make(map[string]string, len(node.Status.Capacity))
+	for name, q := range node.Status.Capacity {
+		oldItems[string(name)] = q.String()
+	}
+	return createPatches(owned, oldItems, extendedResources, "/status/capacity", true)
+}
+
+// removeLegacyNodeFields removes the labels, annotations and extended
+// resources tracked in NFD annotations, i.e. managed with JSON patches,
+// that are not in the new set of items. The tracking annotations are
+// removed, too.
+func (m *nfdMaster) removeLegacyNodeFields(cli k8sclient.Interface, node *corev1.Node, labels Labels, annotations Annotations, extendedResources ExtendedResources) error {
+	labelsAnnotation := m.instanceAnnotation(nfdv1alpha1.FeatureLabelsAnnotation)
+	erAnnotation := m.instanceAnnotation(nfdv1alpha1.ExtendedResourceAnnotation)
+	annotationsAnnotation := m.instanceAnnotation(nfdv1alpha1.FeatureAnnotationsTrackingAnnotation)
+
+	oldAnnotations := stringToNsNames(node.Annotations[annotationsAnnotation], nfdv1alpha1.FeatureAnnotationNs)
+	oldAnnotations = append(oldAnnotations, labelsAnnotation, erAnnotation, annotationsAnnotation)
+
+	var patches []utils.JsonPatch
+	for _, name := range stringToNsNames(node.Annotations[labelsAnnotation], nfdv1alpha1.FeatureLabelNs) {
+		_, onNode := node.Labels[name]
+		if _, ok := labels[name]; onNode && !ok {
+			patches = append(patches, utils.NewJsonPatch("remove", "/metadata/labels", name, ""))
+		}
+	}
+	for _, name := range oldAnnotations {
+		_, onNode := node.Annotations[name]
+		if _, ok := annotations[name]; onNode && !ok {
+			patches = append(patches, utils.NewJsonPatch("remove", "/metadata/annotations", name, ""))
+		}
+	}
+	if err := patchNode(cli, node.Name, patches); err != nil {
+		return fmt.Errorf("error while removing legacy node labels and annotations: %w", err)
+	}
+
+	var statusPatches []utils.JsonPatch
+	for _, name := range stringToNsNames(node.Annotations[erAnnotation], nfdv1alpha1.FeatureLabelNs) {
+		if _, ok := extendedResources[name]; ok {
+			continue
+		}
+		if _, ok := node.Status.Capacity[corev1.ResourceName(name)]; ok {
+			statusPatches = append(statusPatches,
+				utils.NewJsonPatch("remove", "/status/capacity", name, ""),
+				utils.NewJsonPatch("remove", "/status/allocatable", name, ""))
+		}
+	}
+	if err := patchNodeStatus(cli, node.Name, statusPatches); err != nil {
+		return fmt.Errorf("error while removing legacy extended resources: %w", err)
+	}
+	return nil
+}
+
+// reportFieldConflicts reports conflicts with other field managers.
+func (m *nfdMaster) reportFieldConflicts(node *corev1.Node, itemType string, conflicts map[string]string) {
+	if len(conflicts) == 0 {
+		return
+	}
+	action := "skipped"
+	if m.config().Restrictions.AllowOverwrite {
+		action = "overwritten"
+	}
+	for _, name := range sets.List(sets.KeySet(conflicts)) {
+		klog.InfoS("field manager conflict", "nodeName", node.Name, "type", itemType, "name", name, "action", action, "conflict", conflicts[name])
+		nodeFieldConflicts.Inc()
+	}
+	if m.nodeEventRecorder != nil {
+		m.nodeEventRecorder.Eventf(node, nil, corev1.EventTypeWarning, "FieldManagerConflict", nodeEventAction,
+			"%s %s: %s managed by another field manager", action, itemType, strings.Join(sets.List(sets.KeySet(conflicts)), ", "))
+	}
+}
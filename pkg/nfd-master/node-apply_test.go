@@ -0,0 +1,117 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdmaster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1apply "k8s.io/client-go/applyconfigurations/core/v1"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+)
+
+func TestApplyNodeObject(t *testing.T) {
+	ctx := context.TODO()
+	cli := fakeclient.NewClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-1",
+			Labels: map[string]string{"example.com/legacy": "true"},
+			Annotations: map[string]string{
+				nfdv1alpha1.FeatureLabelsAnnotation: "example.com/legacy",
+			},
+		},
+	})
+	// Label managed by another field manager
+	_, err := cli.CoreV1().Nodes().Apply(ctx, corev1apply.Node("node-1").WithLabels(map[string]string{"example.com/conflict": "a"}),
+		metav1.ApplyOptions{FieldManager: "other"})
+	assert.NoError(t, err)
+
+	m := newFakeMaster(WithKubernetesClient(cli))
+	m.config().NodeUpdateStrategy = NodeUpdateStrategyServerSideApply
+	m.config().Restrictions.AllowOverwrite = false
+
+	getNode := func() *corev1.Node {
+		node, err := cli.CoreV1().Nodes().Get(ctx, "node-1", metav1.GetOptions{})
+		assert.NoError(t, err)
+		return node
+	}
+
+	// Legacy items are removed and conflicts skipped
+	labels := Labels{"example.com/foo": "1", "example.com/conflict": "b"}
+	assert.NoError(t, m.updateNodeObject(cli, getNode(), labels, Annotations{}, ExtendedResources{}, nil, nil))
+	node := getNode()
+	assert.Equal(t, map[string]string{"example.com/foo": "1", "example.com/conflict": "a"}, node.Labels)
+	assert.NotContains(t, node.Annotations, nfdv1alpha1.FeatureLabelsAnnotation)
+	assert.ElementsMatch(t, []string{"example.com/foo"}, getManagedNodeFields(node, "nfd-master").labels.UnsortedList())
+	assert.Equal(t, []string{"example.com/foo"}, m.nfdManagedLabels(node))
+
+	// Conflicts are overwritten if allowed
+	m.config().Restrictions.AllowOverwrite = true
+	assert.NoError(t, m.updateNodeObject(cli, getNode(), labels, Annotations{}, ExtendedResources{}, nil, nil))
+	assert.Equal(t, map[string]string{"example.com/foo": "1", "example.com/conflict": "b"}, getNode().Labels)
+
+	// Labels no longer produced are removed
+	assert.NoError(t, m.updateNodeObject(cli, getNode(), Labels{"example.com/bar": "1"}, Annotations{}, ExtendedResources{}, nil, nil))
+	assert.Equal(t, map[string]string{"example.com/bar": "1"}, getNode().Labels)
+}
+
+func TestApplyNodeExtendedResources(t *testing.T) {
+	ctx := context.TODO()
+	cli := fakeclient.NewClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+	m := newFakeMaster(WithKubernetesClient(cli))
+	m.config().NodeUpdateStrategy = NodeUpdateStrategyServerSideApply
+
+	getNode := func() *corev1.Node {
+		node, err := cli.CoreV1().Nodes().Get(ctx, "node-1", metav1.GetOptions{})
+		assert.NoError(t, err)
+		return node
+	}
+
+	assert.NoError(t, m.updateNodeObject(cli, getNode(), Labels{}, Annotations{}, ExtendedResources{"example.com/er": "2"}, nil, nil))
+	node := getNode()
+	assert.Equal(t, resource.MustParse("2"), node.Status.Capacity["example.com/er"])
+	assert.ElementsMatch(t, []string{"example.com/er"}, getManagedNodeFields(node, "nfd-master").capacity.UnsortedList())
+
+	// Extended resources no longer produced are removed
+	assert.NoError(t, m.updateNodeObject(cli, getNode(), Labels{}, Annotations{}, ExtendedResources{}, nil, nil))
+	assert.NotContains(t, getNode().Status.Capacity, corev1.ResourceName("example.com/er"))
+
+	// Extended resource managed by another field manager
+	_, err := cli.CoreV1().Nodes().ApplyStatus(ctx, corev1apply.Node("node-1").WithStatus(corev1apply.NodeStatus().WithCapacity(
+		corev1.ResourceList{"example.com/conflict": resource.MustParse("1")})), metav1.ApplyOptions{FieldManager: "other"})
+	assert.NoError(t, err)
+
+	// Conflicts are skipped
+	m.config().Restrictions.AllowOverwrite = false
+	extendedResources := ExtendedResources{"example.com/er": "2", "example.com/conflict": "4"}
+	assert.NoError(t, m.updateNodeObject(cli, getNode(), Labels{}, Annotations{}, extendedResources, nil, nil))
+	node = getNode()
+	assert.Equal(t, resource.MustParse("2"), node.Status.Capacity["example.com/er"])
+	assert.Equal(t, resource.MustParse("1"), node.Status.Capacity["example.com/conflict"])
+	assert.ElementsMatch(t, []string{"example.com/er"}, getManagedNodeFields(node, "nfd-master").capacity.UnsortedList())
+
+	// Conflicts are overwritten if allowed
+	m.config().Restrictions.AllowOverwrite = true
+	assert.NoError(t, m.updateNodeObject(cli, getNode(), Labels{}, Annotations{}, extendedResources, nil, nil))
+	assert.Equal(t, resource.MustParse("4"), getNode().Status.Capacity["example.com/conflict"])
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdmaster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+)
+
+const (
+	// nodeEventReason is the reason of the events emitted on node changes
+	nodeEventReason = "NodeFeaturesChanged"
+	// nodeEventAction is the action of the events emitted on node changes
+	nodeEventAction = "UpdateNode"
+	// maxNodeEventNoteLen is the maximum length of the note of an event
+	// (events.k8s.io/v1 API limit)
+	maxNodeEventNoteLen = 1024
+
+	// Rate limit of events per node
+	nodeEventBurst    = 5
+	nodeEventInterval = 30 * time.Second
+)
+
+// Types of changes to a node.
+const (
+	nodeChangeAdded   = "added"
+	nodeChangeRemoved = "removed"
+	nodeChangeChanged = "changed"
+)
+
+// nodeChange describes one added, removed or changed label, taint or
+// extended resource of a node.
+type nodeChange struct {
+	// outputType is one of ruleOutputLabel, ruleOutputTaint or
+	// ruleOutputExtendedResource
+	outputType string
+	name       string
+	op         string
+}
+
+// nodeChangesFromPatches returns the changes described by JSON patches of the
+// node object. Only patches under jsonPath are considered.
+func nodeChangesFromPatches(outputType, jsonPath string, patches []utils.JsonPatch) []nodeChange {
+	var changes []nodeChange
+	prefix := jsonPath + "/"
+	for _, p := range patches {
+		if !strings.HasPrefix(p.Path, prefix) {
+			continue
+		}
+		c := nodeChange{
+			outputType: outputType,
+			name:       strings.ReplaceAll(strings.TrimPrefix(p.Path, prefix), "~1", "/"),
+		}
+		switch p.Op {
+		case "add":
+			c.op = nodeChangeAdded
+		case "remove":
+			c.op = nodeChangeRemoved
+		default:
+			c.op = nodeChangeChanged
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// nodeEventLimiter rate limits the events emitted per node.
+type nodeEventLimiter struct {
+	sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func newNodeEventLimiter() *nodeEventLimiter {
+	return &nodeEventLimiter{limiters: make(map[string]*rate.Limiter)}
+}
+
+// allow returns true if an event may be emitted on the node.
+func (l *nodeEventLimiter) allow(nodeName string) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	lim, ok := l.limiters[nodeName]
+	if !ok {
+		lim = rate.NewLimiter(rate.Every(nodeEventInterval), nodeEventBurst)
+		l.limiters[nodeName] = lim
+	}
+	return lim.Allow()
+}
+
+// removeNode drops the rate limiter of a node that has been deleted.
+func (l *nodeEventLimiter) removeNode(nodeName string) {
+	l.Lock()
+	defer l.Unlock()
+
+	delete(l.limiters, nodeName)
+}
+
+// recordNodeEvents emits events on a node, describing the changes made to
+// its labels, taints and extended resources. One event is emitted per
+// originating NodeFeatureRule, which is set as the related object of the
+// event. Removals are attributed to the NodeFeatureRule that produced the
+// item in the previous update of the node. Changes not originating from a
+// NodeFeatureRule (e.g. labels from NodeFeature objects) are reported in an
+// event without a related object.
+func (m *nfdMaster) recordNodeEvents(node *corev1.Node, changes []nodeChange, owners map[string]ruleOutputOwner) {
+	curOwners := make(map[string]*nfdv1alpha1.NodeFeatureRule, len(owners))
+	for k, o := range owners {
+		curOwners[k] = o.nfr
+	}
+	prevOwners := m.nodeFeatures.setOutputOwners(node.Name, curOwners)
+
+	if len(changes) == 0 || m.nodeEventRecorder == nil {
+		return
+	}
+
+	byOwner := make(map[*nfdv1alpha1.NodeFeatureRule][]nodeChange)
+	for _, c := range changes {
+		nfr := curOwners[c.outputType+"/"+c.name]
+		if c.op == nodeChangeRemoved {
+			nfr = prevOwners[c.outputType+"/"+c.name]
+		}
+		byOwner[nfr] = append(byOwner[nfr], c)
+	}
+
+	nfrs := make([]*nfdv1alpha1.NodeFeatureRule, 0, len(byOwner))
+	for nfr := range byOwner {
+		nfrs = append(nfrs, nfr)
+	}
+	sort.Slice(nfrs, func(i, j int) bool {
+		if nfrs[i] == nil || nfrs[j] == nil {
+			return nfrs[j] != nil
+		}
+		return nfrs[i].Name < nfrs[j].Name
+	})
+
+	for _, nfr := range nfrs {
+		if !m.nodeEventLimiter.allow(node.Name) {
+			klog.V(2).InfoS("node event rate limit exceeded, dropping event", "nodeName", node.Name)
+			nodeEventsDropped.Inc()
+			return
+		}
+		var related runtime.Object
+		if nfr != nil {
+			related = nfr
+		}
+		m.nodeEventRecorder.Eventf(node, related, corev1.EventTypeNormal, nodeEventReason, nodeEventAction, "%s", nodeEventNote(byOwner[nfr]))
+	}
+}
+
+// nodeEventNote returns a human-readable summary of node changes, e.g.
+// "label added: foo, bar; taint removed: example.com/t:NoSchedule".
+func nodeEventNote(changes []nodeChange) string {
+	names := make(map[string][]string)
+	for _, c := range changes {
+		key := c.outputType + " " + c.op
+		names[key] = append(names[key], c.name)
+	}
+
+	var parts []string
+	for _, outputType := range []string{ruleOutputLabel, ruleOutputTaint, ruleOutputExtendedResource} {
+		for _, op := range []string{nodeChangeAdded, nodeChangeChanged, nodeChangeRemoved} {
+			key := outputType + " " + op
+			if n := names[key]; len(n) > 0 {
+				sort.Strings(n)
+				parts = append(parts, fmt.Sprintf("%s: %s", key, strings.Join(n, ", ")))
+			}
+		}
+	}
+
+	note := strings.Join(parts, "; ")
+	if len(note) > maxNodeEventNoteLen {
+		note = note[:maxNodeEventNoteLen-3] + "..."
+	}
+	return note
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdmaster
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+)
+
+type recordedNodeEvent struct {
+	related string
+	note    string
+}
+
+// fakeNodeEventRecorder records the related object and the note of events.
+type fakeNodeEventRecorder struct {
+	events []recordedNodeEvent
+}
+
+func (r *fakeNodeEventRecorder) Eventf(regarding runtime.Object, related runtime.Object, eventtype, reason, action, note string, args ...interface{}) {
+	e := recordedNodeEvent{note: fmt.Sprintf(note, args...)}
+	if related != nil {
+		e.related = related.(metav1.Object).GetName()
+	}
+	r.events = append(r.events, e)
+}
+
+func (r *fakeNodeEventRecorder) WithLogger(logger klog.Logger) events.EventRecorderLogger {
+	return r
+}
+
+func TestNodeChangesFromPatches(t *testing.T) {
+	patches := []utils.JsonPatch{
+		utils.NewJsonPatch("add", "/metadata/labels", "example.com/foo", "1"),
+		utils.NewJsonPatch("replace", "/metadata/labels", "bar", "2"),
+		utils.NewJsonPatch("remove", "/metadata/labels", "example.com/baz", ""),
+		utils.NewJsonPatch("add", "/metadata/annotations", "example.com/foo", "1"),
+	}
+	assert.Equal(t, []nodeChange{
+		{outputType: ruleOutputLabel, name: "example.com/foo", op: nodeChangeAdded},
+		{outputType: ruleOutputLabel, name: "bar", op: nodeChangeChanged},
+		{outputType: ruleOutputLabel, name: "example.com/baz", op: nodeChangeRemoved},
+	}, nodeChangesFromPatches(ruleOutputLabel, "/metadata/labels", patches))
+}
+
+func TestRecordNodeEvents(t *testing.T) {
+	recorder := &fakeNodeEventRecorder{}
+	m := &nfdMaster{nodeEventRecorder: recorder, nodeEventLimiter: newNodeEventLimiter(), nodeFeatures: newNodeFeaturesCache()}
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	nfr := &nfdv1alpha1.NodeFeatureRule{ObjectMeta: metav1.ObjectMeta{Name: "nfr-1"}}
+	owners := map[string]ruleOutputOwner{
+		"label/example.com/foo":          {nfr: nfr, rule: "rule-1"},
+		"taint/example.com/t:NoSchedule": {nfr: nfr, rule: "rule-1"},
+	}
+	changes := []nodeChange{
+		{outputType: ruleOutputLabel, name: "example.com/foo", op: nodeChangeAdded},
+		{outputType: ruleOutputTaint, name: "example.com/t:NoSchedule", op: nodeChangeChanged},
+		{outputType: ruleOutputLabel, name: "example.com/bar", op: nodeChangeAdded},
+		{outputType: ruleOutputLabel, name: "example.com/baz", op: nodeChangeRemoved},
+		{outputType: ruleOutputExtendedResource, name: "example.com/er", op: nodeChangeRemoved},
+	}
+
+	m.recordNodeEvents(node, changes, owners)
+	assert.Equal(t, []recordedNodeEvent{
+		{note: "label added: example.com/bar; label removed: example.com/baz; extendedResource removed: example.com/er"},
+		{related: "nfr-1", note: "label added: example.com/foo; taint changed: example.com/t:NoSchedule"},
+	}, recorder.events)
+
+	// No events without changes
+	recorder.events = nil
+	m.recordNodeEvents(node, nil, owners)
+	assert.Empty(t, recorder.events)
+
+	// Removals should be related to the NodeFeatureRule that produced the
+	// item earlier
+	m.recordNodeEvents(node, []nodeChange{
+		{outputType: ruleOutputLabel, name: "example.com/foo", op: nodeChangeRemoved},
+		{outputType: ruleOutputTaint, name: "example.com/t:NoSchedule", op: nodeChangeRemoved},
+	}, nil)
+	assert.Equal(t, []recordedNodeEvent{
+		{related: "nfr-1", note: "label removed: example.com/foo; taint removed: example.com/t:NoSchedule"},
+	}, recorder.events)
+	recorder.events = nil
+
+	// Events should be rate limited per node
+	for i := 0; i < 2*nodeEventBurst; i++ {
+		m.recordNodeEvents(node, changes[:1], owners)
+	}
+	assert.Len(t, recorder.events, nodeEventBurst-3)
+	m.recordNodeEvents(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}}, changes[:1], owners)
+	assert.Len(t, recorder.events, nodeEventBurst-2)
+}
@@ -0,0 +1,271 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdmaster
+
+import (
+	"hash/fnv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+)
+
+// nodeFeaturesCacheEntry is the cached data of one node.
+type nodeFeaturesCacheEntry struct {
+	// featuresKey identifies the NodeFeature objects (and their
+	// ResourceVersions) the merged features were created from
+	featuresKey string
+	features    *nfdv1alpha1.NodeFeature
+	// evaluationKey identifies the inputs of the last successful update of
+	// the node
+	evaluationKey uint64
+	// sourceSnapshots contains the data of the successfully discovered
+	// feature sources, keyed by "<namespace>/<name>/<source>" of the
+	// NodeFeature object
+	sourceSnapshots map[string]*sourceSnapshot
+	// ruleConflicts contains the keys of the conflicts between
+	// NodeFeatureRules found in the last evaluation of the node
+	ruleConflicts sets.Set[string]
+	// outputOwners contains the NodeFeatureRules that produced the labels,
+	// taints and extended resources in the last update of the node, keyed
+	// by "<output type>/<name>"
+	outputOwners map[string]*nfdv1alpha1.NodeFeatureRule
+}
+
+// nodeFeaturesCache caches the merged NodeFeature objects of nodes, shared
+// by the node and NodeFeatureGroup updaters. The entries are tied to the
+// ResourceVersions of the contributing NodeFeature objects so the objects of
+// a node are only re-merged when some of them change. In addition, the cache
+// keeps track of the inputs of the last update of each node so that
+// evaluation of the node can be skipped if nothing has changed.
+type nodeFeaturesCache struct {
+	sync.Mutex
+	nodes map[string]*nodeFeaturesCacheEntry
+}
+
+func newNodeFeaturesCache() *nodeFeaturesCache {
+	return &nodeFeaturesCache{nodes: make(map[string]*nodeFeaturesCacheEntry)}
+}
+
+// get returns the cached merged features of a node if they were created from
+// the NodeFeature objects identified by featuresKey.
+func (c *nodeFeaturesCache) get(nodeName, featuresKey string) (*nfdv1alpha1.NodeFeature, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	e, ok := c.nodes[nodeName]
+	if !ok || e.features == nil || e.featuresKey != featuresKey {
+		return nil, false
+	}
+	return e.features, true
+}
+
+// set stores the merged features of a node.
+func (c *nodeFeaturesCache) set(nodeName, featuresKey string, nf *nfdv1alpha1.NodeFeature) {
+	c.Lock()
+	defer c.Unlock()
+
+	if e, ok := c.nodes[nodeName]; ok {
+		e.featuresKey = featuresKey
+		e.features = nf
+		return
+	}
+	c.nodes[nodeName] = &nodeFeaturesCacheEntry{featuresKey: featuresKey, features: nf}
+}
+
+// isEvaluated returns true if the last successful update of the node was done
+// with the same inputs.
+func (c *nodeFeaturesCache) isEvaluated(nodeName string, evaluationKey uint64) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	e, ok := c.nodes[nodeName]
+	return ok && e.evaluationKey == evaluationKey
+}
+
+// setEvaluated records the inputs of a successful update of the node.
+func (c *nodeFeaturesCache) setEvaluated(nodeName string, evaluationKey uint64) {
+	c.Lock()
+	defer c.Unlock()
+
+	if e, ok := c.nodes[nodeName]; ok {
+		e.evaluationKey = evaluationKey
+	}
+}
+
+// getSourceSnapshot returns the data of a successfully discovered feature
+// source of a node, or nil if there is none.
+func (c *nodeFeaturesCache) getSourceSnapshot(nodeName, key string) *sourceSnapshot {
+	c.Lock()
+	defer c.Unlock()
+
+	if e, ok := c.nodes[nodeName]; ok {
+		return e.sourceSnapshots[key]
+	}
+	return nil
+}
+
+// setSourceSnapshot stores the data of a successfully discovered feature
+// source of a node.
+func (c *nodeFeaturesCache) setSourceSnapshot(nodeName, key string, s *sourceSnapshot) {
+	c.Lock()
+	defer c.Unlock()
+
+	e, ok := c.nodes[nodeName]
+	if !ok {
+		e = &nodeFeaturesCacheEntry{}
+		c.nodes[nodeName] = e
+	}
+	if e.sourceSnapshots == nil {
+		e.sourceSnapshots = make(map[string]*sourceSnapshot)
+	}
+	e.sourceSnapshots[key] = s
+}
+
+// setRuleConflicts stores the conflicts between NodeFeatureRules found in
+// an evaluation of a node. Returns the conflicts that were not present in the
+// previous evaluation.
+func (c *nodeFeaturesCache) setRuleConflicts(nodeName string, conflicts sets.Set[string]) sets.Set[string] {
+	c.Lock()
+	defer c.Unlock()
+
+	e, ok := c.nodes[nodeName]
+	if !ok {
+		e = &nodeFeaturesCacheEntry{}
+		c.nodes[nodeName] = e
+	}
+	newConflicts := conflicts.Difference(e.ruleConflicts)
+	e.ruleConflicts = conflicts
+	return newConflicts
+}
+
+// setOutputOwners stores the NodeFeatureRules that produced the outputs
+// applied in an update of a node. Returns the owners stored in the previous
+// update.
+func (c *nodeFeaturesCache) setOutputOwners(nodeName string, owners map[string]*nfdv1alpha1.NodeFeatureRule) map[string]*nfdv1alpha1.NodeFeatureRule {
+	c.Lock()
+	defer c.Unlock()
+
+	e, ok := c.nodes[nodeName]
+	if !ok {
+		e = &nodeFeaturesCacheEntry{}
+		c.nodes[nodeName] = e
+	}
+	prev := e.outputOwners
+	e.outputOwners = owners
+	return prev
+}
+
+// removeNode drops the data of a node, e.g. when the node has been deleted.
+func (c *nodeFeaturesCache) removeNode(nodeName string) {
+	c.Lock()
+	defer c.Unlock()
+
+	delete(c.nodes, nodeName)
+}
+
+// pruneNodes drops the data of all nodes not found in the given set of node
+// names.
+func (c *nodeFeaturesCache) pruneNodes(nodeNames map[string]struct{}) {
+	c.Lock()
+	defer c.Unlock()
+
+	for n := range c.nodes {
+		if _, ok := nodeNames[n]; !ok {
+			delete(c.nodes, n)
+		}
+	}
+}
+
+// nodeFeaturesKey returns a key identifying a list of NodeFeature objects
+// and their ResourceVersions.
+func nodeFeaturesKey(objs []*nfdv1alpha1.NodeFeature) string {
+	var b strings.Builder
+	for _, o := range objs {
+		b.WriteString(o.Namespace + "/" + o.Name + "@" + o.ResourceVersion + ";")
+	}
+	return b.String()
+}
+
+// nodeFeatureRulesKey returns a key identifying a set of NodeFeatureRule
+// objects and their generations. Generation is used instead of the
+// ResourceVersion because status updates of the rules do not affect the
+// nodes.
+func nodeFeatureRulesKey(nfrs []*nfdv1alpha1.NodeFeatureRule) string {
+	keys := make([]string, len(nfrs))
+	for i, o := range nfrs {
+		keys[i] = o.Name + "@" + strconv.FormatInt(o.Generation, 10) + ";"
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "")
+}
+
+// nodeFeatureGroupsKey returns a key identifying a set of NodeFeatureGroup
+// objects and their generations. Generation is used instead of the
+// ResourceVersion because status updates of the groups do not affect the
+// nodes.
+func nodeFeatureGroupsKey(nfgs []*nfdv1alpha1.NodeFeatureGroup) string {
+	keys := make([]string, len(nfgs))
+	for i, o := range nfgs {
+		keys[i] = o.Namespace + "/" + o.Name + "@" + strconv.FormatInt(o.Generation, 10) + ";"
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "")
+}
+
+// nodeEvaluationKey returns a key identifying the inputs of updating a node:
+// the generation of the nfd-master configuration, the NodeFeature objects of the node, the NodeFeatureRule and
+// NodeFeatureGroup objects, the labels of the node (used in the nodeSelector
+// of NodeFeatureRules) and the annotations, taints and capacity of the node.
+// The latter ones are included so that NFD-managed annotations, taints and
+// extended resources removed from the node by others are restored on resync.
+func nodeEvaluationKey(configGeneration int64, featuresKey, rulesKey, groupsKey string, node *corev1.Node) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(strconv.FormatInt(configGeneration, 10) + "|" + featuresKey + "|" + rulesKey + "|" + groupsKey + "|"))
+	writeSortedMap(h, node.Labels)
+	h.Write([]byte("|"))
+	writeSortedMap(h, node.Annotations)
+	h.Write([]byte("|"))
+	for _, t := range node.Spec.Taints {
+		h.Write([]byte(t.ToString() + ";"))
+	}
+	h.Write([]byte("|"))
+	capacity := make(map[string]string, len(node.Status.Capacity))
+	for name, q := range node.Status.Capacity {
+		capacity[string(name)] = q.String()
+	}
+	writeSortedMap(h, capacity)
+	return h.Sum64()
+}
+
+// writeSortedMap writes the items of a map in a deterministic order.
+func writeSortedMap(w io.Writer, m map[string]string) {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, _ = w.Write([]byte(name + "=" + m[name] + ";"))
+	}
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdmaster
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+)
+
+// RuleConflictPolicy specifies how conflicting outputs of NodeFeatureRules
+// are resolved.
+type RuleConflictPolicy string
+
+const (
+	// RuleConflictPolicyHighestPriorityWins selects the value from the rule
+	// with the highest priority. If the priorities are equal, the value from
+	// the NodeFeatureRule object that is last in alphabetical order wins.
+	RuleConflictPolicyHighestPriorityWins RuleConflictPolicy = "highestPriorityWins"
+	// RuleConflictPolicyFirstWins selects the value from the rule that was
+	// processed first, i.e. the NodeFeatureRule object that is first in
+	// alphabetical order wins.
+	RuleConflictPolicyFirstWins RuleConflictPolicy = "firstWins"
+	// RuleConflictPolicyError treats conflicts as errors: none of the
+	// conflicting values is applied.
+	RuleConflictPolicyError RuleConflictPolicy = "error"
+)
+
+// Types of rule output.
+const (
+	ruleOutputLabel            = "label"
+	ruleOutputAnnotation       = "annotation"
+	ruleOutputExtendedResource = "extendedResource"
+	ruleOutputTaint            = "taint"
+)
+
+// ruleOutputOwner identifies the rule that produced an output value.
+type ruleOutputOwner struct {
+	nfr  *nfdv1alpha1.NodeFeatureRule
+	rule string
+}
+
+func (o ruleOutputOwner) String() string {
+	return fmt.Sprintf("rule %q of NodeFeatureRule %q", o.rule, o.nfr.Name)
+}
+
+// ruleConflict describes a conflict between the outputs of two rules.
+type ruleConflict struct {
+	outputType string
+	name       string
+	// Conflicting rules and their values
+	prev      ruleOutputOwner
+	prevValue string
+	cur       ruleOutputOwner
+	curValue  string
+	// curWins is true if the value of cur was applied
+	curWins bool
+	// dropped is true if neither of the values was applied
+	dropped bool
+}
+
+func (c ruleConflict) String() string {
+	msg := fmt.Sprintf("conflicting values for %s %q: %q from %s and %q from %s", c.outputType, c.name, c.prevValue, c.prev, c.curValue, c.cur)
+	switch {
+	case c.dropped:
+		return msg + ", ignoring both"
+	case c.curWins:
+		return msg + fmt.Sprintf(", using the value from %s", c.cur)
+	}
+	return msg + fmt.Sprintf(", using the value from %s", c.prev)
+}
+
+// key identifies the conflict, i.e. the output and the conflicting
+// NodeFeatureRule objects, across evaluations of a node.
+func (c ruleConflict) key() string {
+	return c.outputType + "/" + c.name + "/" + c.prev.nfr.Name + "/" + c.cur.nfr.Name
+}
+
+// losers returns the rules whose value was not applied.
+func (c ruleConflict) losers() []ruleOutputOwner {
+	switch {
+	case c.dropped:
+		return []ruleOutputOwner{c.prev, c.cur}
+	case c.curWins:
+		return []ruleOutputOwner{c.prev}
+	}
+	return []ruleOutputOwner{c.cur}
+}
+
+// ruleOutputMerger merges the outputs of multiple rules, resolving conflicts
+// according to the conflict policy.
+type ruleOutputMerger struct {
+	policy RuleConflictPolicy
+
+	owners map[string]ruleOutputOwner
+	// conflicted contains outputs that are dropped because of a conflict
+	// (with the error policy) and the first conflict of each
+	conflicted map[string]ruleConflict
+
+	taints     []corev1.Taint
+	taintIndex map[string]int
+}
+
+func newRuleOutputMerger(policy RuleConflictPolicy) *ruleOutputMerger {
+	return &ruleOutputMerger{
+		policy:     policy,
+		owners:     make(map[string]ruleOutputOwner),
+		conflicted: make(map[string]ruleConflict),
+		taintIndex: make(map[string]int),
+	}
+}
+
+// resolve determines if a new value for an output should replace the
+// existing value. Returns true if the new value should be applied and false
+// if the existing value should be retained. The output should be dropped if
+// a conflict with the error policy is returned. Outputs are owned by
+// NodeFeatureRule objects: later rules of the same object override the
+// values of earlier rules without a conflict. With the error policy, values
+// from other objects for an output that was already dropped are conflicts,
+// too.
+func (m *ruleOutputMerger) resolve(outputType, name, prevValue, curValue string, owner ruleOutputOwner) (bool, *ruleConflict) {
+	key := outputType + "/" + name
+
+	if first, ok := m.conflicted[key]; ok {
+		// Report the conflict against the one of the first conflicting
+		// rules that is from another object and had a different value
+		prev, prevValue := first.prev, first.prevValue
+		if prev.nfr.Name == owner.nfr.Name || prevValue == curValue {
+			prev, prevValue = first.cur, first.curValue
+		}
+		if prev.nfr.Name == owner.nfr.Name || prevValue == curValue {
+			return false, nil
+		}
+		return false, &ruleConflict{
+			outputType: outputType,
+			name:       name,
+			prev:       prev,
+			prevValue:  prevValue,
+			cur:        owner,
+			curValue:   curValue,
+			dropped:    true,
+		}
+	}
+
+	prev, ok := m.owners[key]
+	if !ok || prevValue == curValue || prev.nfr.Name == owner.nfr.Name {
+		m.owners[key] = owner
+		return true, nil
+	}
+
+	c := &ruleConflict{
+		outputType: outputType,
+		name:       name,
+		prev:       prev,
+		prevValue:  prevValue,
+		cur:        owner,
+		curValue:   curValue,
+	}
+	switch m.policy {
+	case RuleConflictPolicyFirstWins:
+	case RuleConflictPolicyError:
+		c.dropped = true
+		m.conflicted[key] = *c
+	default:
+		c.curWins = owner.nfr.Spec.Priority >= prev.nfr.Spec.Priority
+	}
+	if c.curWins {
+		m.owners[key] = owner
+	}
+	return c.curWins, c
+}
+
+// mergeMap merges the key-value outputs (labels, annotations or extended
+// resources) of a rule into dst.
+func (m *ruleOutputMerger) mergeMap(outputType string, dst, src map[string]string, owner ruleOutputOwner) []ruleConflict {
+	var conflicts []ruleConflict
+	for k, v := range src {
+		apply, c := m.resolve(outputType, k, dst[k], v, owner)
+		if c != nil {
+			conflicts = append(conflicts, *c)
+			if c.dropped {
+				delete(dst, k)
+			}
+		}
+		if apply {
+			dst[k] = v
+		}
+	}
+	return conflicts
+}
+
+// mergeTaints merges the taints output of a rule. Taints with the same key
+// and effect are considered to be the same output.
+func (m *ruleOutputMerger) mergeTaints(src []corev1.Taint, owner ruleOutputOwner) []ruleConflict {
+	var conflicts []ruleConflict
+	for _, t := range src {
+		name := t.Key + ":" + string(t.Effect)
+		idx, exists := m.taintIndex[name]
+		prevValue := ""
+		if exists {
+			prevValue = m.taints[idx].Value
+		}
+		apply, c := m.resolve(ruleOutputTaint, name, prevValue, t.Value, owner)
+		if c != nil {
+			conflicts = append(conflicts, *c)
+			if c.dropped && exists {
+				m.taints = append(m.taints[:idx], m.taints[idx+1:]...)
+				delete(m.taintIndex, name)
+				for n, i := range m.taintIndex {
+					if i > idx {
+						m.taintIndex[n] = i - 1
+					}
+				}
+			}
+		}
+		if apply {
+			if exists {
+				m.taints[idx] = t
+			} else {
+				m.taintIndex[name] = len(m.taints)
+				m.taints = append(m.taints, t)
+			}
+		}
+	}
+	return conflicts
+}
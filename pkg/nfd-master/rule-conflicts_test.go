@@ -0,0 +1,164 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdmaster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+)
+
+func newTestOwner(nfrName string, priority int32) ruleOutputOwner {
+	return ruleOutputOwner{
+		nfr: &nfdv1alpha1.NodeFeatureRule{
+			ObjectMeta: metav1.ObjectMeta{Name: nfrName},
+			Spec:       nfdv1alpha1.NodeFeatureRuleSpec{Priority: priority},
+		},
+		rule: "rule",
+	}
+}
+
+func TestRuleOutputMerger(t *testing.T) {
+	tcs := []struct {
+		name           string
+		policy         RuleConflictPolicy
+		prioA          int32
+		prioB          int32
+		expectedLabels map[string]string
+		expectedTaints []corev1.Taint
+		expectedLoser  []string
+	}{
+		{
+			name:           "highest priority wins, equal priorities",
+			policy:         RuleConflictPolicyHighestPriorityWins,
+			expectedLabels: map[string]string{"same": "true", "conflict": "b", "only-a": "a", "only-b": "b"},
+			expectedTaints: []corev1.Taint{{Key: "taint", Value: "b", Effect: corev1.TaintEffectNoSchedule}},
+			expectedLoser:  []string{"nfr-a"},
+		},
+		{
+			name:           "highest priority wins",
+			policy:         RuleConflictPolicyHighestPriorityWins,
+			prioA:          10,
+			expectedLabels: map[string]string{"same": "true", "conflict": "a", "only-a": "a", "only-b": "b"},
+			expectedTaints: []corev1.Taint{{Key: "taint", Value: "a", Effect: corev1.TaintEffectNoSchedule}},
+			expectedLoser:  []string{"nfr-b"},
+		},
+		{
+			name:           "first wins",
+			policy:         RuleConflictPolicyFirstWins,
+			prioB:          10,
+			expectedLabels: map[string]string{"same": "true", "conflict": "a", "only-a": "a", "only-b": "b"},
+			expectedTaints: []corev1.Taint{{Key: "taint", Value: "a", Effect: corev1.TaintEffectNoSchedule}},
+			expectedLoser:  []string{"nfr-b"},
+		},
+		{
+			name:           "error",
+			policy:         RuleConflictPolicyError,
+			expectedLabels: map[string]string{"same": "true", "only-a": "a", "only-b": "b"},
+			expectedTaints: []corev1.Taint{},
+			expectedLoser:  []string{"nfr-a", "nfr-b"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newRuleOutputMerger(tc.policy)
+			ownerA := newTestOwner("nfr-a", tc.prioA)
+			ownerB := newTestOwner("nfr-b", tc.prioB)
+			labels := map[string]string{}
+
+			conflicts := m.mergeMap(ruleOutputLabel, labels, map[string]string{"same": "true", "conflict": "a", "only-a": "a"}, ownerA)
+			conflicts = append(conflicts, m.mergeTaints([]corev1.Taint{{Key: "taint", Value: "a", Effect: corev1.TaintEffectNoSchedule}}, ownerA)...)
+			assert.Empty(t, conflicts)
+
+			conflicts = m.mergeMap(ruleOutputLabel, labels, map[string]string{"same": "true", "conflict": "b", "only-b": "b"}, ownerB)
+			conflicts = append(conflicts, m.mergeTaints([]corev1.Taint{{Key: "taint", Value: "b", Effect: corev1.TaintEffectNoSchedule}}, ownerB)...)
+
+			assert.Equal(t, tc.expectedLabels, labels)
+			assert.Equal(t, tc.expectedTaints, m.taints)
+			assert.Len(t, conflicts, 2)
+			for _, c := range conflicts {
+				losers := []string{}
+				for _, o := range c.losers() {
+					losers = append(losers, o.nfr.Name)
+				}
+				assert.Equal(t, tc.expectedLoser, losers)
+			}
+
+			// A third value for an output dropped with the error policy
+			// must not be applied either, and is a conflict, too
+			if tc.policy == RuleConflictPolicyError {
+				conflicts = m.mergeMap(ruleOutputLabel, labels, map[string]string{"conflict": "c"}, newTestOwner("nfr-c", 0))
+				assert.Len(t, conflicts, 1)
+				assert.Equal(t, "nfr-a", conflicts[0].prev.nfr.Name)
+				assert.Equal(t, "nfr-c", conflicts[0].cur.nfr.Name)
+				assert.True(t, conflicts[0].dropped)
+				assert.NotContains(t, labels, "conflict")
+
+				// A value equal to one of the dropped ones conflicts with
+				// the other
+				conflicts = m.mergeMap(ruleOutputLabel, labels, map[string]string{"conflict": "a"}, newTestOwner("nfr-d", 0))
+				assert.Len(t, conflicts, 1)
+				assert.Equal(t, "nfr-b", conflicts[0].prev.nfr.Name)
+				assert.NotContains(t, labels, "conflict")
+			}
+		})
+	}
+}
+
+func TestRuleOutputMergerSameObject(t *testing.T) {
+	m := newRuleOutputMerger(RuleConflictPolicyFirstWins)
+	owner := newTestOwner("nfr-a", 0)
+	labels := map[string]string{}
+
+	assert.Empty(t, m.mergeMap(ruleOutputLabel, labels, map[string]string{"foo": "1"}, owner))
+	// Later rules of the same object override earlier ones
+	owner.rule = "rule-2"
+	assert.Empty(t, m.mergeMap(ruleOutputLabel, labels, map[string]string{"foo": "2"}, owner))
+	assert.Equal(t, map[string]string{"foo": "2"}, labels)
+	assert.Equal(t, "rule-2", m.owners[ruleOutputLabel+"/foo"].rule)
+}
+
+func TestReportRuleConflicts(t *testing.T) {
+	recorder := &fakeNodeEventRecorder{}
+	m := &nfdMaster{nodeEventRecorder: recorder, nodeFeatures: newNodeFeaturesCache()}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+
+	merger := newRuleOutputMerger(RuleConflictPolicyHighestPriorityWins)
+	labels := map[string]string{}
+	merger.mergeMap(ruleOutputLabel, labels, map[string]string{"foo": "a"}, newTestOwner("nfr-a", 0))
+	conflicts := merger.mergeMap(ruleOutputLabel, labels, map[string]string{"foo": "b"}, newTestOwner("nfr-b", 0))
+	assert.Len(t, conflicts, 1)
+
+	// New conflicts are reported
+	m.reportRuleConflicts(node, conflicts)
+	assert.Len(t, recorder.events, 1)
+	assert.Equal(t, "node-1", recorder.events[0].related)
+
+	// Ongoing conflicts are not reported again
+	m.reportRuleConflicts(node, conflicts)
+	assert.Len(t, recorder.events, 1)
+
+	// Conflicts are reported again after they have been resolved
+	m.reportRuleConflicts(node, nil)
+	m.reportRuleConflicts(node, conflicts)
+	assert.Len(t, recorder.events, 2)
+}
@@ -0,0 +1,516 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdmaster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	taintutils "k8s.io/kubernetes/pkg/util/taints"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+)
+
+// errNodeUpdatesPaused is returned when a node update is not done because
+// the safeguard has paused node updates.
+var errNodeUpdatesPaused = errors.New("node updates are paused by the safeguard")
+
+const (
+	// SafeguardConditionNodeUpdatesAllowed is the type of the condition
+	// reporting the state of the safeguard.
+	SafeguardConditionNodeUpdatesAllowed = "NodeUpdatesAllowed"
+)
+
+// SafeguardConfig contains the configuration of the safeguard against mass
+// removal of labels and mass addition of NoSchedule and NoExecute taints.
+// The safeguard is disabled if neither MaxNodes nor MaxNodesPercent is set.
+type SafeguardConfig struct {
+	// MaxNodes is the maximum number of nodes that may lose a given label,
+	// or gain a given NoSchedule or NoExecute taint, within Window
+	MaxNodes int
+	// MaxNodesPercent is the same limit as MaxNodes, expressed as a
+	// percentage of all nodes in the cluster
+	MaxNodesPercent int
+	Window          utils.DurationVal
+}
+
+func (c *SafeguardConfig) enabled() bool {
+	return c.MaxNodes > 0 || c.MaxNodesPercent > 0
+}
+
+func (c *SafeguardConfig) validate() error {
+	if c.MaxNodes < 0 {
+		return fmt.Errorf("invalid safeguard.maxNodes %d, must not be negative", c.MaxNodes)
+	}
+	if c.MaxNodesPercent < 0 || c.MaxNodesPercent > 100 {
+		return fmt.Errorf("invalid safeguard.maxNodesPercent %d, must be between 0 and 100", c.MaxNodesPercent)
+	}
+	if c.enabled() && c.Window.Duration <= 0 {
+		return fmt.Errorf("invalid safeguard.window %v, must be greater than 0", c.Window.Duration)
+	}
+	return nil
+}
+
+// limit returns the maximum number of nodes a change may be applied to
+// within the window, or -1 if there is no limit. The percentage limit is
+// rounded up and ignored if the number of nodes is not known.
+func (c *SafeguardConfig) limit(nodeCount int) int {
+	limit := -1
+	if c.MaxNodes > 0 {
+		limit = c.MaxNodes
+	}
+	if c.MaxNodesPercent > 0 && nodeCount > 0 {
+		l := (nodeCount*c.MaxNodesPercent + 99) / 100
+		if limit < 0 || l < limit {
+			limit = l
+		}
+	}
+	return limit
+}
+
+// safeguardConfigMapName is the name of the ConfigMap, in the namespace of
+// nfd-master, storing the state of the safeguard. Node updates are resumed
+// by setting its "acknowledged" data field to "true".
+const safeguardConfigMapName = "nfd-master-safeguard"
+
+// Data fields of the safeguard ConfigMap.
+const (
+	safeguardKeyTrip           = "trip"
+	safeguardKeyMessage        = "message"
+	safeguardKeyAcknowledged   = "acknowledged"
+	safeguardKeyAcknowledgedAt = "acknowledgedAt"
+)
+
+// safeguardTrip describes the change that caused the safeguard to pause
+// node updates.
+type safeguardTrip struct {
+	change nodeChange
+	// node is the node whose update would have exceeded the limit
+	node      *corev1.Node
+	nodeCount int
+	limit     int
+	window    time.Duration
+	time      time.Time
+}
+
+// safeguardTripRecord is the serialized form of safeguardTrip stored in the
+// safeguard ConfigMap.
+type safeguardTripRecord struct {
+	OutputType string          `json:"outputType"`
+	Name       string          `json:"name"`
+	Operation  string          `json:"operation"`
+	Node       string          `json:"node"`
+	NodeCount  int             `json:"nodeCount"`
+	Limit      int             `json:"limit"`
+	Window     metav1.Duration `json:"window"`
+	Time       metav1.Time     `json:"time"`
+}
+
+func (t *safeguardTrip) record() safeguardTripRecord {
+	return safeguardTripRecord{
+		OutputType: t.change.outputType,
+		Name:       t.change.name,
+		Operation:  t.change.op,
+		Node:       t.node.Name,
+		NodeCount:  t.nodeCount,
+		Limit:      t.limit,
+		Window:     metav1.Duration{Duration: t.window},
+		Time:       metav1.NewTime(t.time),
+	}
+}
+
+func (r *safeguardTripRecord) trip() *safeguardTrip {
+	return &safeguardTrip{
+		change:    nodeChange{outputType: r.OutputType, name: r.Name, op: r.Operation},
+		node:      &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: r.Node}},
+		nodeCount: r.NodeCount,
+		limit:     r.Limit,
+		window:    r.Window.Duration,
+		time:      r.Time.Time,
+	}
+}
+
+func (t *safeguardTrip) message() string {
+	verb := "added to"
+	if t.change.op == nodeChangeRemoved {
+		verb = "removed from"
+	}
+	return fmt.Sprintf("%s %q would be %s %d nodes within %v, exceeding the limit of %d nodes",
+		t.change.outputType, t.change.name, verb, t.nodeCount, t.window, t.limit)
+}
+
+// nodeUpdateSafeguard limits the number of nodes that may lose a given label
+// or gain a given NoSchedule or NoExecute taint within a time window. When the
+// limit is exceeded all node updates are paused until the trip is
+// acknowledged.
+type nodeUpdateSafeguard struct {
+	sync.Mutex
+	// nodeCount is the number of nodes in the cluster, used for calculating
+	// the percentage limit
+	nodeCount int
+	// changes holds the nodes each change has been applied to, and when
+	changes map[nodeChange]map[string]time.Time
+	// acknowledged holds the changes exempted from the limit after an
+	// acknowledgement, and when the exemption expires
+	acknowledged map[nodeChange]time.Time
+	// trip is non-nil when node updates are paused
+	trip           *safeguardTrip
+	transitionTime time.Time
+}
+
+func newNodeUpdateSafeguard() *nodeUpdateSafeguard {
+	return &nodeUpdateSafeguard{
+		changes:        make(map[nodeChange]map[string]time.Time),
+		acknowledged:   make(map[nodeChange]time.Time),
+		transitionTime: time.Now(),
+	}
+}
+
+// setNodeCount updates the number of nodes in the cluster.
+func (s *nodeUpdateSafeguard) setNodeCount(n int) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.nodeCount = n
+}
+
+// check determines whether changes may be applied to the node. The changes are
+// recorded if allowed. Returns false if node updates are paused. The returned
+// trip is non-nil if the changes caused the safeguard to trip.
+func (s *nodeUpdateSafeguard) check(node *corev1.Node, changes []nodeChange, config SafeguardConfig, now time.Time) (bool, *safeguardTrip) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.trip != nil {
+		return false, nil
+	}
+	if !config.enabled() {
+		return true, nil
+	}
+
+	// Drop changes that have fallen out of the window
+	for c, nodes := range s.changes {
+		for n, t := range nodes {
+			if now.Sub(t) >= config.Window.Duration {
+				delete(nodes, n)
+			}
+		}
+		if len(nodes) == 0 {
+			delete(s.changes, c)
+		}
+	}
+	for c, expiry := range s.acknowledged {
+		if !now.Before(expiry) {
+			delete(s.acknowledged, c)
+		}
+	}
+
+	limit := config.limit(s.nodeCount)
+	for _, c := range changes {
+		if _, ok := s.acknowledged[c]; ok {
+			continue
+		}
+		nodes := s.changes[c]
+		if _, ok := nodes[node.Name]; ok {
+			continue
+		}
+		if limit >= 0 && len(nodes)+1 > limit {
+			s.trip = &safeguardTrip{
+				change:    c,
+				node:      node,
+				nodeCount: len(nodes) + 1,
+				limit:     limit,
+				window:    config.Window.Duration,
+				time:      now,
+			}
+			s.transitionTime = now
+			return false, s.trip
+		}
+	}
+
+	for _, c := range changes {
+		if s.changes[c] == nil {
+			s.changes[c] = make(map[string]time.Time)
+		}
+		s.changes[c][node.Name] = now
+	}
+	return true, nil
+}
+
+// setTrip pauses node updates because of a trip stored in the API, e.g. by
+// a previous nfd-master instance. Returns false if node updates were already
+// paused.
+func (s *nodeUpdateSafeguard) setTrip(trip *safeguardTrip) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.trip != nil {
+		return false
+	}
+	s.trip = trip
+	s.transitionTime = trip.time
+	return true
+}
+
+// acknowledge resumes node updates. The given change is exempted from the
+// limit until exemptUntil. Returns the trip that paused node updates, nil if
+// node updates were not paused.
+func (s *nodeUpdateSafeguard) acknowledge(change nodeChange, exemptUntil, now time.Time) *safeguardTrip {
+	s.Lock()
+	defer s.Unlock()
+
+	if exemptUntil.After(now) {
+		s.acknowledged[change] = exemptUntil
+	}
+	trip := s.trip
+	if trip == nil {
+		return nil
+	}
+	s.trip = nil
+	s.transitionTime = now
+	return trip
+}
+
+// condition returns the state of the safeguard as a condition.
+func (s *nodeUpdateSafeguard) condition() metav1.Condition {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.trip != nil {
+		return metav1.Condition{
+			Type:               SafeguardConditionNodeUpdatesAllowed,
+			Status:             metav1.ConditionFalse,
+			LastTransitionTime: metav1.NewTime(s.transitionTime),
+			Reason:             "SafeguardTripped",
+			Message:            s.trip.message() + "; node updates are paused until acknowledged",
+		}
+	}
+	return metav1.Condition{
+		Type:               SafeguardConditionNodeUpdatesAllowed,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(s.transitionTime),
+		Reason:             "WithinLimits",
+		Message:            "node updates are not paused",
+	}
+}
+
+// disruptiveNodeChanges returns the NFD-managed labels that would be removed
+// from the node and the NoSchedule and NoExecute taints that would be added
+// to it.
+func (m *nfdMaster) disruptiveNodeChanges(node *corev1.Node, labels Labels, taints []corev1.Taint) []nodeChange {
+	var changes []nodeChange
+
+	for _, name := range m.nfdManagedLabels(node) {
+		if _, ok := node.Labels[name]; !ok {
+			continue
+		}
+		if _, ok := labels[name]; !ok {
+			changes = append(changes, nodeChange{outputType: ruleOutputLabel, name: name, op: nodeChangeRemoved})
+		}
+	}
+
+	for _, taint := range taints {
+		if taint.Effect != corev1.TaintEffectNoSchedule && taint.Effect != corev1.TaintEffectNoExecute {
+			continue
+		}
+		if taintutils.TaintExists(node.Spec.Taints, &taint) {
+			continue
+		}
+		changes = append(changes, nodeChange{outputType: ruleOutputTaint, name: taint.Key + ":" + string(taint.Effect), op: nodeChangeAdded})
+	}
+
+	return changes
+}
+
+// checkSafeguard determines whether the node may be updated with the given
+// labels and taints. Reports the trip if the update causes the safeguard to
+// pause node updates.
+func (m *nfdMaster) checkSafeguard(node *corev1.Node, labels Labels, taints []corev1.Taint) bool {
+	changes := m.disruptiveNodeChanges(node, labels, taints)
+	allowed, trip := m.safeguard.check(node, changes, m.config().Safeguard, time.Now())
+	if trip != nil {
+		klog.ErrorS(errNodeUpdatesPaused, "safeguard limit exceeded, pausing node updates", "nodeName", node.Name, "reason", trip.message())
+		safeguardTripped.Set(1)
+		if m.nodeEventRecorder != nil {
+			m.nodeEventRecorder.Eventf(node, nil, corev1.EventTypeWarning, "NodeUpdatesPaused", nodeEventAction, "%s; node updates are paused until acknowledged", trip.message())
+		}
+		if err := m.storeSafeguardTrip(trip); err != nil {
+			klog.ErrorS(err, "failed to store the state of the safeguard", "configmap", klog.KRef(m.namespace, safeguardConfigMapName))
+		}
+	}
+	if !allowed {
+		klog.V(2).InfoS("node updates are paused by the safeguard, skipping node update", "nodeName", node.Name)
+		nodeUpdatesBlocked.Inc()
+	}
+	return allowed
+}
+
+// storeSafeguardTrip stores the trip in the safeguard ConfigMap, replacing
+// any earlier state.
+func (m *nfdMaster) storeSafeguardTrip(trip *safeguardTrip) error {
+	raw, err := json.Marshal(trip.record())
+	if err != nil {
+		return err
+	}
+	data := map[string]string{
+		safeguardKeyTrip:         string(raw),
+		safeguardKeyMessage:      trip.message(),
+		safeguardKeyAcknowledged: "false",
+	}
+
+	cli := m.k8sClient.CoreV1().ConfigMaps(m.namespace)
+	cm, err := cli.Get(context.TODO(), safeguardConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: safeguardConfigMapName, Namespace: m.namespace}, Data: data}
+		_, err = cli.Create(context.TODO(), cm, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+	cm.Data = data
+	_, err = cli.Update(context.TODO(), cm, metav1.UpdateOptions{})
+	return err
+}
+
+// safeguardConfigMapUpdated syncs the state of the safeguard from the
+// safeguard ConfigMap. Node updates are paused if the ConfigMap contains an
+// unacknowledged trip, and resumed when the trip is acknowledged.
+func (m *nfdMaster) safeguardConfigMapUpdated(cm *corev1.ConfigMap) {
+	var trip *safeguardTrip
+	if raw, ok := cm.Data[safeguardKeyTrip]; ok {
+		r := safeguardTripRecord{}
+		if err := json.Unmarshal([]byte(raw), &r); err != nil {
+			klog.ErrorS(err, "invalid safeguard state", "configmap", klog.KObj(cm))
+		} else {
+			trip = r.trip()
+		}
+	}
+
+	if cm.Data[safeguardKeyAcknowledged] != "true" {
+		if trip != nil && m.safeguard.setTrip(trip) {
+			klog.InfoS("safeguard tripped earlier, node updates are paused until acknowledged", "configmap", klog.KObj(cm), "reason", trip.message())
+			safeguardTripped.Set(1)
+		}
+		return
+	}
+
+	// The change that caused the trip is exempted from the limit for one
+	// window after the acknowledgement
+	now := time.Now()
+	acknowledgedAt := now
+	if t, err := time.Parse(time.RFC3339, cm.Data[safeguardKeyAcknowledgedAt]); err == nil {
+		acknowledgedAt = t
+	} else if m.isLeader {
+		m.storeSafeguardAcknowledgeTime(cm, now)
+	}
+	var change nodeChange
+	exemptUntil := time.Time{}
+	if trip != nil {
+		change = trip.change
+		exemptUntil = acknowledgedAt.Add(trip.window)
+	}
+	m.resumeNodeUpdates(m.safeguard.acknowledge(change, exemptUntil, now), "safeguard acknowledged")
+}
+
+// safeguardConfigMapDeleted resets the safeguard, resuming node updates.
+func (m *nfdMaster) safeguardConfigMapDeleted() {
+	m.resumeNodeUpdates(m.safeguard.acknowledge(nodeChange{}, time.Time{}, time.Now()), "safeguard state deleted")
+}
+
+// storeSafeguardAcknowledgeTime records the time of acknowledgement in the
+// safeguard ConfigMap.
+func (m *nfdMaster) storeSafeguardAcknowledgeTime(cm *corev1.ConfigMap, now time.Time) {
+	cm = cm.DeepCopy()
+	cm.Data[safeguardKeyAcknowledgedAt] = now.UTC().Format(time.RFC3339)
+	if _, err := m.k8sClient.CoreV1().ConfigMaps(cm.Namespace).Update(context.TODO(), cm, metav1.UpdateOptions{}); err != nil {
+		klog.ErrorS(err, "failed to store the state of the safeguard", "configmap", klog.KObj(cm))
+	}
+}
+
+// resumeNodeUpdates reports the resumption of node updates and re-processes
+// all nodes. Does nothing if trip is nil, i.e. node updates were not paused.
+func (m *nfdMaster) resumeNodeUpdates(trip *safeguardTrip, reason string) {
+	if trip == nil {
+		return
+	}
+	klog.InfoS("resuming node updates", "reason", reason, "trip", trip.message())
+	safeguardTripped.Set(0)
+	if m.nodeEventRecorder != nil {
+		m.nodeEventRecorder.Eventf(trip.node, nil, corev1.EventTypeNormal, "NodeUpdatesResumed", nodeEventAction, "%s, node updates resumed", reason)
+	}
+	// Re-process the nodes whose update was skipped
+	if m.nfdController != nil {
+		m.nfdController.updateAllNodes()
+	}
+}
+
+// startSafeguardInformer starts watching the safeguard ConfigMap, and waits
+// until its initial state has been synced.
+func (m *nfdMaster) startSafeguardInformer() error {
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(m.k8sClient, 0,
+		informers.WithNamespace(m.namespace),
+		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+			o.FieldSelector = fields.OneTermEqualSelector("metadata.name", safeguardConfigMapName).String()
+		}))
+	informer := informerFactory.Core().V1().ConfigMaps().Informer()
+	if _, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			m.safeguardConfigMapUpdated(obj.(*corev1.ConfigMap))
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			m.safeguardConfigMapUpdated(newObj.(*corev1.ConfigMap))
+		},
+		DeleteFunc: func(_ interface{}) {
+			m.safeguardConfigMapDeleted()
+		},
+	}); err != nil {
+		return err
+	}
+	informerFactory.Start(m.stop)
+	if !cache.WaitForCacheSync(m.stop, informer.HasSynced) {
+		return fmt.Errorf("failed to sync the state of the safeguard")
+	}
+	return nil
+}
+
+// safeguardStatusHandler serves the state of the safeguard. Responds with
+// 503 Service Unavailable if node updates are paused.
+func (m *nfdMaster) safeguardStatusHandler(w http.ResponseWriter, _ *http.Request) {
+	writeSafeguardCondition(w, m.safeguard.condition())
+}
+
+func writeSafeguardCondition(w http.ResponseWriter, cond metav1.Condition) {
+	w.Header().Set("Content-Type", "application/json")
+	if cond.Status != metav1.ConditionTrue {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(cond); err != nil {
+		klog.ErrorS(err, "failed to write safeguard status")
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdmaster
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+)
+
+func newSafeguardTestNode(i int) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)}}
+}
+
+func TestSafeguardConfigLimit(t *testing.T) {
+	tcs := []struct {
+		config    SafeguardConfig
+		nodeCount int
+		expected  int
+	}{
+		{config: SafeguardConfig{}, nodeCount: 100, expected: -1},
+		{config: SafeguardConfig{MaxNodes: 5}, nodeCount: 100, expected: 5},
+		{config: SafeguardConfig{MaxNodesPercent: 10}, nodeCount: 100, expected: 10},
+		{config: SafeguardConfig{MaxNodesPercent: 10}, nodeCount: 15, expected: 2},
+		{config: SafeguardConfig{MaxNodesPercent: 10}, nodeCount: 0, expected: -1},
+		{config: SafeguardConfig{MaxNodes: 5, MaxNodesPercent: 10}, nodeCount: 100, expected: 5},
+		{config: SafeguardConfig{MaxNodes: 20, MaxNodesPercent: 10}, nodeCount: 100, expected: 10},
+	}
+	for _, tc := range tcs {
+		assert.Equal(t, tc.expected, tc.config.limit(tc.nodeCount), "%+v, %d nodes", tc.config, tc.nodeCount)
+	}
+}
+
+func TestNodeUpdateSafeguard(t *testing.T) {
+	config := SafeguardConfig{MaxNodes: 2, Window: utils.DurationVal{Duration: time.Minute}}
+	labelRemoved := nodeChange{outputType: ruleOutputLabel, name: "example.com/foo", op: nodeChangeRemoved}
+	taintAdded := nodeChange{outputType: ruleOutputTaint, name: "example.com/t:NoExecute", op: nodeChangeAdded}
+	now := time.Now()
+
+	s := newNodeUpdateSafeguard()
+	for i := 0; i < 2; i++ {
+		allowed, trip := s.check(newSafeguardTestNode(i), []nodeChange{labelRemoved}, config, now)
+		assert.True(t, allowed)
+		assert.Nil(t, trip)
+	}
+	// Re-applying a change to the same node does not count
+	allowed, _ := s.check(newSafeguardTestNode(0), []nodeChange{labelRemoved}, config, now)
+	assert.True(t, allowed)
+	// Other changes are counted separately
+	allowed, _ = s.check(newSafeguardTestNode(2), []nodeChange{taintAdded}, config, now)
+	assert.True(t, allowed)
+	assert.Equal(t, metav1.ConditionTrue, s.condition().Status)
+
+	// Third node exceeds the limit
+	allowed, trip := s.check(newSafeguardTestNode(3), []nodeChange{taintAdded, labelRemoved}, config, now)
+	assert.False(t, allowed)
+	assert.NotNil(t, trip)
+	assert.Equal(t, labelRemoved, trip.change)
+	assert.Equal(t, "node-3", trip.node.Name)
+	assert.Equal(t, `label "example.com/foo" would be removed from 3 nodes within 1m0s, exceeding the limit of 2 nodes`, trip.message())
+	cond := s.condition()
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, "SafeguardTripped", cond.Reason)
+
+	// All updates are paused until acknowledged
+	allowed, trip = s.check(newSafeguardTestNode(4), nil, config, now)
+	assert.False(t, allowed)
+	assert.Nil(t, trip)
+
+	assert.NotNil(t, s.acknowledge(labelRemoved, now.Add(time.Minute), now))
+	assert.Nil(t, s.acknowledge(labelRemoved, now.Add(time.Minute), now))
+	assert.Equal(t, metav1.ConditionTrue, s.condition().Status)
+
+	// Acknowledged change is exempted from the limit for one window
+	for i := 3; i < 6; i++ {
+		allowed, _ = s.check(newSafeguardTestNode(i), []nodeChange{labelRemoved}, config, now.Add(30*time.Second))
+		assert.True(t, allowed)
+	}
+	allowed, _ = s.check(newSafeguardTestNode(6), []nodeChange{taintAdded}, config, now.Add(30*time.Second))
+	assert.True(t, allowed)
+	allowed, trip = s.check(newSafeguardTestNode(7), []nodeChange{taintAdded}, config, now.Add(30*time.Second))
+	assert.False(t, allowed)
+	assert.Equal(t, taintAdded, trip.change)
+	s.acknowledge(taintAdded, now.Add(90*time.Second), now.Add(30*time.Second))
+
+	// Changes fall out of the window
+	later := now.Add(2 * time.Minute)
+	for i := 10; i < 12; i++ {
+		allowed, _ = s.check(newSafeguardTestNode(i), []nodeChange{labelRemoved}, config, later)
+		assert.True(t, allowed)
+	}
+	allowed, _ = s.check(newSafeguardTestNode(12), []nodeChange{labelRemoved}, config, later)
+	assert.False(t, allowed)
+
+	// Disabled safeguard allows everything
+	s = newNodeUpdateSafeguard()
+	for i := 0; i < 10; i++ {
+		allowed, _ = s.check(newSafeguardTestNode(i), []nodeChange{labelRemoved}, SafeguardConfig{}, now)
+		assert.True(t, allowed)
+	}
+}
+
+func TestDisruptiveNodeChanges(t *testing.T) {
+	m := newFakeMaster()
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+			Labels: map[string]string{
+				nfdv1alpha1.FeatureLabelNs + "/foo": "true",
+				nfdv1alpha1.FeatureLabelNs + "/bar": "true",
+				"example.com/baz":                   "true",
+				"example.com/not-nfd":               "true",
+			},
+			Annotations: map[string]string{
+				nfdv1alpha1.FeatureLabelsAnnotation: "foo,bar,example.com/baz,example.com/missing",
+			},
+		},
+		Spec: corev1.NodeSpec{
+			Taints: []corev1.Taint{{Key: "example.com/existing", Effect: corev1.TaintEffectNoSchedule}},
+		},
+	}
+	labels := Labels{nfdv1alpha1.FeatureLabelNs + "/foo": "false"}
+	taints := []corev1.Taint{
+		{Key: "example.com/existing", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "example.com/new", Effect: corev1.TaintEffectNoExecute},
+		{Key: "example.com/soft", Effect: corev1.TaintEffectPreferNoSchedule},
+	}
+
+	assert.ElementsMatch(t, []nodeChange{
+		{outputType: ruleOutputLabel, name: nfdv1alpha1.FeatureLabelNs + "/bar", op: nodeChangeRemoved},
+		{outputType: ruleOutputLabel, name: "example.com/baz", op: nodeChangeRemoved},
+		{outputType: ruleOutputTaint, name: "example.com/new:NoExecute", op: nodeChangeAdded},
+	}, m.disruptiveNodeChanges(node, labels, taints))
+}
+
+func TestSafeguardConfigMap(t *testing.T) {
+	m := newFakeMaster()
+	m.config().Safeguard = SafeguardConfig{MaxNodes: 1, Window: utils.DurationVal{Duration: time.Minute}}
+	tripNodes := func(m *nfdMaster) []bool {
+		allowed := []bool{}
+		for i := 0; i < 2; i++ {
+			node := newSafeguardTestNode(i)
+			node.Labels = map[string]string{"example.com/foo": "true"}
+			node.Annotations = map[string]string{nfdv1alpha1.FeatureLabelsAnnotation: "example.com/foo"}
+			allowed = append(allowed, m.checkSafeguard(node, Labels{}, nil))
+		}
+		return allowed
+	}
+	assert.Equal(t, []bool{true, false}, tripNodes(m))
+
+	w := httptest.NewRecorder()
+	m.safeguardStatusHandler(w, httptest.NewRequest(http.MethodGet, "/safeguard", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"False"`)
+
+	// Trip is stored in the API
+	cm, err := m.k8sClient.CoreV1().ConfigMaps(m.namespace).Get(context.TODO(), safeguardConfigMapName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "false", cm.Data[safeguardKeyAcknowledged])
+	assert.Contains(t, cm.Data[safeguardKeyMessage], `label "example.com/foo" would be removed from 2 nodes`)
+
+	// Another instance (e.g. after restart or failover) restores the trip
+	m2 := newFakeMaster(WithKubernetesClient(m.k8sClient))
+	m2.config().Safeguard = m.config().Safeguard
+	m2.isLeader = true
+	m2.safeguardConfigMapUpdated(cm)
+	assert.Equal(t, metav1.ConditionFalse, m2.safeguard.condition().Status)
+	assert.Equal(t, []bool{false, false}, tripNodes(m2))
+
+	// Acknowledging the trip in the ConfigMap resumes node updates and
+	// exempts the change from the limit
+	cm.Data[safeguardKeyAcknowledged] = "true"
+	cm, err = m.k8sClient.CoreV1().ConfigMaps(m.namespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	m2.safeguardConfigMapUpdated(cm)
+	assert.Equal(t, metav1.ConditionTrue, m2.safeguard.condition().Status)
+	assert.Equal(t, []bool{true, true}, tripNodes(m2))
+	cm, err = m.k8sClient.CoreV1().ConfigMaps(m.namespace).Get(context.TODO(), safeguardConfigMapName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, cm.Data[safeguardKeyAcknowledgedAt])
+
+	// Deleting the ConfigMap resets the safeguard
+	m.safeguardConfigMapDeleted()
+	assert.Equal(t, metav1.ConditionTrue, m.safeguard.condition().Status)
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdmaster
+
+import (
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+)
+
+// sourceStatusFeature is the name of the attribute feature containing the
+// discovery status of the feature sources of a node, available in
+// NodeFeatureRules and NodeFeatureGroups.
+const sourceStatusFeature = "nfd.sourceStatus"
+
+// Values of the sourceStatusFeature elements.
+const (
+	sourceStatusOk     = "ok"
+	sourceStatusStale  = "stale"
+	sourceStatusFailed = "failed"
+)
+
+// sourceSnapshot contains the features and labels of one feature source from
+// the last NodeFeature object where the discovery of the source was
+// successful. The snapshots are only kept in memory, i.e. they are lost when
+// nfd-master restarts.
+type sourceSnapshot struct {
+	features *nfdv1alpha1.Features
+	labels   map[string]string
+}
+
+// newSourceSnapshot extracts the features and labels of a feature source from
+// a NodeFeatureSpec. The features of a source are identified by the
+// "<source>." prefix of the feature name and the labels by the list of label
+// names reported in the status of the source.
+func newSourceSnapshot(spec *nfdv1alpha1.NodeFeatureSpec, status *nfdv1alpha1.FeatureSourceStatus) *sourceSnapshot {
+	featurePrefix := status.Name + "."
+
+	s := &sourceSnapshot{features: nfdv1alpha1.NewFeatures(), labels: make(map[string]string)}
+	for k, v := range spec.Features.Flags {
+		if strings.HasPrefix(k, featurePrefix) {
+			s.features.Flags[k] = *v.DeepCopy()
+		}
+	}
+	for k, v := range spec.Features.Attributes {
+		if strings.HasPrefix(k, featurePrefix) {
+			s.features.Attributes[k] = *v.DeepCopy()
+		}
+	}
+	for k, v := range spec.Features.Instances {
+		if strings.HasPrefix(k, featurePrefix) {
+			s.features.Instances[k] = *v.DeepCopy()
+		}
+	}
+	for _, name := range status.Labels {
+		if v, ok := spec.Labels[name]; ok {
+			s.labels[name] = v
+		}
+	}
+	return s
+}
+
+// restoreInto replaces the features and labels of a feature source in a
+// NodeFeatureSpec with the ones from the snapshot.
+func (s *sourceSnapshot) restoreInto(spec *nfdv1alpha1.NodeFeatureSpec, status *nfdv1alpha1.FeatureSourceStatus) {
+	current := newSourceSnapshot(spec, status)
+	for k := range current.features.Flags {
+		delete(spec.Features.Flags, k)
+	}
+	for k := range current.features.Attributes {
+		delete(spec.Features.Attributes, k)
+	}
+	for k := range current.features.Instances {
+		delete(spec.Features.Instances, k)
+	}
+	for k := range current.labels {
+		delete(spec.Labels, k)
+	}
+
+	s.features.DeepCopy().MergeInto(&spec.Features)
+	if len(s.labels) > 0 && spec.Labels == nil {
+		spec.Labels = make(map[string]string, len(s.labels))
+	}
+	for k, v := range s.labels {
+		spec.Labels[k] = v
+	}
+}
+
+// restoreFailedSources replaces the features and labels of the feature
+// sources whose discovery failed, as reported in the status of the
+// NodeFeature object, with the ones from the last successful discovery seen
+// by nfd-master. This way a failed discovery does not cause labels to be
+// removed from the node. The snapshots of the successfully discovered sources
+// are updated.
+func (m *nfdMaster) restoreFailedSources(nodeName string, obj *nfdv1alpha1.NodeFeature, spec *nfdv1alpha1.NodeFeatureSpec) {
+	for i := range obj.Status.Sources {
+		s := &obj.Status.Sources[i]
+		key := obj.Namespace + "/" + obj.Name + "/" + s.Name
+		if s.Error == "" {
+			m.nodeFeatures.setSourceSnapshot(nodeName, key, newSourceSnapshot(spec, s))
+			continue
+		}
+
+		snapshot := m.nodeFeatures.getSourceSnapshot(nodeName, key)
+		if snapshot == nil {
+			klog.InfoS("feature discovery failed, no earlier features available", "nodeName", nodeName, "nodefeature", klog.KObj(obj), "featureSource", s.Name, "error", s.Error)
+			continue
+		}
+		klog.InfoS("feature discovery failed, keeping last known features", "nodeName", nodeName, "nodefeature", klog.KObj(obj), "featureSource", s.Name, "error", s.Error)
+		snapshot.restoreInto(spec, s)
+	}
+}
+
+// addSourceStatusFeature adds the discovery status of the feature sources
+// reported in the NodeFeature objects as the sourceStatusFeature attribute
+// feature.
+func addSourceStatusFeature(features *nfdv1alpha1.Features, objs []*nfdv1alpha1.NodeFeature) {
+	elements := make(map[string]string)
+	for _, o := range objs {
+		for _, s := range o.Status.Sources {
+			switch {
+			case s.Error == "":
+				elements[s.Name] = sourceStatusOk
+			case s.Stale:
+				elements[s.Name] = sourceStatusStale
+			default:
+				elements[s.Name] = sourceStatusFailed
+			}
+		}
+	}
+	if len(elements) == 0 {
+		return
+	}
+	if features.Attributes == nil {
+		features.Attributes = make(map[string]nfdv1alpha1.AttributeFeatureSet)
+	}
+	features.Attributes[sourceStatusFeature] = nfdv1alpha1.NewAttributeFeatures(elements)
+}
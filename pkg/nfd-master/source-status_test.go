@@ -0,0 +1,104 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdmaster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+)
+
+func newSourceStatusTestNodeFeature(nodeName string, pciFeatures bool, pciStatus nfdv1alpha1.FeatureSourceStatus) *nfdv1alpha1.NodeFeature {
+	nf := &nfdv1alpha1.NodeFeature{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName, Namespace: "nfd"},
+		Spec: nfdv1alpha1.NodeFeatureSpec{
+			Features: *nfdv1alpha1.NewFeatures(),
+			Labels: map[string]string{
+				"feature.node.kubernetes.io/cpu-model.family": "6",
+			},
+		},
+		Status: nfdv1alpha1.NodeFeatureStatus{Sources: []nfdv1alpha1.FeatureSourceStatus{
+			{Name: "cpu", Labels: []string{"feature.node.kubernetes.io/cpu-model.family"}},
+		}},
+	}
+	nf.Spec.Features.Attributes["cpu.model"] = nfdv1alpha1.NewAttributeFeatures(map[string]string{"family": "6"})
+	if pciFeatures {
+		nf.Spec.Features.Instances["pci.device"] = nfdv1alpha1.NewInstanceFeatures(*nfdv1alpha1.NewInstanceFeature(map[string]string{"class": "0300"}))
+		// Label names of the source do not need to follow any convention
+		nf.Spec.Labels["feature.node.kubernetes.io/pci-0300.present"] = "true"
+		nf.Spec.Labels["example.com/gpu"] = "true"
+		pciStatus.Labels = []string{"example.com/gpu", "feature.node.kubernetes.io/pci-0300.present"}
+	}
+	nf.Status.Sources = append(nf.Status.Sources, pciStatus)
+	return nf
+}
+
+func TestRestoreFailedSources(t *testing.T) {
+	m := newFakeMaster()
+	m.namespace = "nfd"
+
+	// Successful discovery
+	merged := m.mergeNodeFeatures("node-1", []*nfdv1alpha1.NodeFeature{
+		newSourceStatusTestNodeFeature("node-1", true, nfdv1alpha1.FeatureSourceStatus{Name: "pci"}),
+	})
+	assert.Contains(t, merged.Spec.Features.Instances, "pci.device")
+	assert.Equal(t, map[string]string{"cpu": "ok", "pci": "ok"}, merged.Spec.Features.Attributes[sourceStatusFeature].Elements)
+
+	// Failed discovery keeps the last known features and labels of the source
+	merged = m.mergeNodeFeatures("node-1", []*nfdv1alpha1.NodeFeature{
+		newSourceStatusTestNodeFeature("node-1", false, nfdv1alpha1.FeatureSourceStatus{Name: "pci", Error: "failed to read sysfs"}),
+	})
+	assert.Equal(t, "0300", merged.Spec.Features.Instances["pci.device"].Elements[0].Attributes["class"])
+	assert.Equal(t, "true", merged.Spec.Labels["feature.node.kubernetes.io/pci-0300.present"])
+	assert.Equal(t, "true", merged.Spec.Labels["example.com/gpu"])
+	assert.Equal(t, "6", merged.Spec.Labels["feature.node.kubernetes.io/cpu-model.family"])
+	assert.Equal(t, map[string]string{"cpu": "ok", "pci": "failed"}, merged.Spec.Features.Attributes[sourceStatusFeature].Elements)
+
+	// Stale source
+	merged = m.mergeNodeFeatures("node-1", []*nfdv1alpha1.NodeFeature{
+		newSourceStatusTestNodeFeature("node-1", false, nfdv1alpha1.FeatureSourceStatus{Name: "pci", Error: "timed out", Stale: true}),
+	})
+	assert.Contains(t, merged.Spec.Features.Instances, "pci.device")
+	assert.Equal(t, "stale", merged.Spec.Features.Attributes[sourceStatusFeature].Elements["pci"])
+
+	// Successful discovery without the features removes them
+	merged = m.mergeNodeFeatures("node-1", []*nfdv1alpha1.NodeFeature{
+		newSourceStatusTestNodeFeature("node-1", false, nfdv1alpha1.FeatureSourceStatus{Name: "pci"}),
+	})
+	assert.NotContains(t, merged.Spec.Features.Instances, "pci.device")
+	assert.NotContains(t, merged.Spec.Labels, "feature.node.kubernetes.io/pci-0300.present")
+	assert.NotContains(t, merged.Spec.Labels, "example.com/gpu")
+	merged = m.mergeNodeFeatures("node-1", []*nfdv1alpha1.NodeFeature{
+		newSourceStatusTestNodeFeature("node-1", false, nfdv1alpha1.FeatureSourceStatus{Name: "pci", Error: "failed to read sysfs"}),
+	})
+	assert.NotContains(t, merged.Spec.Features.Instances, "pci.device")
+
+	// Nodes are tracked separately
+	merged = m.mergeNodeFeatures("node-2", []*nfdv1alpha1.NodeFeature{
+		newSourceStatusTestNodeFeature("node-2", false, nfdv1alpha1.FeatureSourceStatus{Name: "pci", Error: "failed to read sysfs"}),
+	})
+	assert.NotContains(t, merged.Spec.Features.Instances, "pci.device")
+
+	// NodeFeature objects without status
+	nf := newSourceStatusTestNodeFeature("node-3", true, nfdv1alpha1.FeatureSourceStatus{})
+	nf.Status = nfdv1alpha1.NodeFeatureStatus{}
+	merged = m.mergeNodeFeatures("node-3", []*nfdv1alpha1.NodeFeature{nf})
+	assert.NotContains(t, merged.Spec.Features.Attributes, sourceStatusFeature)
+}
@@ -17,26 +17,43 @@ limitations under the License.
 package nfdmaster
 
 import (
+	"context"
+	"errors"
 	"sync"
 	"time"
 
 	"golang.org/x/time/rate"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	k8sclient "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	nfdclientset "sigs.k8s.io/node-feature-discovery/api/generated/clientset/versioned"
-	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
 )
 
+// resyncPollInterval is the interval of checking if the node update queue
+// has capacity for nodes queued for resync.
+const resyncPollInterval = 100 * time.Millisecond
+
 type updaterPool struct {
 	started  bool
 	queue    workqueue.TypedRateLimitingInterface[string]
 	nfgQueue workqueue.TypedRateLimitingInterface[string]
+	// resyncQueue holds the nodes queued for a full resync. They are moved
+	// to queue only when it has spare capacity, giving priority to nodes
+	// whose own NodeFeature objects have changed.
+	resyncQueue workqueue.TypedInterface[string]
+	parallelism int
+	// ctx is cancelled when the pool is stopped. It is used to abort
+	// waiting for capacity in the update queue or for the node update
+	// rate limiter.
+	ctx    context.Context
+	cancel context.CancelFunc
 	sync.RWMutex
 
 	wg        sync.WaitGroup
 	nfgWg     sync.WaitGroup
+	resyncWg  sync.WaitGroup
 	nfdMaster *nfdMaster
 }
 
@@ -55,12 +72,25 @@ func (u *updaterPool) processNodeUpdateRequest(cli k8sclient.Interface) bool {
 
 	defer u.queue.Done(nodeName)
 
+	// The queue hands out the remaining items after shutdown, drop them
+	if u.ctx.Err() != nil {
+		return false
+	}
+
 	nodeUpdateRequests.Inc()
 
 	// Check if node exists
 	if node, err := getNode(cli, nodeName); apierrors.IsNotFound(err) {
 		klog.InfoS("node not found, skip update", "nodeName", nodeName)
-	} else if err := u.nfdMaster.nfdAPIUpdateOneNode(cli, node); err != nil {
+		u.nfdMaster.nfrStatus.removeNode(nodeName)
+		u.nfdMaster.nodeFeatures.removeNode(nodeName)
+		u.nfdMaster.removeNodeFromNodeFeatureGroups(nodeName)
+		u.nfdMaster.nodeEventLimiter.removeNode(nodeName)
+		u.nfdMaster.labelFlaps.removeNode(nodeName)
+	} else if err := u.nfdMaster.nfdAPIUpdateOneNode(cli, node); errors.Is(err, errNodeUpdatesPaused) {
+		// All nodes are re-processed when node updates are resumed
+		klog.V(2).InfoS("node updates are paused, skip update", "nodeName", nodeName)
+	} else if err != nil {
 		if n := u.queue.NumRequeues(nodeName); n < 15 {
 			klog.InfoS("retrying node update", "nodeName", nodeName, "lastError", err, "numRetries", n)
 		} else {
@@ -89,31 +119,97 @@ func (u *updaterPool) runNodeUpdater() {
 	u.wg.Done()
 }
 
+// runResyncFeeder moves nodes from the resync queue to the node update queue
+// whenever the node update queue has fewer items than there are updaters.
+// Nodes still pending in the resync queue are dropped when the pool is
+// stopped.
+func (u *updaterPool) runResyncFeeder() {
+	defer u.resyncWg.Done()
+	for {
+		nodeName, quit := u.resyncQueue.Get()
+		if quit {
+			return
+		}
+		if !u.waitQueueCapacity() {
+			u.resyncQueue.Done(nodeName)
+			return
+		}
+		u.queue.Add(nodeName)
+		u.resyncQueue.Done(nodeName)
+	}
+}
+
+// waitQueueCapacity waits until the node update queue has fewer items than
+// there are updaters. Returns false if the pool was stopped while waiting.
+func (u *updaterPool) waitQueueCapacity() bool {
+	for {
+		select {
+		case <-u.ctx.Done():
+			return false
+		default:
+		}
+		if u.queue.Len() < u.parallelism {
+			return true
+		}
+		select {
+		case <-u.ctx.Done():
+			return false
+		case <-time.After(resyncPollInterval):
+		}
+	}
+}
+
+// context returns a context that is cancelled when the pool is stopped.
+func (u *updaterPool) context() context.Context {
+	if u == nil || u.ctx == nil {
+		return context.Background()
+	}
+	return u.ctx
+}
+
 func (u *updaterPool) processNodeFeatureGroupUpdateRequest(cli nfdclientset.Interface) bool {
-	nfgName, quit := u.nfgQueue.Get()
+	nfgKey, quit := u.nfgQueue.Get()
 	if quit {
 		return false
 	}
-	defer u.nfgQueue.Done(nfgName)
+	defer u.nfgQueue.Done(nfgKey)
 
 	nodeFeatureGroupUpdateRequests.Inc()
 
+	namespace, name, err := cache.SplitMetaNamespaceKey(nfgKey)
+	if err != nil {
+		klog.ErrorS(err, "invalid NodeFeatureGroup key", "key", nfgKey)
+		u.nfgQueue.Forget(nfgKey)
+		return true
+	}
+	if !u.nfdMaster.isNodeFeatureGroupNamespaceSelected(namespace) {
+		klog.V(2).InfoS("NodeFeatureGroup namespace is not selected, skipping", "nodeFeatureGroup", klog.KRef(namespace, name))
+		u.nfdMaster.nfgMembership.forget(nfgKey)
+		u.nfgQueue.Forget(nfgKey)
+		return true
+	}
+
 	// Check if NodeFeatureGroup exists
-	var nfg *nfdv1alpha1.NodeFeatureGroup
-	var err error
-	if nfg, err = getNodeFeatureGroup(cli, u.nfdMaster.namespace, nfgName); apierrors.IsNotFound(err) {
-		klog.InfoS("NodeFeatureGroup not found, skip update", "NodeFeatureGroupName", nfgName)
-	} else if err := u.nfdMaster.nfdAPIUpdateNodeFeatureGroup(u.nfdMaster.nfdClient, nfg); err != nil {
-		if n := u.nfgQueue.NumRequeues(nfgName); n < 15 {
-			klog.InfoS("retrying NodeFeatureGroup update", "nodeFeatureGroup", klog.KObj(nfg), "lastError", err)
+	nfg, err := getNodeFeatureGroup(cli, namespace, name)
+	if apierrors.IsNotFound(err) {
+		klog.InfoS("NodeFeatureGroup not found, skip update", "nodeFeatureGroup", klog.KRef(namespace, name))
+		u.nfdMaster.nfgMembership.forget(nfgKey)
+		u.nfgQueue.Forget(nfgKey)
+		return true
+	} else if err == nil {
+		err = u.nfdMaster.nfdAPIUpdateNodeFeatureGroup(u.nfdMaster.nfdClient, nfg)
+	}
+	if err != nil {
+		if n := u.nfgQueue.NumRequeues(nfgKey); n < 15 {
+			klog.InfoS("retrying NodeFeatureGroup update", "nodeFeatureGroup", klog.KRef(namespace, name), "lastError", err)
 		} else {
-			klog.ErrorS(err, "failed to update NodeFeatureGroup, queueing for retry", "nodeFeatureGroup", klog.KObj(nfg), "lastError", err, "numRetries", n)
+			klog.ErrorS(err, "failed to update NodeFeatureGroup, queueing for retry", "nodeFeatureGroup", klog.KRef(namespace, name), "lastError", err, "numRetries", n)
 		}
-		u.nfgQueue.AddRateLimited(nfgName)
+		u.nfgQueue.AddRateLimited(nfgKey)
 		return true
 	}
 
-	u.nfgQueue.Forget(nfgName)
+	u.nfgQueue.Forget(nfgKey)
 	return true
 }
 
@@ -150,6 +246,12 @@ func (u *updaterPool) start(parallelism int) {
 	)
 	u.queue = workqueue.NewTypedRateLimitingQueue[string](rl)
 	u.nfgQueue = workqueue.NewTypedRateLimitingQueue[string](rl)
+	u.resyncQueue = workqueue.NewTyped[string]()
+	u.parallelism = parallelism
+	u.ctx, u.cancel = context.WithCancel(context.Background())
+
+	u.resyncWg.Add(1)
+	go u.runResyncFeeder()
 
 	for range parallelism {
 		u.wg.Add(1)
@@ -170,6 +272,9 @@ func (u *updaterPool) stop() {
 	}
 
 	klog.InfoS("stopping the NFD master updater pool")
+	u.cancel()
+	u.resyncQueue.ShutDown()
+	u.resyncWg.Wait()
 	u.queue.ShutDown()
 	u.wg.Wait()
 	u.nfgQueue.ShutDown()
@@ -183,14 +288,38 @@ func (u *updaterPool) running() bool {
 	return u.started
 }
 
+// addNode queues a node for update, e.g. when the NodeFeature objects of the
+// node have changed. Repeated updates of the same node within the coalesce
+// period are merged into one.
 func (u *updaterPool) addNode(nodeName string) {
 	u.RLock()
 	defer u.RUnlock()
-	u.queue.Add(nodeName)
+	if d := u.nfdMaster.config().NodeUpdates.CoalescePeriod.Duration; d > 0 {
+		u.queue.AddAfter(nodeName, d)
+	} else {
+		u.queue.Add(nodeName)
+	}
+}
+
+// addNodeResync queues a node for update as part of updating all nodes. The
+// node is processed with a lower priority than nodes queued with addNode.
+func (u *updaterPool) addNodeResync(nodeName string) {
+	u.RLock()
+	defer u.RUnlock()
+	u.resyncQueue.Add(nodeName)
+}
+
+// addNodeAfter queues a node for update after the given delay.
+func (u *updaterPool) addNodeAfter(nodeName string, delay time.Duration) {
+	u.RLock()
+	defer u.RUnlock()
+	u.queue.AddAfter(nodeName, delay)
 }
 
-func (u *updaterPool) addNodeFeatureGroup(nodeFeatureGroupName string) {
+// addNodeFeatureGroup queues a NodeFeatureGroup for update. The group is
+// identified by its <namespace>/<name> key.
+func (u *updaterPool) addNodeFeatureGroup(nodeFeatureGroupKey string) {
 	u.RLock()
 	defer u.RUnlock()
-	u.nfgQueue.Add(nodeFeatureGroupName)
+	u.nfgQueue.Add(nodeFeatureGroupKey)
 }
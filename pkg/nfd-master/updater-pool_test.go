@@ -17,12 +17,15 @@ limitations under the License.
 package nfdmaster
 
 import (
+	"context"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 	fakek8sclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/workqueue"
 	fakenfdclient "sigs.k8s.io/node-feature-discovery/api/generated/clientset/versioned/fake"
 )
 
@@ -113,3 +116,91 @@ func TestRunNodeFeatureGroupUpdater(t *testing.T) {
 			withTimeout, 2*time.Second, ShouldEqual, 0)
 	})
 }
+
+func TestNodeUpdatePriority(t *testing.T) {
+	fakeMaster := newFakeMaster()
+	updaterPool := newFakeupdaterPool(fakeMaster)
+	updaterPool.queue = workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[string]())
+	updaterPool.resyncQueue = workqueue.NewTyped[string]()
+	updaterPool.parallelism = 1
+	updaterPool.ctx, updaterPool.cancel = context.WithCancel(context.Background())
+	defer updaterPool.cancel()
+	updaterPool.resyncWg.Add(1)
+	go updaterPool.runResyncFeeder()
+	defer updaterPool.resyncQueue.ShutDown()
+
+	Convey("When nodes are queued for resync", t, func() {
+		for _, n := range []string{"node-1", "node-2", "node-3"} {
+			updaterPool.addNodeResync(n)
+		}
+		// Only as many nodes as there are updaters are moved to the update
+		// queue, the feeder holds the next one
+		So(func() interface{} { return updaterPool.resyncQueue.Len() },
+			withTimeout, 2*time.Second, ShouldEqual, 1)
+		So(updaterPool.queue.Len(), ShouldEqual, 1)
+
+		// Nodes with changed features bypass the resync queue
+		updaterPool.addNode("node-4")
+		So(updaterPool.queue.Len(), ShouldEqual, 2)
+		So(updaterPool.resyncQueue.Len(), ShouldEqual, 1)
+
+		// Resync continues when the update queue is drained
+		for _, expected := range []string{"node-1", "node-4"} {
+			n, _ := updaterPool.queue.Get()
+			So(n, ShouldEqual, expected)
+			updaterPool.queue.Done(n)
+		}
+		So(func() interface{} { return updaterPool.resyncQueue.Len() },
+			withTimeout, 2*time.Second, ShouldEqual, 0)
+		So(updaterPool.queue.Len(), ShouldEqual, 1)
+	})
+}
+
+func TestNodeUpdaterStopWithResyncBacklog(t *testing.T) {
+	fakeMaster := newFakeMaster(WithKubernetesClient(fakek8sclient.NewSimpleClientset()))
+	fakeMaster.nfdController = newFakeNfdAPIController(fakenfdclient.NewSimpleClientset())
+	updaterPool := newFakeupdaterPool(fakeMaster)
+
+	updaterPool.start(1)
+	Convey("When stopping the pool with a backlog of nodes queued for resync", t, func() {
+		for i := range 1000 {
+			updaterPool.addNodeResync(fmt.Sprintf("node-%d", i))
+		}
+		stopped := make(chan struct{})
+		go func() {
+			updaterPool.stop()
+			close(stopped)
+		}()
+		// Pending nodes are dropped instead of waiting for them to be
+		// processed
+		So(func() interface{} {
+			select {
+			case <-stopped:
+				return true
+			default:
+				return false
+			}
+		}, withTimeout, 2*time.Second, ShouldBeTrue)
+		So(updaterPool.running(), ShouldBeFalse)
+	})
+}
+
+func TestNodeUpdateCoalescing(t *testing.T) {
+	fakeMaster := newFakeMaster()
+	fakeMaster.config().NodeUpdates.CoalescePeriod.Duration = 100 * time.Millisecond
+	updaterPool := newFakeupdaterPool(fakeMaster)
+	updaterPool.queue = workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[string]())
+	defer updaterPool.queue.ShutDown()
+
+	Convey("When the same node is queued repeatedly within the coalesce period", t, func() {
+		for range 3 {
+			updaterPool.addNode(testNodeName)
+		}
+		// The node is queued once after the coalesce period
+		So(updaterPool.queue.Len(), ShouldEqual, 0)
+		So(func() interface{} { return updaterPool.queue.Len() },
+			withTimeout, 2*time.Second, ShouldEqual, 1)
+		time.Sleep(200 * time.Millisecond)
+		So(updaterPool.queue.Len(), ShouldEqual, 1)
+	})
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdmaster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+	"sigs.k8s.io/node-feature-discovery/pkg/apis/nfd/validate"
+	nfdfeatures "sigs.k8s.io/node-feature-discovery/pkg/features"
+)
+
+// webhookPath is the path the validating admission webhook is served on.
+const webhookPath = "/validate"
+
+// maxAdmissionReviewSize is the maximum size of an AdmissionReview request
+// body that the webhook accepts.
+const maxAdmissionReviewSize = 3 * 1024 * 1024
+
+// startWebhookServer starts the validating admission webhook server. The
+// server is stopped when the returned function is called.
+func (m *nfdMaster) startWebhookServer() func() {
+	mux := http.NewServeMux()
+	mux.HandleFunc(webhookPath, m.serveAdmissionReview)
+	server := http.Server{Addr: fmt.Sprintf(":%d", m.args.WebhookPort), Handler: mux}
+
+	go func() {
+		klog.InfoS("webhook server starting", "port", server.Addr)
+		klog.InfoS("webhook server stopped", "exitCode", server.ListenAndServeTLS(m.args.WebhookCertFile, m.args.WebhookKeyFile))
+	}()
+	return func() { server.Close() }
+}
+
+// serveAdmissionReview is the http handler of the validating admission
+// webhook.
+func (m *nfdMaster) serveAdmissionReview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxAdmissionReviewSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	review := admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(body, &review); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode AdmissionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "AdmissionReview does not contain a request", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = m.admit(review.Request)
+	review.Response.UID = review.Request.UID
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		klog.ErrorS(err, "failed to write AdmissionReview response")
+	}
+}
+
+// admit validates the object of an admission request.
+func (m *nfdMaster) admit(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+	if req.Kind.Group != nfdv1alpha1.SchemeGroupVersion.Group {
+		return admissionDenied(fmt.Errorf("unsupported resource %s", req.Kind))
+	}
+
+	var errs []error
+	switch req.Kind.Kind {
+	case "NodeFeatureRule":
+		obj := nfdv1alpha1.NodeFeatureRule{}
+		if err := json.Unmarshal(req.Object.Raw, &obj); err != nil {
+			return admissionDenied(fmt.Errorf("failed to decode NodeFeatureRule: %w", err))
+		}
+		errs = m.validateNodeFeatureRule(&obj)
+	case "NodeFeatureGroup":
+		obj := nfdv1alpha1.NodeFeatureGroup{}
+		if err := json.Unmarshal(req.Object.Raw, &obj); err != nil {
+			return admissionDenied(fmt.Errorf("failed to decode NodeFeatureGroup: %w", err))
+		}
+		errs = validate.NodeFeatureGroupSpec(&obj.Spec)
+		errs = append(errs, m.validateLabels(obj.Spec.Labels)...)
+	case "NodeFeature":
+		obj := nfdv1alpha1.NodeFeature{}
+		if err := json.Unmarshal(req.Object.Raw, &obj); err != nil {
+			return admissionDenied(fmt.Errorf("failed to decode NodeFeature: %w", err))
+		}
+		errs = validateNodeFeatureLabels(obj.Spec.Labels)
+	default:
+		return admissionDenied(fmt.Errorf("unsupported resource %s", req.Kind))
+	}
+
+	if len(errs) > 0 {
+		klog.V(2).InfoS("admission denied", "kind", req.Kind.Kind, "namespace", req.Namespace, "name", req.Name, "errors", errs)
+		return admissionDenied(utilerrors.NewAggregate(errs))
+	}
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+// validateNodeFeatureRule validates a NodeFeatureRule. Label names are
+// checked against the allowed and denied label namespaces of nfd-master.
+func (m *nfdMaster) validateNodeFeatureRule(nfr *nfdv1alpha1.NodeFeatureRule) []error {
+	spec := nfr.Spec.DeepCopy()
+
+	var errs []error
+	names := make([]string, 0, len(spec.Rules))
+	for i := range spec.Rules {
+		rule := &spec.Rules[i]
+		names = append(names, rule.Name)
+		if !nfdfeatures.NFDFeatureGate.Enabled(nfdfeatures.DisableAutoPrefix) {
+			rule.Annotations = addNsToMapKeys(rule.Annotations, nfdv1alpha1.FeatureAnnotationNs)
+			rule.ExtendedResources = addNsToMapKeys(rule.ExtendedResources, nfdv1alpha1.ExtendedResourceNs)
+		}
+		for _, err := range m.validateLabels(rule.Labels) {
+			errs = append(errs, fmt.Errorf("rule %q: %w", rule.Name, err))
+		}
+		for _, err := range validate.Rule(rule) {
+			errs = append(errs, fmt.Errorf("rule %q: %w", rule.Name, err))
+		}
+	}
+	errs = append(errs, validate.RuleNames(names)...)
+	return append(validate.NodeSelector(spec.NodeSelector), errs...)
+}
+
+// validateLabels validates labels against the allowed and denied label
+// namespaces of nfd-master. The labelWhiteList is not taken into account as
+// it only filters labels instead of denying them.
+func (m *nfdMaster) validateLabels(labels map[string]string) []error {
+	labels = validate.DummyDynamicValues(labels)
+	if !nfdfeatures.NFDFeatureGate.Enabled(nfdfeatures.DisableAutoPrefix) {
+		labels = addNsToMapKeys(labels, nfdv1alpha1.FeatureLabelNs)
+	}
+
+	// Sort for reproducible output
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if err := m.validateFeatureLabel(name, labels[name]); err != nil {
+			errs = append(errs, fmt.Errorf("invalid label %q: %w", name, err))
+		}
+	}
+	return errs
+}
+
+// validateNodeFeatureLabels validates the labels of a NodeFeature object.
+// Only labels that can never be applied to a node, i.e. labels with an invalid
+// name or value, are rejected. Labels in namespaces that are not allowed are
+// left to be filtered out by nfd-master: nfd-worker may produce them, e.g.
+// from feature files, and rejecting them would reject all the other features
+// and labels of the node, too.
+func validateNodeFeatureLabels(labels map[string]string) []error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(labels)) {
+		err := validate.Label(name, labels[name])
+		if err != nil && err != validate.ErrNSNotAllowed && err != validate.ErrUnprefixedKeysNotAllowed {
+			errs = append(errs, fmt.Errorf("invalid label %q: %w", name, err))
+		}
+	}
+	return errs
+}
+
+func admissionDenied(err error) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonInvalid,
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		},
+	}
+}
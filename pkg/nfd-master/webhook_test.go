@@ -0,0 +1,167 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdmaster
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+)
+
+func newAdmissionReview(t *testing.T, kind string, op admissionv1.Operation, obj interface{}) []byte {
+	raw, err := json.Marshal(obj)
+	assert.NoError(t, err)
+	review := admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       types.UID("test-uid"),
+			Kind:      metav1.GroupVersionKind{Group: nfdv1alpha1.SchemeGroupVersion.Group, Version: "v1alpha1", Kind: kind},
+			Operation: op,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+	data, err := json.Marshal(review)
+	assert.NoError(t, err)
+	return data
+}
+
+func TestWebhook(t *testing.T) {
+	m := newFakeMaster(withConfig(&NFDConfig{ExtraLabelNs: utils.StringSetVal{"extra.kubernetes.io": struct{}{}}}))
+	m.config().deniedNs.normal = map[string]struct{}{"denied.example.com": {}}
+	m.config().deniedNs.wildcard = map[string]struct{}{}
+
+	server := httptest.NewServer(http.HandlerFunc(m.serveAdmissionReview))
+	defer server.Close()
+
+	review := func(kind string, op admissionv1.Operation, obj interface{}) *admissionv1.AdmissionResponse {
+		resp, err := http.Post(server.URL, "application/json", bytes.NewReader(newAdmissionReview(t, kind, op, obj)))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		out := admissionv1.AdmissionReview{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		assert.Equal(t, types.UID("test-uid"), out.Response.UID)
+		return out.Response
+	}
+
+	validRule := nfdv1alpha1.Rule{
+		Name: "rule-1",
+		Labels: map[string]string{
+			"unprefixed":                   "true",
+			"example.com/dynamic":          "@kernel.version.major",
+			"extra.kubernetes.io/label":    "true",
+			"feature.node.kubernetes.io/x": "true",
+		},
+		LabelsTemplate: "{{range .kernel.loadedmodule}}mod-{{.Name}}=true\n{{end}}",
+		MatchFeatures: nfdv1alpha1.FeatureMatcher{
+			{
+				Feature:          "kernel.loadedmodule",
+				MatchExpressions: &nfdv1alpha1.MatchExpressionSet{"dummy": &nfdv1alpha1.MatchExpression{Op: nfdv1alpha1.MatchExists}},
+			},
+		},
+	}
+
+	// NodeFeatureRule
+	nfr := &nfdv1alpha1.NodeFeatureRule{
+		ObjectMeta: metav1.ObjectMeta{Name: "nfr-1"},
+		Spec:       nfdv1alpha1.NodeFeatureRuleSpec{Rules: []nfdv1alpha1.Rule{validRule}},
+	}
+	resp := review("NodeFeatureRule", admissionv1.Create, nfr)
+	assert.True(t, resp.Allowed, "unexpected denial: %v", resp.Result)
+
+	invalidRule := validRule
+	invalidRule.Labels = map[string]string{
+		"denied.example.com/label": "true",
+		"kubernetes.io/label":      "true",
+	}
+	invalidRule.LabelsTemplate = "{{range .kernel"
+	invalidRule.MatchFeatures = nfdv1alpha1.FeatureMatcher{
+		{
+			Feature:          "kernel.loadedmodule",
+			MatchExpressions: &nfdv1alpha1.MatchExpressionSet{"dummy": &nfdv1alpha1.MatchExpression{Op: nfdv1alpha1.MatchGt, Value: nfdv1alpha1.MatchValue{"a"}}},
+		},
+	}
+	nfr.Spec.Rules = append(nfr.Spec.Rules, invalidRule)
+	resp = review("NodeFeatureRule", admissionv1.Update, nfr)
+	assert.False(t, resp.Allowed)
+	assert.Equal(t, int32(http.StatusUnprocessableEntity), resp.Result.Code)
+	assert.Contains(t, resp.Result.Message, `namespace "denied.example.com" is not allowed`)
+	assert.Contains(t, resp.Result.Message, `namespace "kubernetes.io" is not allowed`)
+	assert.Contains(t, resp.Result.Message, "invalid template")
+	assert.Contains(t, resp.Result.Message, `not a number "a"`)
+	assert.Contains(t, resp.Result.Message, `duplicate rule name "rule-1"`)
+
+	// Deletion is always allowed
+	resp = review("NodeFeatureRule", admissionv1.Delete, nfr)
+	assert.True(t, resp.Allowed)
+
+	// NodeFeatureGroup
+	nfg := &nfdv1alpha1.NodeFeatureGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nfg-1", Namespace: "default"},
+		Spec: nfdv1alpha1.NodeFeatureGroupSpec{
+			Rules: []nfdv1alpha1.GroupRule{{Name: "rule-1", MatchFeatures: validRule.MatchFeatures}},
+		},
+	}
+	resp = review("NodeFeatureGroup", admissionv1.Create, nfg)
+	assert.True(t, resp.Allowed, "unexpected denial: %v", resp.Result)
+
+	nfg.Spec.Rules = append(nfg.Spec.Rules, nfdv1alpha1.GroupRule{CELExpression: "flags["})
+	resp = review("NodeFeatureGroup", admissionv1.Create, nfg)
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "rule name cannot be empty")
+	assert.Contains(t, resp.Result.Message, "invalid CEL expression")
+
+	// NodeFeature
+	nf := &nfdv1alpha1.NodeFeature{
+		ObjectMeta: metav1.ObjectMeta{Name: "nf-1", Namespace: "default"},
+		Spec:       nfdv1alpha1.NodeFeatureSpec{Labels: map[string]string{"example.com/label": "true"}},
+	}
+	resp = review("NodeFeature", admissionv1.Create, nf)
+	assert.True(t, resp.Allowed, "unexpected denial: %v", resp.Result)
+
+	// Labels in namespaces that are not allowed are filtered by nfd-master
+	nf.Spec.Labels["kubernetes.io/label"] = "true"
+	resp = review("NodeFeature", admissionv1.Create, nf)
+	assert.True(t, resp.Allowed, "unexpected denial: %v", resp.Result)
+
+	nf.Spec.Labels["example.com/invalid"] = "invalid value"
+	resp = review("NodeFeature", admissionv1.Create, nf)
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, `invalid label "example.com/invalid"`)
+
+	// Unsupported kind
+	resp = review("Foo", admissionv1.Create, nf)
+	assert.False(t, resp.Allowed)
+
+	// Malformed request
+	httpResp, err := http.Post(server.URL, "application/json", bytes.NewReader([]byte("{")))
+	assert.NoError(t, err)
+	httpResp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, httpResp.StatusCode)
+}
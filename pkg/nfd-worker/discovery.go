@@ -0,0 +1,310 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdworker
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"time"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+	"sigs.k8s.io/node-feature-discovery/pkg/version"
+	"sigs.k8s.io/node-feature-discovery/source"
+)
+
+// nodeFeatureStatusRefreshPeriod is the maximum age of the discovery
+// timestamps in the status of the NodeFeature object. Changes in the
+// timestamps and durations alone do not cause the object to be updated
+// more often.
+const nodeFeatureStatusRefreshPeriod = 10 * time.Minute
+
+// sourceDiscoveryState is the state of the discovery of one feature source.
+type sourceDiscoveryState struct {
+	// running is true while Discover() of the source has not returned
+	running bool
+	// stale is true if the last discovery did not complete in time and the
+	// last good features are used in place of the features of the source
+	stale bool
+	// lastGood is a copy of the features from the last successful discovery
+	lastGood *nfdv1alpha1.Features
+	// lastGoodLabels are the labels created from lastGood
+	lastGoodLabels Labels
+	// pendingConfig is the configuration to apply to the source once its
+	// running discovery returns
+	pendingConfig source.Config
+	// lastSuccess is the time when the last successful discovery completed
+	lastSuccess time.Time
+	// duration of the most recent discovery, or the timeout if the
+	// discovery did not complete in time
+	duration time.Duration
+	// err is the error of the most recent discovery
+	err error
+}
+
+// discoverFeatures runs discovery of the given feature sources concurrently.
+// Sources whose discovery does not complete within core.sourceTimeout or
+// core.discoveryTimeout keep their last good features, which are marked
+// stale. Discovery of a source is not re-started until its previous
+// discovery has returned.
+func (w *nfdWorker) discoverFeatures(featureSources []source.FeatureSource) {
+	done := make(chan string, len(featureSources))
+	pending := sets.New[string]()
+
+	w.discoveryMu.Lock()
+	for _, s := range featureSources {
+		name := s.Name()
+		state, ok := w.sourceStates[name]
+		if !ok {
+			state = &sourceDiscoveryState{}
+			w.sourceStates[name] = state
+		}
+		if state.running {
+			klog.InfoS("previous feature discovery has not completed, skipping source", "featureSource", name)
+			continue
+		}
+		state.running = true
+		pending.Insert(name)
+		go w.discoverSource(s, done)
+	}
+	w.discoveryMu.Unlock()
+
+	var sourceDeadline, discoveryDeadline <-chan time.Time
+	if d := w.config.Core.SourceTimeout.Duration; d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		sourceDeadline = t.C
+	}
+	if d := w.config.Core.DiscoveryTimeout.Duration; d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		discoveryDeadline = t.C
+	}
+
+	for pending.Len() > 0 {
+		select {
+		case name := <-done:
+			pending.Delete(name)
+		case <-sourceDeadline:
+			w.markStale(pending, "sourceTimeout", w.config.Core.SourceTimeout.Duration)
+			return
+		case <-discoveryDeadline:
+			w.markStale(pending, "discoveryTimeout", w.config.Core.DiscoveryTimeout.Duration)
+			return
+		}
+	}
+}
+
+// discoverSource runs discovery of one feature source, sending its name to
+// done when completed. The discovered features are stored as an immutable
+// snapshot used by GetAllFeatures, so that the source itself is not accessed
+// while its discovery is running.
+func (w *nfdWorker) discoverSource(s source.FeatureSource, done chan<- string) {
+	name := s.Name()
+	start := time.Now()
+	err := s.Discover()
+	duration := time.Since(start)
+	sourceDiscoveryDuration.WithLabelValues(name).Observe(duration.Seconds())
+	features := s.GetFeatures().DeepCopy()
+
+	w.discoveryMu.Lock()
+	state := w.sourceStates[name]
+	state.running = false
+	state.duration = duration
+	state.err = err
+	if state.pendingConfig != nil {
+		if cs, ok := s.(source.ConfigurableSource); ok {
+			cs.SetConfig(state.pendingConfig)
+		}
+		state.pendingConfig = nil
+	}
+	if err != nil {
+		klog.ErrorS(err, "feature discovery failed", "source", name)
+		sourceDiscoveryFailures.WithLabelValues(name).Inc()
+		// Stale sources keep their last good features
+		if !state.stale {
+			source.SetFeatureSnapshot(name, features)
+		}
+	} else {
+		state.lastGood = features
+		state.lastGoodLabels = nil
+		state.lastSuccess = time.Now()
+		source.SetFeatureSnapshot(name, features)
+		if state.stale {
+			klog.InfoS("delayed feature discovery completed, features are up-to-date", "featureSource", name, "duration", duration)
+			state.stale = false
+			featureSourceStale.WithLabelValues(name).Set(0)
+		}
+	}
+	w.discoveryMu.Unlock()
+
+	klog.V(3).InfoS("feature discovery completed", "featureSource", name, "duration", duration)
+	done <- name
+}
+
+// markStale marks the sources whose discovery is still running as stale,
+// using their last good features in place of the current ones.
+func (w *nfdWorker) markStale(names sets.Set[string], reason string, timeout time.Duration) {
+	w.discoveryMu.Lock()
+	defer w.discoveryMu.Unlock()
+
+	for name := range names {
+		state := w.sourceStates[name]
+		if !state.running {
+			// Completed just now
+			continue
+		}
+		klog.InfoS("feature discovery timed out, using last good features", "featureSource", name, "reason", reason, "timeout", timeout)
+		sourceDiscoveryFailures.WithLabelValues(name).Inc()
+
+		state.stale = true
+		state.duration = timeout
+		state.err = fmt.Errorf("discovery did not complete within %s (%s)", timeout, reason)
+		features := state.lastGood
+		if features == nil {
+			features = nfdv1alpha1.NewFeatures()
+		}
+		source.SetFeatureSnapshot(name, features)
+		featureSourceStale.WithLabelValues(name).Set(1)
+	}
+}
+
+// getSourceLabels returns the labels of a label source. The labels of a
+// source whose discovery is still running are not created from the source
+// but the labels created from its last good features are used.
+func (w *nfdWorker) getSourceLabels(s source.LabelSource, labelWhiteList regexp.Regexp) (Labels, error) {
+	w.discoveryMu.Lock()
+	state, ok := w.sourceStates[s.Name()]
+	if ok && state.running {
+		labels := maps.Clone(state.lastGoodLabels)
+		w.discoveryMu.Unlock()
+		if labels == nil {
+			labels = Labels{}
+		}
+		return labels, nil
+	}
+	w.discoveryMu.Unlock()
+
+	labels, err := getFeatureLabels(s, labelWhiteList)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		w.discoveryMu.Lock()
+		if state.err == nil {
+			state.lastGoodLabels = labels
+		}
+		w.discoveryMu.Unlock()
+	}
+	return labels, nil
+}
+
+// setSourceConfig changes the configuration of a source. Applying the
+// configuration is deferred until the discovery of the source returns if
+// it is still running.
+func (w *nfdWorker) setSourceConfig(s source.ConfigurableSource, c source.Config) {
+	w.discoveryMu.Lock()
+	defer w.discoveryMu.Unlock()
+
+	if state, ok := w.sourceStates[s.Name()]; ok && state.running {
+		klog.InfoS("feature discovery is running, deferring configuration update of the source", "featureSource", s.Name())
+		state.pendingConfig = c
+		return
+	}
+	s.SetConfig(c)
+}
+
+// featureSourceStatus returns the discovery status of the enabled feature
+// sources for the NodeFeature object, including the names of the labels
+// created from each source. Sources that have not been discovered yet are
+// omitted.
+func (w *nfdWorker) featureSourceStatus(sourceLabels map[string]Labels) nfdv1alpha1.NodeFeatureStatus {
+	w.discoveryMu.Lock()
+	defer w.discoveryMu.Unlock()
+
+	status := nfdv1alpha1.NodeFeatureStatus{}
+	for _, s := range w.featureSources {
+		state, ok := w.sourceStates[s.Name()]
+		if !ok || (state.err == nil && state.lastSuccess.IsZero()) {
+			continue
+		}
+		ss := nfdv1alpha1.FeatureSourceStatus{
+			Name:     s.Name(),
+			Duration: &metav1.Duration{Duration: state.duration.Round(time.Millisecond)},
+			Stale:    state.stale,
+			Version:  version.Get(),
+		}
+		if !state.lastSuccess.IsZero() {
+			t := metav1.NewTime(state.lastSuccess)
+			ss.LastSuccessfulTime = &t
+		}
+		if state.err != nil {
+			ss.Error = state.err.Error()
+		}
+		if labels := sourceLabels[s.Name()]; len(labels) > 0 {
+			ss.Labels = slices.Sorted(maps.Keys(labels))
+		}
+		status.Sources = append(status.Sources, ss)
+	}
+	return status
+}
+
+// nodeFeatureNeedsUpdate returns true if the NodeFeature object needs to be
+// updated. Changes in the discovery timestamps and durations of the status
+// alone are ignored until the timestamps get older than
+// nodeFeatureStatusRefreshPeriod, in order not to update the object on every
+// discovery pass.
+func nodeFeatureNeedsUpdate(old, updated *nfdv1alpha1.NodeFeature) bool {
+	if apiequality.Semantic.DeepEqual(old, updated) {
+		return false
+	}
+
+	o := old.DeepCopy()
+	u := updated.DeepCopy()
+	clearStatusTimestamps(&o.Status)
+	clearStatusTimestamps(&u.Status)
+	if !apiequality.Semantic.DeepEqual(o, u) {
+		return true
+	}
+
+	for _, s := range old.Status.Sources {
+		if s.LastSuccessfulTime != nil && time.Since(s.LastSuccessfulTime.Time) > nodeFeatureStatusRefreshPeriod {
+			return true
+		}
+	}
+	return false
+}
+
+// clearStatusTimestamps zeroes the timestamps and durations of a
+// NodeFeature status, preserving the information whether they are set.
+func clearStatusTimestamps(status *nfdv1alpha1.NodeFeatureStatus) {
+	for i := range status.Sources {
+		s := &status.Sources[i]
+		if s.LastSuccessfulTime != nil {
+			s.LastSuccessfulTime = &metav1.Time{}
+		}
+		if s.Duration != nil {
+			s.Duration = &metav1.Duration{}
+		}
+	}
+}
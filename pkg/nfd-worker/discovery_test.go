@@ -0,0 +1,243 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdworker
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+	"sigs.k8s.io/node-feature-discovery/source"
+)
+
+// blockingSource is a feature source whose discovery blocks until released
+type blockingSource struct {
+	name     string
+	value    string
+	release  chan struct{}
+	err      error
+	features *nfdv1alpha1.Features
+}
+
+func (s *blockingSource) Name() string { return s.name }
+
+func (s *blockingSource) Discover() error {
+	s.features = nfdv1alpha1.NewFeatures()
+	if s.release != nil {
+		<-s.release
+	}
+	s.features.Flags["flag"] = nfdv1alpha1.NewFlagFeatures(s.value)
+	return s.err
+}
+
+func (s *blockingSource) GetFeatures() *nfdv1alpha1.Features { return s.features }
+
+// blockingLabelSource is a blocking feature source that also creates labels
+// and is configurable
+type blockingLabelSource struct {
+	blockingSource
+	config string
+}
+
+func (s *blockingLabelSource) GetLabels() (source.FeatureLabels, error) {
+	labels := source.FeatureLabels{}
+	for f := range s.features.Flags["flag"].Elements {
+		labels[s.config+"-"+f] = true
+	}
+	return labels, nil
+}
+
+func (s *blockingLabelSource) Priority() int { return 0 }
+
+func (s *blockingLabelSource) NewConfig() source.Config { return "" }
+
+func (s *blockingLabelSource) GetConfig() source.Config { return s.config }
+
+func (s *blockingLabelSource) SetConfig(c source.Config) { s.config = c.(string) }
+
+func (w *nfdWorker) sourceState(name string) sourceDiscoveryState {
+	w.discoveryMu.Lock()
+	defer w.discoveryMu.Unlock()
+	return *w.sourceStates[name]
+}
+
+func TestDiscoverFeatures(t *testing.T) {
+	Convey("When discovering features concurrently", t, func() {
+		w := &nfdWorker{config: newDefaultConfig(), sourceStates: make(map[string]*sourceDiscoveryState)}
+		w.config.Core.SourceTimeout = utils.DurationVal{Duration: 50 * time.Millisecond}
+		fast := &blockingSource{name: "test-fast", value: "a"}
+		slow := &blockingSource{name: "test-slow", value: "a"}
+		sources := []source.FeatureSource{fast, slow}
+
+		w.discoverFeatures(sources)
+		So(w.sourceState("test-slow").stale, ShouldBeFalse)
+		So(w.sourceState("test-slow").lastGood.Flags["flag"].Elements, ShouldContainKey, "a")
+
+		// Hung source times out and keeps its last good features
+		slow.release = make(chan struct{})
+		slow.value = "b"
+		start := time.Now()
+		w.discoverFeatures(sources)
+		So(time.Since(start), ShouldBeLessThan, time.Second)
+		state := w.sourceState("test-slow")
+		So(state.running, ShouldBeTrue)
+		So(state.stale, ShouldBeTrue)
+		So(state.lastGood.Flags["flag"].Elements, ShouldContainKey, "a")
+		So(w.sourceState("test-fast").stale, ShouldBeFalse)
+
+		// Source is not re-run until the previous discovery returns
+		w.discoverFeatures(sources)
+		So(w.sourceState("test-slow").running, ShouldBeTrue)
+
+		// Features are up-to-date once the discovery completes
+		close(slow.release)
+		So(func() bool {
+			for i := 0; i < 100; i++ {
+				if s := w.sourceState("test-slow"); !s.running {
+					return s.stale
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			return true
+		}(), ShouldBeFalse)
+		So(w.sourceState("test-slow").lastGood.Flags["flag"].Elements, ShouldContainKey, "b")
+
+		// Failed discovery does not update the last good features
+		slow.release = nil
+		slow.value = "c"
+		slow.err = errors.New("failed")
+		w.discoverFeatures(sources)
+		So(w.sourceState("test-slow").lastGood.Flags["flag"].Elements, ShouldContainKey, "b")
+	})
+
+	Convey("When a source is accessed during its discovery", t, func() {
+		w := &nfdWorker{config: newDefaultConfig(), sourceStates: make(map[string]*sourceDiscoveryState)}
+		w.config.Core.SourceTimeout = utils.DurationVal{Duration: 50 * time.Millisecond}
+		slow := &blockingLabelSource{blockingSource: blockingSource{name: "test-labels", value: "a"}, config: "x"}
+		sources := []source.FeatureSource{slow}
+		labelWhiteList := *regexp.MustCompile("")
+
+		w.discoverFeatures(sources)
+		labels, err := w.getSourceLabels(slow, labelWhiteList)
+		So(err, ShouldBeNil)
+		So(labels, ShouldResemble, Labels{"feature.node.kubernetes.io/test-labels-x-a": "true"})
+
+		// Features and labels of the hung source come from the last good
+		// discovery, and configuration changes are deferred
+		slow.release = make(chan struct{})
+		slow.value = "b"
+		w.discoverFeatures(sources)
+		labels, err = w.getSourceLabels(slow, labelWhiteList)
+		So(err, ShouldBeNil)
+		So(labels, ShouldResemble, Labels{"feature.node.kubernetes.io/test-labels-x-a": "true"})
+		w.setSourceConfig(slow, "y")
+		So(w.sourceState("test-labels").pendingConfig, ShouldEqual, "y")
+
+		// Configuration is applied once the discovery returns
+		close(slow.release)
+		for i := 0; i < 100 && w.sourceState("test-labels").running; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		So(w.sourceState("test-labels").running, ShouldBeFalse)
+		So(w.sourceState("test-labels").lastGood.Flags["flag"].Elements, ShouldContainKey, "b")
+		labels, err = w.getSourceLabels(slow, labelWhiteList)
+		So(err, ShouldBeNil)
+		So(labels, ShouldResemble, Labels{"feature.node.kubernetes.io/test-labels-y-b": "true"})
+		source.SetFeatureSnapshot("test-labels", nil)
+	})
+
+	Convey("When the global discovery deadline is exceeded", t, func() {
+		w := &nfdWorker{config: newDefaultConfig(), sourceStates: make(map[string]*sourceDiscoveryState)}
+		w.config.Core.SourceTimeout = utils.DurationVal{}
+		w.config.Core.DiscoveryTimeout = utils.DurationVal{Duration: 50 * time.Millisecond}
+		slow := &blockingSource{name: "test-slow", release: make(chan struct{})}
+		defer close(slow.release)
+
+		w.discoverFeatures([]source.FeatureSource{slow})
+		So(w.sourceState("test-slow").stale, ShouldBeTrue)
+	})
+}
+
+func TestFeatureSourceStatus(t *testing.T) {
+	Convey("When reporting the discovery status of feature sources", t, func() {
+		w := &nfdWorker{config: newDefaultConfig(), sourceStates: make(map[string]*sourceDiscoveryState)}
+		w.config.Core.SourceTimeout = utils.DurationVal{Duration: 50 * time.Millisecond}
+		ok := &blockingSource{name: "test-ok"}
+		failed := &blockingSource{name: "test-failed", err: errors.New("device not responding")}
+		slow := &blockingSource{name: "test-slow", release: make(chan struct{})}
+		defer close(slow.release)
+		w.featureSources = []source.FeatureSource{ok, failed, slow, &blockingSource{name: "test-not-run"}}
+
+		w.discoverFeatures(w.featureSources[:3])
+		status := w.featureSourceStatus(map[string]Labels{"test-ok": {"example.com/b": "true", "example.com/a": "true"}})
+		So(status.Sources, ShouldHaveLength, 3)
+
+		So(status.Sources[0].Name, ShouldEqual, "test-ok")
+		So(status.Sources[0].Labels, ShouldResemble, []string{"example.com/a", "example.com/b"})
+		So(status.Sources[0].LastSuccessfulTime, ShouldNotBeNil)
+		So(status.Sources[0].Duration, ShouldNotBeNil)
+		So(status.Sources[0].Error, ShouldBeEmpty)
+
+		So(status.Sources[1].Name, ShouldEqual, "test-failed")
+		So(status.Sources[1].LastSuccessfulTime, ShouldBeNil)
+		So(status.Sources[1].Error, ShouldEqual, "device not responding")
+		So(status.Sources[1].Stale, ShouldBeFalse)
+
+		So(status.Sources[2].Name, ShouldEqual, "test-slow")
+		So(status.Sources[2].Error, ShouldContainSubstring, "did not complete within 50ms")
+		So(status.Sources[2].Stale, ShouldBeTrue)
+		So(status.Sources[2].Duration.Duration, ShouldEqual, 50*time.Millisecond)
+	})
+}
+
+func TestNodeFeatureNeedsUpdate(t *testing.T) {
+	Convey("When comparing NodeFeature objects", t, func() {
+		newNodeFeature := func(lastSuccess time.Time, duration time.Duration, errMsg string) *nfdv1alpha1.NodeFeature {
+			t := metav1.NewTime(lastSuccess)
+			return &nfdv1alpha1.NodeFeature{
+				Spec: nfdv1alpha1.NodeFeatureSpec{Labels: map[string]string{"foo": "bar"}},
+				Status: nfdv1alpha1.NodeFeatureStatus{Sources: []nfdv1alpha1.FeatureSourceStatus{{
+					Name:               "cpu",
+					LastSuccessfulTime: &t,
+					Duration:           &metav1.Duration{Duration: duration},
+					Error:              errMsg,
+				}}},
+			}
+		}
+		now := time.Now()
+		old := newNodeFeature(now.Add(-time.Minute), time.Second, "")
+
+		So(nodeFeatureNeedsUpdate(old, old.DeepCopy()), ShouldBeFalse)
+		// Only the discovery timestamps changed
+		So(nodeFeatureNeedsUpdate(old, newNodeFeature(now, 2*time.Second, "")), ShouldBeFalse)
+		// Discovery status changed
+		So(nodeFeatureNeedsUpdate(old, newNodeFeature(now, time.Second, "failed")), ShouldBeTrue)
+		// Spec changed
+		updated := newNodeFeature(now, time.Second, "")
+		updated.Spec.Labels["foo"] = "baz"
+		So(nodeFeatureNeedsUpdate(old, updated), ShouldBeTrue)
+		// Timestamps older than the refresh period
+		old = newNodeFeature(now.Add(-nodeFeatureStatusRefreshPeriod-time.Minute), time.Second, "")
+		So(nodeFeatureNeedsUpdate(old, newNodeFeature(now, time.Second, "")), ShouldBeTrue)
+	})
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdworker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+)
+
+// discoveredFeatures is the output of the most recent feature discovery,
+// served by the features API.
+type discoveredFeatures struct {
+	features *nfdv1alpha1.Features
+	labels   Labels
+	// sourceLabels contains the labels of each label source separately
+	sourceLabels map[string]Labels
+}
+
+// setDiscoveredFeatures stores the output of a feature discovery for the
+// features API.
+func (w *nfdWorker) setDiscoveredFeatures(features *nfdv1alpha1.Features, labels Labels, sourceLabels map[string]Labels) {
+	w.discoveredMu.Lock()
+	defer w.discoveredMu.Unlock()
+
+	w.discovered = &discoveredFeatures{features: features, labels: labels, sourceLabels: sourceLabels}
+}
+
+func (w *nfdWorker) getDiscoveredFeatures() *discoveredFeatures {
+	w.discoveredMu.RLock()
+	defer w.discoveredMu.RUnlock()
+
+	return w.discovered
+}
+
+// featuresAPISocketMode is the file mode of the features API socket. Access
+// can be granted to consumers by the group of the socket.
+const featuresAPISocketMode os.FileMode = 0660
+
+// startFeaturesAPISocket serves the features API on a unix socket. The API is
+// only served on the socket so that it is not exposed outside the node.
+func (w *nfdWorker) startFeaturesAPISocket(socketPath string) (*http.Server, error) {
+	// Remove a stale socket left behind by a previous instance, but never
+	// anything else
+	if fi, err := os.Lstat(socketPath); err == nil {
+		if fi.Mode().Type() != os.ModeSocket {
+			return nil, fmt.Errorf("refusing to replace %q: not a socket", socketPath)
+		}
+		if err := os.Remove(socketPath); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket %q: %w", socketPath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to stat socket %q: %w", socketPath, err)
+	}
+
+	l, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on socket %q: %w", socketPath, err)
+	}
+	if err := os.Chmod(socketPath, featuresAPISocketMode); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to set permissions of socket %q: %w", socketPath, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /features", w.featuresHandler)
+	mux.HandleFunc("GET /labels", w.labelsHandler)
+	server := &http.Server{Handler: mux}
+	go func() {
+		klog.InfoS("features API server starting", "socket", socketPath)
+		klog.InfoS("features API server stopped", "exitCode", server.Serve(l))
+	}()
+	return server, nil
+}
+
+// featuresHandler serves the discovered features. The features may be
+// filtered with the "source" (name of the feature source) and "feature"
+// (glob pattern matched against the feature name, e.g. "cpu.cpuid" or
+// "pci.*") query parameters.
+func (w *nfdWorker) featuresHandler(writer http.ResponseWriter, r *http.Request) {
+	d := w.getDiscoveredFeatures()
+	if d == nil {
+		http.Error(writer, "feature discovery has not completed yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	sources := queryValues(r, "source")
+	patterns := queryValues(r, "feature")
+	if err := validatePatterns(patterns); err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	match := func(name string) bool {
+		return matchSource(name, sources) && matchPatterns(name, patterns)
+	}
+	features := nfdv1alpha1.NewFeatures()
+	for k, v := range d.features.Flags {
+		if match(k) {
+			features.Flags[k] = v
+		}
+	}
+	for k, v := range d.features.Attributes {
+		if match(k) {
+			features.Attributes[k] = v
+		}
+	}
+	for k, v := range d.features.Instances {
+		if match(k) {
+			features.Instances[k] = v
+		}
+	}
+
+	writeFeaturesAPIResponse(writer, r, features)
+}
+
+// labelsHandler serves the feature labels. The labels may be filtered with
+// the "source" (name of the label source) and "label" (glob pattern matched
+// against the label name) query parameters.
+func (w *nfdWorker) labelsHandler(writer http.ResponseWriter, r *http.Request) {
+	d := w.getDiscoveredFeatures()
+	if d == nil {
+		http.Error(writer, "feature discovery has not completed yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	sources := queryValues(r, "source")
+	patterns := queryValues(r, "label")
+	if err := validatePatterns(patterns); err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	labels := Labels{}
+	for k, v := range d.labels {
+		if !matchPatterns(k, patterns) {
+			continue
+		}
+		if len(sources) > 0 && !labelFromSources(k, sources, d.sourceLabels) {
+			continue
+		}
+		labels[k] = v
+	}
+
+	writeFeaturesAPIResponse(writer, r, labels)
+}
+
+// writeFeaturesAPIResponse writes the response in JSON, or, in YAML if
+// requested with the "format" query parameter or the Accept header.
+func writeFeaturesAPIResponse(writer http.ResponseWriter, r *http.Request, obj any) {
+	format := r.URL.Query().Get("format")
+	if format == "" && strings.Contains(r.Header.Get("Accept"), "yaml") {
+		format = "yaml"
+	}
+
+	var data []byte
+	var err error
+	switch format {
+	case "", "json":
+		writer.Header().Set("Content-Type", "application/json")
+		data, err = json.Marshal(obj)
+	case "yaml":
+		writer.Header().Set("Content-Type", "application/yaml")
+		data, err = yaml.Marshal(obj)
+	default:
+		http.Error(writer, fmt.Sprintf("unsupported format %q", format), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		klog.ErrorS(err, "failed to encode features API response")
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := writer.Write(data); err != nil {
+		klog.ErrorS(err, "failed to write features API response")
+	}
+}
+
+// queryValues returns the values of a query parameter, which may be
+// specified multiple times or as a comma-separated list.
+func queryValues(r *http.Request, key string) []string {
+	var values []string
+	for _, v := range r.URL.Query()[key] {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
+
+func validatePatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// matchSource returns true if the feature belongs to one of the sources, or
+// if no sources are specified.
+func matchSource(featureName string, sources []string) bool {
+	if len(sources) == 0 {
+		return true
+	}
+	for _, s := range sources {
+		if strings.HasPrefix(featureName, s+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPatterns returns true if the name matches one of the patterns, or if
+// no patterns are specified.
+func matchPatterns(name string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// labelFromSources returns true if the label was created by one of the
+// sources.
+func labelFromSources(name string, sources []string, sourceLabels map[string]Labels) bool {
+	for _, s := range sources {
+		if _, ok := sourceLabels[s][name]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdworker
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"sigs.k8s.io/yaml"
+
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
+)
+
+func newFeaturesAPITestWorker() *nfdWorker {
+	w := &nfdWorker{}
+	features := nfdv1alpha1.NewFeatures()
+	features.Flags["cpu.cpuid"] = nfdv1alpha1.NewFlagFeatures("AVX", "AVX2")
+	features.Attributes["cpu.model"] = nfdv1alpha1.NewAttributeFeatures(map[string]string{"family": "6"})
+	features.Instances["pci.device"] = nfdv1alpha1.NewInstanceFeatures(*nfdv1alpha1.NewInstanceFeature(map[string]string{"class": "0300"}))
+	w.setDiscoveredFeatures(features,
+		Labels{
+			"feature.node.kubernetes.io/cpu-cpuid.AVX":    "true",
+			"feature.node.kubernetes.io/pci-0300.present": "true",
+			"example.com/custom":                          "true",
+		},
+		map[string]Labels{
+			"cpu":    {"feature.node.kubernetes.io/cpu-cpuid.AVX": "true"},
+			"pci":    {"feature.node.kubernetes.io/pci-0300.present": "true"},
+			"custom": {"example.com/custom": "true"},
+		})
+	return w
+}
+
+func getFeaturesAPI(handler http.HandlerFunc, url string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, url, nil))
+	return rec
+}
+
+func TestFeaturesAPI(t *testing.T) {
+	Convey("When querying the features API", t, func() {
+		w := newFeaturesAPITestWorker()
+
+		// All features
+		rec := getFeaturesAPI(w.featuresHandler, "/features")
+		So(rec.Code, ShouldEqual, http.StatusOK)
+		So(rec.Header().Get("Content-Type"), ShouldEqual, "application/json")
+		features := nfdv1alpha1.Features{}
+		So(json.Unmarshal(rec.Body.Bytes(), &features), ShouldBeNil)
+		So(features.Flags, ShouldContainKey, "cpu.cpuid")
+		So(features.Attributes, ShouldContainKey, "cpu.model")
+		So(features.Instances, ShouldContainKey, "pci.device")
+
+		// Filtered by source and feature name
+		features = nfdv1alpha1.Features{}
+		So(json.Unmarshal(getFeaturesAPI(w.featuresHandler, "/features?source=cpu").Body.Bytes(), &features), ShouldBeNil)
+		So(features.Flags, ShouldContainKey, "cpu.cpuid")
+		So(features.Attributes, ShouldContainKey, "cpu.model")
+		So(features.Instances, ShouldBeEmpty)
+		features = nfdv1alpha1.Features{}
+		So(json.Unmarshal(getFeaturesAPI(w.featuresHandler, "/features?feature=cpu.cpuid,pci.*").Body.Bytes(), &features), ShouldBeNil)
+		So(features.Flags, ShouldContainKey, "cpu.cpuid")
+		So(features.Attributes, ShouldBeEmpty)
+		So(features.Instances, ShouldContainKey, "pci.device")
+
+		// YAML output
+		rec = getFeaturesAPI(w.featuresHandler, "/features?source=pci&format=yaml")
+		So(rec.Code, ShouldEqual, http.StatusOK)
+		So(rec.Header().Get("Content-Type"), ShouldEqual, "application/yaml")
+		features = nfdv1alpha1.Features{}
+		So(yaml.Unmarshal(rec.Body.Bytes(), &features), ShouldBeNil)
+		So(features.Instances["pci.device"].Elements[0].Attributes["class"], ShouldEqual, "0300")
+
+		// Invalid requests
+		So(getFeaturesAPI(w.featuresHandler, "/features?feature=[").Code, ShouldEqual, http.StatusBadRequest)
+		So(getFeaturesAPI(w.featuresHandler, "/features?format=xml").Code, ShouldEqual, http.StatusBadRequest)
+		So(getFeaturesAPI((&nfdWorker{}).featuresHandler, "/features").Code, ShouldEqual, http.StatusServiceUnavailable)
+	})
+
+	Convey("When querying the labels API", t, func() {
+		w := newFeaturesAPITestWorker()
+
+		labels := Labels{}
+		So(json.Unmarshal(getFeaturesAPI(w.labelsHandler, "/labels").Body.Bytes(), &labels), ShouldBeNil)
+		So(labels, ShouldHaveLength, 3)
+
+		labels = Labels{}
+		So(json.Unmarshal(getFeaturesAPI(w.labelsHandler, "/labels?source=cpu&source=custom").Body.Bytes(), &labels), ShouldBeNil)
+		So(labels, ShouldResemble, Labels{"feature.node.kubernetes.io/cpu-cpuid.AVX": "true", "example.com/custom": "true"})
+
+		labels = Labels{}
+		So(json.Unmarshal(getFeaturesAPI(w.labelsHandler, "/labels?label=feature.node.kubernetes.io/*").Body.Bytes(), &labels), ShouldBeNil)
+		So(labels, ShouldHaveLength, 2)
+		So(labels, ShouldNotContainKey, "example.com/custom")
+	})
+
+	Convey("When serving the features API on a unix socket", t, func() {
+		w := newFeaturesAPITestWorker()
+		socketPath := filepath.Join(t.TempDir(), "nfd-worker.sock")
+		server, err := w.startFeaturesAPISocket(socketPath)
+		So(err, ShouldBeNil)
+		defer server.Close()
+
+		client := http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+			},
+		}}
+		resp, err := client.Get("http://localhost/labels?source=pci")
+		So(err, ShouldBeNil)
+		defer resp.Body.Close()
+		So(resp.StatusCode, ShouldEqual, http.StatusOK)
+		labels := Labels{}
+		So(json.NewDecoder(resp.Body).Decode(&labels), ShouldBeNil)
+		So(labels, ShouldResemble, Labels{"feature.node.kubernetes.io/pci-0300.present": "true"})
+
+		// Other methods are not allowed
+		resp, err = client.Post("http://localhost/labels", "application/json", nil)
+		So(err, ShouldBeNil)
+		resp.Body.Close()
+		So(resp.StatusCode, ShouldEqual, http.StatusMethodNotAllowed)
+
+		fi, err := os.Stat(socketPath)
+		So(err, ShouldBeNil)
+		So(fi.Mode().Perm(), ShouldEqual, featuresAPISocketMode)
+	})
+
+	Convey("When a stale socket exists", t, func() {
+		w := newFeaturesAPITestWorker()
+		socketPath := filepath.Join(t.TempDir(), "nfd-worker.sock")
+		l, err := net.Listen("unix", socketPath)
+		So(err, ShouldBeNil)
+		l.(*net.UnixListener).SetUnlinkOnClose(false)
+		So(l.Close(), ShouldBeNil)
+
+		server, err := w.startFeaturesAPISocket(socketPath)
+		So(err, ShouldBeNil)
+		So(server.Close(), ShouldBeNil)
+	})
+
+	Convey("When the socket path is not a socket", t, func() {
+		w := newFeaturesAPITestWorker()
+		socketPath := filepath.Join(t.TempDir(), "nfd-worker.sock")
+		So(os.WriteFile(socketPath, []byte("data"), 0644), ShouldBeNil)
+
+		_, err := w.startFeaturesAPISocket(socketPath)
+		So(err, ShouldNotBeNil)
+		data, err := os.ReadFile(socketPath)
+		So(err, ShouldBeNil)
+		So(string(data), ShouldEqual, "data")
+	})
+}
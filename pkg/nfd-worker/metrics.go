@@ -25,6 +25,12 @@ import (
 const (
 	buildInfoQuery                = "build_info"
 	featureDiscoveryDurationQuery = "feature_discovery_duration_seconds"
+	configReloadsQuery            = "config_reloads_total"
+	configReloadFailuresQuery     = "config_reload_failures_total"
+	featureDiscoveryTriggersQuery = "feature_discovery_triggers_total"
+	sourceDiscoveryDurationQuery  = "feature_source_discovery_duration_seconds"
+	sourceDiscoveryFailuresQuery  = "feature_source_discovery_failures_total"
+	featureSourceStaleQuery       = "feature_source_stale"
 )
 
 const (
@@ -42,6 +48,49 @@ var (
 		},
 		[]string{"node"},
 	)
+	sourceDiscoveryDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: nfdWorkerPrefix,
+			Name:      sourceDiscoveryDurationQuery,
+			Help:      "Time taken to discover features of a feature source",
+			Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 5, 30},
+		},
+		[]string{"source"},
+	)
+	sourceDiscoveryFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: nfdWorkerPrefix,
+			Name:      sourceDiscoveryFailuresQuery,
+			Help:      "Number of failed or timed out discoveries of a feature source.",
+		},
+		[]string{"source"},
+	)
+	featureSourceStale = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: nfdWorkerPrefix,
+			Name:      featureSourceStaleQuery,
+			Help:      "Whether the features of a feature source are stale (1) because of a discovery timeout or not (0).",
+		},
+		[]string{"source"},
+	)
+	featureDiscoveryTriggers = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: nfdWorkerPrefix,
+			Name:      featureDiscoveryTriggersQuery,
+			Help:      "Number of events triggering feature rediscovery.",
+		},
+		[]string{"trigger"},
+	)
+	configReloads = prometheus.NewCounter(prometheus.CounterOpts{
+		Subsystem: nfdWorkerPrefix,
+		Name:      configReloadsQuery,
+		Help:      "Number of successful reloads of the configuration.",
+	})
+	configReloadFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Subsystem: nfdWorkerPrefix,
+		Name:      configReloadFailuresQuery,
+		Help:      "Number of failed reloads of the configuration.",
+	})
 	buildInfo = prometheus.NewGauge(prometheus.GaugeOpts{
 		Subsystem: nfdWorkerPrefix,
 		Name:      buildInfoQuery,
@@ -192,6 +192,40 @@ sources:
 	})
 }
 
+func TestReloadConfig(t *testing.T) {
+	Convey("When reloading configuration", t, func() {
+		w, err := NewNfdWorker(WithArgs(&Args{}),
+			WithKubernetesClient(fakeclient.NewSimpleClientset()))
+		So(err, ShouldBeNil)
+		worker := w.(*nfdWorker)
+
+		f, err := os.CreateTemp("", "nfd-test-")
+		So(err, ShouldBeNil)
+		defer os.Remove(f.Name())
+		f.Close()
+		worker.configFilePath = f.Name()
+
+		So(os.WriteFile(f.Name(), []byte("core:\n  labelSources: [fake]\n  sleepInterval: 10s\n"), 0644), ShouldBeNil)
+		So(worker.configure(worker.configFilePath, ""), ShouldBeNil)
+		So(worker.config.Core.SleepInterval.Duration, ShouldEqual, 10*time.Second)
+
+		// Valid config is applied
+		So(os.WriteFile(f.Name(), []byte("core:\n  labelSources: [cpu]\n  sleepInterval: 20s\nsources:\n  pci:\n    deviceClassWhitelist: [\"12\"]\n"), 0644), ShouldBeNil)
+		So(worker.reloadConfig(), ShouldBeTrue)
+		So(worker.config.Core.SleepInterval.Duration, ShouldEqual, 20*time.Second)
+		So(len(worker.labelSources), ShouldEqual, 1)
+		So(worker.labelSources[0].Name(), ShouldEqual, "cpu")
+		So(source.GetConfigurableSource("pci").GetConfig().(*pci.Config).DeviceClassWhitelist, ShouldResemble, []string{"12"})
+
+		// Invalid config is rejected and the current config kept
+		So(os.WriteFile(f.Name(), []byte("core:\n  labelSources: [fake]\n  sleepInterval: foo\n"), 0644), ShouldBeNil)
+		So(worker.reloadConfig(), ShouldBeFalse)
+		So(worker.config.Core.SleepInterval.Duration, ShouldEqual, 20*time.Second)
+		So(worker.labelSources[0].Name(), ShouldEqual, "cpu")
+		So(source.GetConfigurableSource("pci").GetConfig().(*pci.Config).DeviceClassWhitelist, ShouldResemble, []string{"12"})
+	})
+}
+
 func TestNewNfdWorker(t *testing.T) {
 	Convey("When creating new NfdWorker instance", t, func() {
 
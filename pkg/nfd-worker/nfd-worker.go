@@ -21,11 +21,14 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"regexp"
 	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"maps"
@@ -35,6 +38,7 @@ import (
 	"golang.org/x/net/context"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	k8sclient "k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
@@ -42,7 +46,6 @@ import (
 	klogutils "sigs.k8s.io/node-feature-discovery/pkg/utils/klog"
 	"sigs.k8s.io/yaml"
 
-	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	nfdclient "sigs.k8s.io/node-feature-discovery/api/generated/clientset/versioned"
 	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
@@ -77,14 +80,17 @@ type NFDConfig struct {
 }
 
 type coreConfig struct {
-	Klog           klogutils.KlogConfigOpts
-	LabelWhiteList utils.RegexpVal
-	NoPublish      bool
-	NoOwnerRefs    bool
-	FeatureSources []string
-	Sources        *[]string
-	LabelSources   []string
-	SleepInterval  utils.DurationVal
+	Klog             klogutils.KlogConfigOpts
+	LabelWhiteList   utils.RegexpVal
+	NoPublish        bool
+	NoOwnerRefs      bool
+	FeatureSources   []string
+	Sources          *[]string
+	LabelSources     []string
+	SleepInterval    utils.DurationVal
+	SourceTimeout    utils.DurationVal
+	DiscoveryTimeout utils.DurationVal
+	Triggers         triggersConfig
 }
 
 type sourcesConfig map[string]source.Config
@@ -101,6 +107,11 @@ type Args struct {
 	Options     string
 	Port        int
 	NoOwnerRefs bool
+	// ReloadOnSighup enables re-loading the configuration on SIGHUP
+	ReloadOnSighup bool
+	// FeaturesAPISocket is the path of a unix socket to serve the discovered
+	// features and labels on, empty if disabled
+	FeaturesAPISocket string
 
 	Overrides ConfigOverrideArgs
 }
@@ -124,8 +135,21 @@ type nfdWorker struct {
 	featureSources      []source.FeatureSource
 	labelSources        []source.LabelSource
 	ownerReference      []metav1.OwnerReference
+	// triggers is nil if event-driven rediscovery is disabled
+	triggers       *discoveryTriggers
+	triggersConfig triggersConfig
+	discoveryMu    sync.Mutex
+	sourceStates   map[string]*sourceDiscoveryState
+	// discovered is the output of the most recent discovery, served by the
+	// features API
+	discoveredMu sync.RWMutex
+	discovered   *discoveredFeatures
 }
 
+// configReloadRatelimit is the time to wait for further changes of the
+// config file before re-loading it.
+const configReloadRatelimit = time.Second
+
 // This ticker can represent infinite and normal intervals.
 type infiniteTicker struct {
 	*time.Ticker
@@ -167,6 +191,7 @@ func NewNfdWorker(opts ...NfdWorkerOption) (NfdWorker, error) {
 		config:              &NFDConfig{},
 		kubernetesNamespace: utils.GetKubernetesNamespace(),
 		stop:                make(chan struct{}),
+		sourceStates:        make(map[string]*sourceDiscoveryState),
 	}
 
 	for _, o := range opts {
@@ -196,8 +221,13 @@ func NewNfdWorker(opts ...NfdWorkerOption) (NfdWorker, error) {
 func newDefaultConfig() *NFDConfig {
 	return &NFDConfig{
 		Core: coreConfig{
-			LabelWhiteList: utils.RegexpVal{Regexp: *regexp.MustCompile("")},
-			SleepInterval:  utils.DurationVal{Duration: 60 * time.Second},
+			LabelWhiteList:   utils.RegexpVal{Regexp: *regexp.MustCompile("")},
+			SleepInterval:    utils.DurationVal{Duration: 60 * time.Second},
+			SourceTimeout:    utils.DurationVal{Duration: 30 * time.Second},
+			DiscoveryTimeout: utils.DurationVal{Duration: 60 * time.Second},
+			Triggers: triggersConfig{
+				Debounce: utils.DurationVal{Duration: 2 * time.Second},
+			},
 			FeatureSources: []string{"all"},
 			LabelSources:   []string{"all"},
 			Klog:           make(map[string]string),
@@ -222,14 +252,27 @@ func (i *infiniteTicker) Reset(d time.Duration) {
 
 // Run feature discovery.
 func (w *nfdWorker) runFeatureDiscovery() error {
-	discoveryStart := time.Now()
+	return w.runDiscovery(w.featureSources)
+}
+
+// runTriggeredDiscovery re-runs discovery of the named feature sources only,
+// labels are re-created from the features of all sources.
+func (w *nfdWorker) runTriggeredDiscovery(sourceNames sets.Set[string]) error {
+	featureSources := make([]source.FeatureSource, 0, sourceNames.Len())
 	for _, s := range w.featureSources {
-		currentSourceStart := time.Now()
-		if err := s.Discover(); err != nil {
-			klog.ErrorS(err, "feature discovery failed", "source", s.Name())
+		if sourceNames.Has(s.Name()) {
+			featureSources = append(featureSources, s)
 		}
-		klog.V(3).InfoS("feature discovery completed", "featureSource", s.Name(), "duration", time.Since(currentSourceStart))
 	}
+	klog.InfoS("rediscovery triggered", "featureSources", sets.List(sourceNames))
+	return w.runDiscovery(featureSources)
+}
+
+// runDiscovery runs discovery of the given feature sources and updates the
+// NodeFeature object.
+func (w *nfdWorker) runDiscovery(featureSources []source.FeatureSource) error {
+	discoveryStart := time.Now()
+	w.discoverFeatures(featureSources)
 
 	discoveryDuration := time.Since(discoveryStart)
 	klog.V(2).InfoS("feature discovery of all sources completed", "duration", discoveryDuration)
@@ -238,7 +281,8 @@ func (w *nfdWorker) runFeatureDiscovery() error {
 		klog.InfoS("feature discovery sources took over half of sleep interval ", "duration", discoveryDuration, "sleepInterval", w.config.Core.SleepInterval.Duration)
 	}
 	// Get the set of feature labels.
-	labels := createFeatureLabels(w.labelSources, w.config.Core.LabelWhiteList.Regexp)
+	labels, sourceLabels := createSourceLabels(w.labelSources, w.config.Core.LabelWhiteList.Regexp, w.getSourceLabels)
+	w.setDiscoveredFeatures(source.GetAllFeatures().DeepCopy(), labels, sourceLabels)
 
 	// Update the node with the feature labels.
 	if !w.config.Core.NoPublish {
@@ -248,18 +292,18 @@ func (w *nfdWorker) runFeatureDiscovery() error {
 	return nil
 }
 
-// Set owner ref
-func (w *nfdWorker) setOwnerReference() error {
+// Get owner references for the NodeFeature object
+func (w *nfdWorker) getOwnerReference(c coreConfig) ([]metav1.OwnerReference, error) {
 	ownerReference := []metav1.OwnerReference{}
 
-	if !w.config.Core.NoOwnerRefs {
+	if !c.NoOwnerRefs {
 		// Get pod owner reference
 		podName := os.Getenv("POD_NAME")
 		// Add pod owner reference if it exists
 		if podName != "" {
 			if selfPod, err := w.k8sClient.CoreV1().Pods(w.kubernetesNamespace).Get(context.TODO(), podName, metav1.GetOptions{}); err != nil {
 				klog.ErrorS(err, "failed to get self pod, cannot inherit ownerReference for NodeFeature")
-				return err
+				return nil, err
 			} else {
 				for _, owner := range selfPod.OwnerReferences {
 					owner.BlockOwnerDeletion = ptr.To(false)
@@ -283,9 +327,7 @@ func (w *nfdWorker) setOwnerReference() error {
 		}
 	}
 
-	w.ownerReference = ownerReference
-
-	return nil
+	return ownerReference, nil
 }
 
 // Run NfdWorker client. Returns an error if a fatal error is encountered, or, after
@@ -308,7 +350,15 @@ func (w *nfdWorker) Run() error {
 
 	// Register to metrics server
 	promRegistry := prometheus.NewRegistry()
-	promRegistry.MustRegister(buildInfo, featureDiscoveryDuration)
+	promRegistry.MustRegister(
+		buildInfo,
+		featureDiscoveryDuration,
+		sourceDiscoveryDuration,
+		sourceDiscoveryFailures,
+		featureSourceStale,
+		featureDiscoveryTriggers,
+		configReloads,
+		configReloadFailures)
 	httpMux.Handle("/metrics", promhttp.HandlerFor(promRegistry, promhttp.HandlerOpts{}))
 	registerVersion(version.Get())
 
@@ -325,6 +375,15 @@ func (w *nfdWorker) Run() error {
 	// Register health endpoint (at this point we're "ready and live")
 	httpMux.HandleFunc("/healthz", w.Healthz)
 
+	// Serve the discovered features to node-local consumers
+	if w.args.FeaturesAPISocket != "" {
+		featuresAPIServer, err := w.startFeaturesAPISocket(w.args.FeaturesAPISocket)
+		if err != nil {
+			return err
+		}
+		defer featuresAPIServer.Close()
+	}
+
 	// Start HTTP server
 	httpServer := http.Server{Addr: fmt.Sprintf(":%d", w.args.Port), Handler: httpMux}
 	go func() {
@@ -333,6 +392,28 @@ func (w *nfdWorker) Run() error {
 	}()
 	defer httpServer.Close()
 
+	// Start event-driven rediscovery
+	w.configureTriggers()
+	defer w.stopTriggers()
+
+	// Watch the config file for changes
+	var configTrigger <-chan struct{}
+	if w.configFilePath != "" {
+		configWatch, err := utils.CreateFsWatcher(configReloadRatelimit, w.configFilePath)
+		if err != nil {
+			return err
+		}
+		defer configWatch.Close()
+		configTrigger = configWatch.Events
+	}
+
+	var sighup chan os.Signal
+	if w.args.ReloadOnSighup {
+		sighup = make(chan os.Signal, 1)
+		signal.Notify(sighup, syscall.SIGHUP)
+		defer signal.Stop(sighup)
+	}
+
 	for {
 		select {
 		case <-labelTrigger.C:
@@ -341,6 +422,31 @@ func (w *nfdWorker) Run() error {
 				return err
 			}
 
+		case <-configTrigger:
+			klog.InfoS("config file changed, reloading configuration", "path", w.configFilePath)
+			if w.reloadConfig() {
+				labelTrigger.Reset(w.config.Core.SleepInterval.Duration)
+				w.configureTriggers()
+				if err = w.runFeatureDiscovery(); err != nil {
+					return err
+				}
+			}
+
+		case <-sighup:
+			klog.InfoS("SIGHUP received, reloading configuration")
+			if w.reloadConfig() {
+				labelTrigger.Reset(w.config.Core.SleepInterval.Duration)
+				w.configureTriggers()
+				if err = w.runFeatureDiscovery(); err != nil {
+					return err
+				}
+			}
+
+		case sourceNames := <-w.triggerC():
+			if err = w.runTriggeredDiscovery(sourceNames); err != nil {
+				return err
+			}
+
 		case <-w.stop:
 			klog.InfoS("shutting down nfd-worker")
 			return nil
@@ -353,6 +459,57 @@ func (w *nfdWorker) Stop() {
 	close(w.stop)
 }
 
+// configureTriggers (re-)starts event-driven rediscovery if its configuration
+// has changed. Failure to start listening to events is not fatal as periodic
+// rediscovery acts as a fallback.
+func (w *nfdWorker) configureTriggers() {
+	c := w.config.Core.Triggers
+	if w.triggers != nil && c == w.triggersConfig {
+		return
+	}
+	w.stopTriggers()
+
+	if c.enabled() {
+		t, err := startDiscoveryTriggers(c)
+		if err != nil {
+			klog.ErrorS(err, "failed to start event-driven rediscovery")
+			return
+		}
+		klog.InfoS("event-driven rediscovery enabled", "uevents", c.Uevents, "featureFiles", c.FeatureFiles, "debounce", c.Debounce.Duration)
+		w.triggers = t
+		w.triggersConfig = c
+	}
+}
+
+func (w *nfdWorker) stopTriggers() {
+	if w.triggers != nil {
+		w.triggers.Stop()
+		w.triggers = nil
+	}
+}
+
+// triggerC returns the channel of triggered rediscoveries, or nil if
+// event-driven rediscovery is disabled.
+func (w *nfdWorker) triggerC() <-chan sets.Set[string] {
+	if w.triggers == nil {
+		return nil
+	}
+	return w.triggers.C
+}
+
+// reloadConfig re-reads the configuration. The current configuration is kept
+// if the new one is invalid. Returns true if the configuration was
+// successfully updated.
+func (w *nfdWorker) reloadConfig() bool {
+	if err := w.configure(w.configFilePath, w.args.Options); err != nil {
+		klog.ErrorS(err, "failed to reload configuration, keeping the current configuration")
+		configReloadFailures.Inc()
+		return false
+	}
+	configReloads.Inc()
+	return true
+}
+
 func (c *coreConfig) sanitize() {
 	if c.SleepInterval.Duration > 0 && c.SleepInterval.Duration < time.Second {
 		klog.InfoS("too short sleep interval specified, forcing to 1s",
@@ -361,13 +518,9 @@ func (c *coreConfig) sanitize() {
 	}
 }
 
+// configureCore applies the core configuration. Changes are only applied if
+// the whole configuration is valid.
 func (w *nfdWorker) configureCore(c coreConfig) error {
-	// Handle klog
-	err := klogutils.MergeKlogConfiguration(w.args.Klog, c.Klog)
-	if err != nil {
-		return err
-	}
-
 	// Determine enabled feature sources
 	featureSources := make(map[string]source.FeatureSource)
 	for _, name := range c.FeatureSources {
@@ -396,9 +549,11 @@ func (w *nfdWorker) configureCore(c coreConfig) error {
 		}
 	}
 
-	w.featureSources = slices.Collect(maps.Values(featureSources))
+	enabledFeatureSources := slices.Collect(maps.Values(featureSources))
 
-	sort.Slice(w.featureSources, func(i, j int) bool { return w.featureSources[i].Name() < w.featureSources[j].Name() })
+	sort.Slice(enabledFeatureSources, func(i, j int) bool {
+		return enabledFeatureSources[i].Name() < enabledFeatureSources[j].Name()
+	})
 
 	// Determine enabled label sources
 	labelSources := make(map[string]source.LabelSource)
@@ -428,16 +583,30 @@ func (w *nfdWorker) configureCore(c coreConfig) error {
 		}
 	}
 
-	w.labelSources = slices.Collect(maps.Values(labelSources))
+	enabledLabelSources := slices.Collect(maps.Values(labelSources))
 
-	sort.Slice(w.labelSources, func(i, j int) bool {
-		iP, jP := w.labelSources[i].Priority(), w.labelSources[j].Priority()
+	sort.Slice(enabledLabelSources, func(i, j int) bool {
+		iP, jP := enabledLabelSources[i].Priority(), enabledLabelSources[j].Priority()
 		if iP != jP {
 			return iP < jP
 		}
-		return w.labelSources[i].Name() < w.labelSources[j].Name()
+		return enabledLabelSources[i].Name() < enabledLabelSources[j].Name()
 	})
 
+	ownerReference, err := w.getOwnerReference(c)
+	if err != nil {
+		return err
+	}
+
+	// Handle klog
+	if err := klogutils.MergeKlogConfiguration(w.args.Klog, c.Klog); err != nil {
+		return err
+	}
+
+	w.featureSources = enabledFeatureSources
+	w.labelSources = enabledLabelSources
+	w.ownerReference = ownerReference
+
 	if klogV := klog.V(1); klogV.Enabled() {
 		n := make([]string, len(w.featureSources))
 		for i, s := range w.featureSources {
@@ -452,11 +621,6 @@ func (w *nfdWorker) configureCore(c coreConfig) error {
 		klogV.InfoS("enabled label sources", "labelSources", n)
 	}
 
-	err = w.setOwnerReference()
-	if err != nil {
-		return err
-	}
-
 	return nil
 }
 
@@ -514,15 +678,15 @@ func (w *nfdWorker) configure(filepath string, overrides string) error {
 
 	c.Core.sanitize()
 
-	w.config = c
-
 	if err := w.configureCore(c.Core); err != nil {
 		return err
 	}
 
+	w.config = c
+
 	// (Re-)configure sources
 	for _, s := range confSources {
-		s.SetConfig(c.Sources[s.Name()])
+		w.setSourceConfig(s, c.Sources[s.Name()])
 	}
 
 	klog.InfoS("configuration successfully updated", "configuration", w.config)
@@ -532,25 +696,36 @@ func (w *nfdWorker) configure(filepath string, overrides string) error {
 // createFeatureLabels returns the set of feature labels from the enabled
 // sources and the whitelist argument.
 func createFeatureLabels(sources []source.LabelSource, labelWhiteList regexp.Regexp) (labels Labels) {
+	labels, _ = createSourceLabels(sources, labelWhiteList, getFeatureLabels)
+	return labels
+}
+
+// createSourceLabels returns the set of feature labels from the enabled
+// sources and the whitelist argument, and the labels of each source
+// separately. The labels of each source are obtained with getLabels.
+func createSourceLabels(sources []source.LabelSource, labelWhiteList regexp.Regexp,
+	getLabels func(source.LabelSource, regexp.Regexp) (Labels, error)) (labels Labels, sourceLabels map[string]Labels) {
 	labels = Labels{}
+	sourceLabels = make(map[string]Labels, len(sources))
 
 	// Get labels from all enabled label sources
 	klog.InfoS("starting feature discovery...")
 	for _, source := range sources {
-		labelsFromSource, err := getFeatureLabels(source, labelWhiteList)
+		labelsFromSource, err := getLabels(source, labelWhiteList)
 		if err != nil {
 			klog.ErrorS(err, "discovery failed", "source", source.Name())
 			continue
 		}
 
 		maps.Copy(labels, labelsFromSource)
+		sourceLabels[source.Name()] = labelsFromSource
 	}
 	if klogV := klog.V(1); klogV.Enabled() {
 		klogV.InfoS("feature discovery completed", "labels", utils.DelayedDumper(labels))
 	} else {
 		klog.InfoS("feature discovery completed")
 	}
-	return labels
+	return labels, sourceLabels
 }
 
 // getFeatureLabels returns node labels for features discovered by the
@@ -627,6 +802,11 @@ func (m *nfdWorker) updateNodeFeatureObject(labels Labels) error {
 	namespace := m.kubernetesNamespace
 
 	features := source.GetAllFeatures()
+	var sourceLabels map[string]Labels
+	if d := m.getDiscoveredFeatures(); d != nil {
+		sourceLabels = d.sourceLabels
+	}
+	status := m.featureSourceStatus(sourceLabels)
 
 	// TODO: we could implement some simple caching of the object, only get it
 	// every 10 minutes or so because nobody else should really be modifying it
@@ -642,6 +822,7 @@ func (m *nfdWorker) updateNodeFeatureObject(labels Labels) error {
 				Features: *features,
 				Labels:   labels,
 			},
+			Status: status,
 		}
 		klog.InfoS("creating NodeFeature object", "nodefeature", klog.KObj(nfr))
 
@@ -662,8 +843,9 @@ func (m *nfdWorker) updateNodeFeatureObject(labels Labels) error {
 			Features: *features,
 			Labels:   labels,
 		}
+		nfrUpdated.Status = status
 
-		if !apiequality.Semantic.DeepEqual(nfr, nfrUpdated) {
+		if nodeFeatureNeedsUpdate(nfr, nfrUpdated) {
 			klog.InfoS("updating NodeFeature object", "nodefeature", klog.KObj(nfr))
 			nfrUpdated, err = cli.NfdV1alpha1().NodeFeatures(namespace).Update(context.TODO(), nfrUpdated, metav1.UpdateOptions{})
 			if err != nil {
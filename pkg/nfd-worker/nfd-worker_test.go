@@ -129,6 +129,17 @@ func TestRun(t *testing.T) {
 						},
 					},
 				}
+				// Discovery status of the feature sources
+				So(nf.Status.Sources, ShouldHaveLength, 1)
+				So(nf.Status.Sources[0].Name, ShouldEqual, "fake")
+				So(nf.Status.Sources[0].Error, ShouldBeEmpty)
+				So(nf.Status.Sources[0].LastSuccessfulTime, ShouldNotBeNil)
+				So(nf.Status.Sources[0].Labels, ShouldResemble, []string{
+					"feature.node.kubernetes.io/fake-fakefeature1",
+					"feature.node.kubernetes.io/fake-fakefeature2",
+					"feature.node.kubernetes.io/fake-fakefeature3",
+				})
+				nf.Status = v1alpha1.NodeFeatureStatus{}
 				So(nf, ShouldResemble, nfExpected)
 			})
 		})
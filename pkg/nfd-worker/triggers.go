@@ -0,0 +1,285 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdworker
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+	"sigs.k8s.io/node-feature-discovery/source/custom"
+	"sigs.k8s.io/node-feature-discovery/source/local"
+)
+
+// triggersConfig contains the configuration of event-driven rediscovery.
+type triggersConfig struct {
+	// Uevents enables rediscovery on kernel uevents, e.g. hot-plugged
+	// devices, and network link changes
+	Uevents bool
+	// FeatureFiles enables rediscovery on changes of the feature files and
+	// the custom rule drop-in files
+	FeatureFiles bool
+	// Debounce is the time events are collected before running rediscovery
+	Debounce utils.DurationVal
+}
+
+func (c *triggersConfig) enabled() bool {
+	return c.Uevents || c.FeatureFiles
+}
+
+// Types of events triggering rediscovery
+const (
+	triggerUevent = "uevent"
+	triggerLink   = "link"
+	triggerFile   = "file"
+)
+
+// ueventSubsystemSources maps kernel uevent subsystems to the feature sources
+// that need to be re-run.
+var ueventSubsystemSources = map[string][]string{
+	"block":  {"storage"},
+	"cpu":    {"cpu"},
+	"memory": {"memory"},
+	"net":    {"network"},
+	"pci":    {"pci"},
+	"usb":    {"usb"},
+}
+
+// triggerEvent is an event that triggers rediscovery.
+type triggerEvent struct {
+	trigger string
+	// featureSources are the names of the feature sources to re-run. Empty
+	// if only the labels need to be re-created.
+	featureSources []string
+}
+
+// discoveryTriggers listens to events that may change the features of the
+// node. The names of the feature sources to re-run are sent on C after the
+// events of the debounce period have been collected.
+type discoveryTriggers struct {
+	C <-chan sets.Set[string]
+
+	c        chan sets.Set[string]
+	events   chan triggerEvent
+	debounce time.Duration
+	stop     chan struct{}
+	wg       sync.WaitGroup
+	watcher  *fsnotify.Watcher
+}
+
+func newDiscoveryTriggers(debounce time.Duration) *discoveryTriggers {
+	c := make(chan sets.Set[string])
+	return &discoveryTriggers{
+		C:        c,
+		c:        c,
+		events:   make(chan triggerEvent, 16),
+		debounce: debounce,
+		stop:     make(chan struct{}),
+	}
+}
+
+// startDiscoveryTriggers starts listening to the events enabled in the
+// config.
+func startDiscoveryTriggers(config triggersConfig) (*discoveryTriggers, error) {
+	t := newDiscoveryTriggers(config.Debounce.Duration)
+
+	if config.Uevents {
+		if err := t.startNetlinkListeners(); err != nil {
+			t.Stop()
+			return nil, err
+		}
+	}
+	if config.FeatureFiles {
+		if err := t.startFileListener(local.FeatureFilesDir(), custom.Directory); err != nil {
+			t.Stop()
+			return nil, err
+		}
+	}
+
+	t.wg.Add(1)
+	go t.run()
+
+	return t, nil
+}
+
+// Stop stops listening to events.
+func (t *discoveryTriggers) Stop() {
+	close(t.stop)
+	if t.watcher != nil {
+		_ = t.watcher.Close()
+	}
+	t.wg.Wait()
+}
+
+// send passes an event to the debouncer.
+func (t *discoveryTriggers) send(e triggerEvent) {
+	select {
+	case t.events <- e:
+	case <-t.stop:
+	}
+}
+
+func (t *discoveryTriggers) run() {
+	defer t.wg.Done()
+
+	var timer <-chan time.Time
+	// out is nil until the debounce period is over, blocking the send
+	var out chan sets.Set[string]
+	pending := sets.New[string]()
+	for {
+		select {
+		case e := <-t.events:
+			featureDiscoveryTriggers.WithLabelValues(e.trigger).Inc()
+			pending.Insert(e.featureSources...)
+			if timer == nil && out == nil {
+				timer = time.After(t.debounce)
+			}
+
+		case <-timer:
+			timer = nil
+			out = t.c
+
+		case out <- pending:
+			out = nil
+			pending = sets.New[string]()
+
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+// startFileListener watches the feature file directory (localDir) and the
+// custom rule drop-in directory (customDir) and its subdirectories.
+func (t *discoveryTriggers) startFileListener(localDir, customDir string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create fsnotify watcher: %w", err)
+	}
+	t.watcher = watcher
+
+	localDir = filepath.Clean(localDir)
+	customDir = filepath.Clean(customDir)
+
+	addWatch := func(path string) {
+		if err := watcher.Add(path); err != nil {
+			if os.IsNotExist(err) {
+				klog.V(1).InfoS("directory does not exist, not watching it", "path", path)
+			} else {
+				klog.ErrorS(err, "failed to add fsnotify watch", "path", path)
+			}
+			return
+		}
+		klog.V(1).InfoS("added fsnotify watch", "path", path)
+	}
+	addWatch(localDir)
+	addWatch(customDir)
+	// Rules are read from the 1st level subdirectories of the custom
+	// directory, too
+	if entries, err := os.ReadDir(customDir); err == nil {
+		for _, e := range entries {
+			if e.IsDir() {
+				addWatch(filepath.Join(customDir, e.Name()))
+			}
+		}
+	}
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		for {
+			select {
+			case e, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				klog.V(5).InfoS("fsnotify event received", "path", e.Name, "op", e.Op)
+
+				dir := filepath.Dir(e.Name)
+				if dir == localDir {
+					t.send(triggerEvent{trigger: triggerFile, featureSources: []string{local.Name}})
+					continue
+				}
+				if dir == customDir && e.Has(fsnotify.Create) {
+					if fi, err := os.Stat(e.Name); err == nil && fi.IsDir() {
+						addWatch(e.Name)
+					}
+				}
+				// Custom rules are evaluated when creating labels, there
+				// is no need to re-run any feature source
+				t.send(triggerEvent{trigger: triggerFile})
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				klog.ErrorS(err, "fsnotify error")
+			}
+		}
+	}()
+
+	return nil
+}
+
+// parseUevent parses a kernel uevent message into a map of its properties.
+// The message consists of a "ACTION@DEVPATH" header followed by
+// null-separated KEY=VALUE pairs.
+func parseUevent(msg []byte) map[string]string {
+	fields := bytes.Split(msg, []byte{0})
+	if len(fields) < 2 || !bytes.Contains(fields[0], []byte("@")) {
+		return nil
+	}
+
+	props := make(map[string]string, len(fields)-1)
+	for _, f := range fields[1:] {
+		if k, v, ok := strings.Cut(string(f), "="); ok {
+			props[k] = v
+		}
+	}
+	return props
+}
+
+// ueventTriggerEvent returns the trigger event corresponding to a kernel
+// uevent message, or nil if the uevent does not affect any feature source.
+func ueventTriggerEvent(msg []byte) *triggerEvent {
+	props := parseUevent(msg)
+	sources, ok := ueventSubsystemSources[props["SUBSYSTEM"]]
+	if !ok {
+		return nil
+	}
+	klog.V(3).InfoS("uevent received", "action", props["ACTION"], "devpath", props["DEVPATH"], "subsystem", props["SUBSYSTEM"])
+	return &triggerEvent{trigger: triggerUevent, featureSources: sources}
+}
+
+// allUeventTriggerEvent returns a trigger event re-running all feature
+// sources affected by uevents, used when uevents may have been lost.
+func allUeventTriggerEvent() triggerEvent {
+	all := sets.New[string]()
+	for _, s := range ueventSubsystemSources {
+		all.Insert(s...)
+	}
+	return triggerEvent{trigger: triggerUevent, featureSources: sets.List(all)}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdworker
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/utils/hostpath"
+)
+
+// ueventGroupKernel is the netlink multicast group of uevents sent by the
+// kernel (as opposed to the ones re-broadcast by udev)
+const ueventGroupKernel = 1
+
+// startNetlinkListeners starts listening to kernel uevents and network link
+// changes.
+func (t *discoveryTriggers) startNetlinkListeners() error {
+	// The kernel sends uevents only to the initial network namespace
+	if hostNet, err := inHostNetNamespace(); err != nil {
+		klog.V(1).InfoS("failed to determine if running in the host network namespace", "error", err)
+	} else if !hostNet {
+		klog.InfoS("WARNING: not running in the host network namespace, kernel uevents and changes of the host network interfaces will not be received, run nfd-worker with hostNetwork: true")
+	}
+
+	ueventFd, err := openNetlinkSocket(unix.NETLINK_KOBJECT_UEVENT, ueventGroupKernel)
+	if err != nil {
+		return fmt.Errorf("failed to listen to uevents: %w", err)
+	}
+	linkFd, err := openNetlinkSocket(unix.NETLINK_ROUTE, unix.RTMGRP_LINK)
+	if err != nil {
+		unix.Close(ueventFd)
+		return fmt.Errorf("failed to listen to network link changes: %w", err)
+	}
+
+	t.wg.Add(2)
+	go t.receiveNetlink(ueventFd, func(msg []byte) {
+		if e := ueventTriggerEvent(msg); e != nil {
+			t.send(*e)
+		}
+	}, allUeventTriggerEvent)
+	go t.receiveNetlink(linkFd, func(msg []byte) {
+		msgs, err := syscall.ParseNetlinkMessage(msg)
+		if err != nil {
+			klog.ErrorS(err, "failed to parse netlink message")
+			return
+		}
+		for _, m := range msgs {
+			if m.Header.Type == unix.RTM_NEWLINK || m.Header.Type == unix.RTM_DELLINK {
+				klog.V(3).InfoS("network link change received")
+				t.send(triggerEvent{trigger: triggerLink, featureSources: []string{"network"}})
+				return
+			}
+		}
+	}, func() triggerEvent {
+		return triggerEvent{trigger: triggerLink, featureSources: []string{"network"}}
+	})
+
+	return nil
+}
+
+// inHostNetNamespace returns true if nfd-worker runs in the network namespace
+// of the host. It compares the network interfaces of the host sysfs, which
+// shows the interfaces of the network namespace it was mounted in, to the
+// network interfaces visible to nfd-worker.
+func inHostNetNamespace() (bool, error) {
+	entries, err := os.ReadDir(hostpath.SysfsDir.Path("class/net"))
+	if err != nil {
+		return false, err
+	}
+	hostIfaces := sets.New[string]()
+	for _, e := range entries {
+		hostIfaces.Insert(e.Name())
+	}
+
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return false, err
+	}
+	ownIfaces := sets.New[string]()
+	for _, iface := range ifaces {
+		ownIfaces.Insert(iface.Name)
+	}
+	return hostIfaces.Equal(ownIfaces), nil
+}
+
+// receiveNetlink receives messages from a netlink socket until the triggers
+// are stopped. The lost function is used to create an event if messages were
+// dropped because of the socket receive buffer overrunning.
+func (t *discoveryTriggers) receiveNetlink(fd int, handle func([]byte), lost func() triggerEvent) {
+	defer t.wg.Done()
+	defer unix.Close(fd)
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := unix.Recvfrom(fd, buf, 0)
+
+		select {
+		case <-t.stop:
+			return
+		default:
+		}
+
+		switch {
+		case err == nil:
+			handle(buf[:n])
+		case errors.Is(err, unix.EAGAIN), errors.Is(err, unix.EINTR):
+			// Receive timeout, check if we should stop
+		case errors.Is(err, unix.ENOBUFS):
+			klog.InfoS("netlink receive buffer overrun, events lost")
+			t.send(lost())
+		default:
+			klog.ErrorS(err, "failed to receive netlink message, stopping listener")
+			return
+		}
+	}
+}
+
+// openNetlinkSocket opens a netlink socket subscribed to the given multicast
+// groups.
+func openNetlinkSocket(protocol int, groups uint32) (int, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, protocol)
+	if err != nil {
+		return -1, fmt.Errorf("failed to create netlink socket: %w", err)
+	}
+	// Use a receive timeout so that the listener can be stopped
+	tv := unix.Timeval{Sec: 1}
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+		unix.Close(fd)
+		return -1, fmt.Errorf("failed to set netlink socket receive timeout: %w", err)
+	}
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: groups}); err != nil {
+		unix.Close(fd)
+		return -1, fmt.Errorf("failed to bind netlink socket: %w", err)
+	}
+	return fd, nil
+}
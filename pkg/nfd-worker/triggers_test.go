@@ -0,0 +1,97 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfdworker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func receiveTrigger(t *discoveryTriggers) sets.Set[string] {
+	select {
+	case s := <-t.C:
+		return s
+	case <-time.After(time.Second):
+		return nil
+	}
+}
+
+func TestParseUevent(t *testing.T) {
+	Convey("When parsing uevents", t, func() {
+		msg := []byte("add@/devices/pci0000:00/0000:00:1c.0\x00ACTION=add\x00DEVPATH=/devices/pci0000:00/0000:00:1c.0\x00SUBSYSTEM=pci\x00SEQNUM=1234\x00")
+		So(parseUevent(msg), ShouldResemble, map[string]string{
+			"ACTION":    "add",
+			"DEVPATH":   "/devices/pci0000:00/0000:00:1c.0",
+			"SUBSYSTEM": "pci",
+			"SEQNUM":    "1234",
+		})
+		So(ueventTriggerEvent(msg), ShouldResemble, &triggerEvent{trigger: triggerUevent, featureSources: []string{"pci"}})
+
+		// Uevents of unrelated subsystems are ignored
+		So(ueventTriggerEvent([]byte("change@/devices/virtual/tty/tty1\x00ACTION=change\x00SUBSYSTEM=tty\x00")), ShouldBeNil)
+		// Messages re-broadcast by udev are not in the kernel format
+		So(parseUevent([]byte("libudev\x00\xfe\xed\xca\xfe")), ShouldBeNil)
+	})
+}
+
+func TestDiscoveryTriggers(t *testing.T) {
+	Convey("When triggering rediscovery", t, func() {
+		tr := newDiscoveryTriggers(10 * time.Millisecond)
+		tr.wg.Add(1)
+		go tr.run()
+		defer tr.Stop()
+
+		// Events within the debounce period are merged
+		tr.send(triggerEvent{trigger: triggerUevent, featureSources: []string{"pci"}})
+		tr.send(triggerEvent{trigger: triggerLink, featureSources: []string{"network"}})
+		tr.send(triggerEvent{trigger: triggerUevent, featureSources: []string{"pci"}})
+		So(receiveTrigger(tr), ShouldResemble, sets.New("pci", "network"))
+
+		// Events only requiring labels to be re-created
+		tr.send(triggerEvent{trigger: triggerFile})
+		s := receiveTrigger(tr)
+		So(s, ShouldNotBeNil)
+		So(s.Len(), ShouldEqual, 0)
+	})
+
+	Convey("When watching feature files", t, func() {
+		localDir := t.TempDir()
+		customDir := t.TempDir()
+		tr := newDiscoveryTriggers(10 * time.Millisecond)
+		So(tr.startFileListener(localDir, customDir), ShouldBeNil)
+		tr.wg.Add(1)
+		go tr.run()
+		defer tr.Stop()
+
+		// Changes in feature files re-run the local source
+		So(os.WriteFile(filepath.Join(localDir, "features"), []byte("foo=bar"), 0644), ShouldBeNil)
+		So(receiveTrigger(tr), ShouldResemble, sets.New("local"))
+
+		// Changes in custom rules (also in new subdirectories) only require
+		// labels to be re-created
+		subDir := filepath.Join(customDir, "my-rules")
+		So(os.Mkdir(subDir, 0755), ShouldBeNil)
+		So(receiveTrigger(tr), ShouldResemble, sets.New[string]())
+		So(os.WriteFile(filepath.Join(subDir, "rules.yaml"), []byte("[]"), 0644), ShouldBeNil)
+		So(receiveTrigger(tr), ShouldResemble, sets.New[string]())
+	})
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"k8s.io/klog/v2"
+)
+
+// FsWatcher watches a set of files for changes in their content. The
+// directories containing the files are watched (instead of the files
+// themselves) so that replacing a file is detected, too. This covers
+// ConfigMaps mounted as volumes where an update is done by atomically
+// swapping a symlink. A notification is sent on the Events channel when the
+// content of any of the files changes, or, a file is created or removed.
+type FsWatcher struct {
+	// Events receives a notification when any of the files has changed. The
+	// channel is buffered so that pending notifications are coalesced.
+	Events chan struct{}
+
+	watcher   *fsnotify.Watcher
+	ratelimit time.Duration
+	names     []string
+	dirs      map[string]struct{}
+	checksums map[string]string
+}
+
+// CreateFsWatcher creates a new FsWatcher watching the given files.
+// Filesystem events are rate limited so that in certain filesystem operations
+// producing numerous events in quick succession only one notification is
+// sent.
+func CreateFsWatcher(ratelimit time.Duration, names ...string) (*FsWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fsnotify watcher: %w", err)
+	}
+
+	w := &FsWatcher{
+		Events:    make(chan struct{}, 1),
+		watcher:   watcher,
+		ratelimit: ratelimit,
+		dirs:      make(map[string]struct{}),
+		checksums: make(map[string]string, len(names)),
+	}
+	for _, name := range names {
+		w.names = append(w.names, filepath.Clean(name))
+	}
+
+	w.updateWatches()
+	w.updateChecksums()
+
+	go w.watch()
+
+	return w, nil
+}
+
+// Close stops the watcher.
+func (w *FsWatcher) Close() error {
+	return w.watcher.Close()
+}
+
+// updateWatches adds watches for the directories of the files. If the
+// directory of a file does not exist, the closest existing parent directory
+// is watched so that we catch the creation of the directory.
+func (w *FsWatcher) updateWatches() {
+	dirs := make(map[string]struct{}, len(w.names))
+	for _, name := range w.names {
+		for p := filepath.Dir(name); ; p = filepath.Dir(p) {
+			if _, err := os.Stat(p); err == nil {
+				dirs[p] = struct{}{}
+				break
+			}
+			if p == filepath.Dir(p) {
+				break
+			}
+		}
+	}
+
+	for p := range w.dirs {
+		if _, ok := dirs[p]; !ok {
+			// The watch is removed automatically if the directory was deleted
+			_ = w.watcher.Remove(p)
+			delete(w.dirs, p)
+		}
+	}
+	for p := range dirs {
+		if _, ok := w.dirs[p]; ok {
+			continue
+		}
+		if err := w.watcher.Add(p); err != nil {
+			klog.ErrorS(err, "failed to add fsnotify watch", "path", p)
+			continue
+		}
+		klog.V(1).InfoS("added fsnotify watch", "path", p)
+		w.dirs[p] = struct{}{}
+	}
+}
+
+// updateChecksums re-calculates the checksums of the files. Returns true if
+// any of them changed.
+func (w *FsWatcher) updateChecksums() bool {
+	changed := false
+	for _, name := range w.names {
+		sum := ""
+		// ReadFile follows symlinks so we get the content of the current
+		// target of the file
+		if data, err := os.ReadFile(name); err == nil {
+			h := sha256.Sum256(data)
+			sum = hex.EncodeToString(h[:])
+		} else if !os.IsNotExist(err) {
+			klog.ErrorS(err, "failed to read watched file", "path", name)
+			continue
+		}
+		if prev, ok := w.checksums[name]; !ok || prev != sum {
+			if ok {
+				klog.V(2).InfoS("watched file changed", "path", name)
+				changed = true
+			}
+			w.checksums[name] = sum
+		}
+	}
+	return changed
+}
+
+func (w *FsWatcher) watch() {
+	var ratelimiter <-chan time.Time
+	for {
+		select {
+		case e, ok := <-w.watcher.Events:
+			// Watcher has been closed
+			if !ok {
+				return
+			}
+			klog.V(5).InfoS("fsnotify event received", "path", e.Name, "op", e.Op)
+			if ratelimiter == nil {
+				ratelimiter = time.After(w.ratelimit)
+			}
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			klog.ErrorS(err, "fsnotify error")
+
+		case <-ratelimiter:
+			ratelimiter = nil
+
+			// Directories might have been created or removed
+			w.updateWatches()
+
+			if w.updateChecksums() {
+				select {
+				case w.Events <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}
+}
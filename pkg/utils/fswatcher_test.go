@@ -0,0 +1,86 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testRatelimit = 10 * time.Millisecond
+
+func expectFsEvent(t *testing.T, w *FsWatcher, expected bool) {
+	t.Helper()
+	select {
+	case <-w.Events:
+		assert.True(t, expected, "unexpected event")
+	case <-time.After(20 * testRatelimit):
+		assert.False(t, expected, "event not received")
+	}
+}
+
+// writeConfigMapData mimics the way kubelet updates ConfigMaps mounted as
+// volumes: the data is written in a new directory and the "..data" symlink
+// is atomically swapped to point to it.
+func writeConfigMapData(t *testing.T, dir, version, data string) {
+	t.Helper()
+	dataDir := filepath.Join(dir, version)
+	assert.NoError(t, os.Mkdir(dataDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dataDir, "nfd.conf"), []byte(data), 0644))
+	assert.NoError(t, os.Symlink(version, filepath.Join(dir, "..data_tmp")))
+	assert.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+}
+
+func TestFsWatcher(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "nfd.conf")
+	writeConfigMapData(t, dir, "..v1", "foo: 1")
+	assert.NoError(t, os.Symlink(filepath.Join("..data", "nfd.conf"), name))
+
+	w, err := CreateFsWatcher(testRatelimit, name)
+	assert.NoError(t, err)
+	defer w.Close()
+
+	// Symlink swap
+	writeConfigMapData(t, dir, "..v2", "foo: 2")
+	assert.NoError(t, os.RemoveAll(filepath.Join(dir, "..v1")))
+	expectFsEvent(t, w, true)
+
+	// Events not changing the content do not cause a notification
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "other"), []byte("bar"), 0644))
+	assert.NoError(t, os.Chtimes(name, time.Now(), time.Now()))
+	expectFsEvent(t, w, false)
+
+	// File removed
+	assert.NoError(t, os.Remove(name))
+	expectFsEvent(t, w, true)
+
+	// Directory of the file created later
+	name = filepath.Join(dir, "sub", "nfd.conf")
+	w2, err := CreateFsWatcher(testRatelimit, name)
+	assert.NoError(t, err)
+	defer w2.Close()
+
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+	expectFsEvent(t, w2, false)
+	assert.NoError(t, os.WriteFile(name, []byte("foo: 3"), 0644))
+	expectFsEvent(t, w2, true)
+}
@@ -89,6 +89,9 @@ var (
 	_   source.ConfigurableSource = &src
 )
 
+// FeatureFilesDir returns the directory feature files are read from.
+func FeatureFilesDir() string { return featureFilesDir }
+
 // Name method of the LabelSource interface
 func (s *localSource) Name() string { return Name }
 
@@ -20,6 +20,7 @@ package source
 
 import (
 	"fmt"
+	"sync"
 
 	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
 )
@@ -155,12 +156,42 @@ func GetAllConfigurableSources() map[string]ConfigurableSource {
 	return all
 }
 
+// featureSnapshots holds the features used in place of the features of
+// sources, set by the user of the sources after discovery
+var (
+	featureSnapshots   = make(map[string]*nfdv1alpha1.Features)
+	featureSnapshotsMu sync.RWMutex
+)
+
+// SetFeatureSnapshot makes GetAllFeatures use the given features for the
+// named feature source, instead of calling its GetFeatures(). This makes it
+// possible to run discovery of a source concurrently with GetAllFeatures, and
+// to retain the last known good features of a source whose discovery did not
+// complete in time. The features must not be modified after the call.
+// Passing nil features clears the snapshot.
+func SetFeatureSnapshot(name string, features *nfdv1alpha1.Features) {
+	featureSnapshotsMu.Lock()
+	defer featureSnapshotsMu.Unlock()
+
+	if features == nil {
+		delete(featureSnapshots, name)
+	} else {
+		featureSnapshots[name] = features
+	}
+}
+
 // GetAllFeatures returns a combined set of all features from all feature
 // sources.
 func GetAllFeatures() *nfdv1alpha1.Features {
+	featureSnapshotsMu.RLock()
+	defer featureSnapshotsMu.RUnlock()
+
 	features := nfdv1alpha1.NewFeatures()
 	for n, s := range GetAllFeatureSources() {
-		f := s.GetFeatures()
+		f, ok := featureSnapshots[n]
+		if !ok {
+			f = s.GetFeatures()
+		}
 		for k, v := range f.Flags {
 			// Prefix feature with the name of the source
 			k = n + "." + k
@@ -22,6 +22,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/api/nfd/v1alpha1"
 	source "sigs.k8s.io/node-feature-discovery/source"
 
 	// Register all source packages
@@ -78,3 +79,14 @@ func TestFeatureSources(t *testing.T) {
 		assert.Empty(t, (*f).Instances, msg)
 	}
 }
+
+func TestFeatureSnapshot(t *testing.T) {
+	snapshot := nfdv1alpha1.NewFeatures()
+	snapshot.Flags["foo"] = nfdv1alpha1.NewFlagFeatures("bar")
+
+	source.SetFeatureSnapshot("fake", snapshot)
+	assert.Equal(t, snapshot.Flags["foo"], source.GetAllFeatures().Flags["fake.foo"])
+
+	source.SetFeatureSnapshot("fake", nil)
+	assert.NotContains(t, source.GetAllFeatures().Flags, "fake.foo")
+}